@@ -0,0 +1,260 @@
+// Package apiclient is a typed client of the HTTP API of the money converter, served by its serve command.
+//
+// A Client fetches exchange rates as money.Convert expects, and provides the rates of the day as an
+// ecbank.RateTable, so that a server may relay another one.
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"moneyconverter/ecbank"
+	"moneyconverter/money"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultTimeout is how long a Client waits for an answer, unless given its own http.Client.
+const defaultTimeout = 30 * time.Second
+
+const (
+	// providerName identifies the server as the provider of the rates.
+	providerName = "moneyconverter"
+	// baseCurrencyCode is the currency the bank quotes its rates against.
+	baseCurrencyCode = "EUR"
+)
+
+// Client calls a money converter server.
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// Option customises a Client built by New.
+type Option func(*Client)
+
+// WithHTTPClient makes the client send its requests with the given http.Client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// New returns a Client of the server at baseURL, such as http://localhost:8080.
+func New(baseURL string, opts ...Option) Client {
+	c := Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+// Conversion is the answer of the server to a conversion.
+type Conversion struct {
+	Amount money.Amount
+	Result money.Amount
+	Rate   money.ExchangeRate
+	// Date is the day the rate was published.
+	Date time.Time
+	// Stale reports whether the rate comes from an outdated cache of the server.
+	Stale bool
+}
+
+// conversion is the answer of the server to /v1/convert.
+type conversion struct {
+	Amount money.Amount       `json:"amount"`
+	Result money.Amount       `json:"result"`
+	Rate   money.ExchangeRate `json:"rate"`
+	Date   string             `json:"date"`
+	Stale  bool               `json:"stale"`
+}
+
+// rates is the answer of the server to /v1/rates.
+type rates struct {
+	Base  money.Currency                        `json:"base"`
+	Date  string                                `json:"date"`
+	Stale bool                                  `json:"stale"`
+	Rates map[money.Currency]money.ExchangeRate `json:"rates"`
+}
+
+// currencies is the answer of the server to /v1/currencies.
+type currencies struct {
+	Currencies []money.Currency `json:"currencies"`
+}
+
+// errorEnvelope is the answer of the server to a failed request.
+type errorEnvelope struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Convert converts an amount with the rates of the day, or of the given day when it isn't zero.
+func (c Client) Convert(ctx context.Context, amount money.Amount, to money.Currency, day time.Time) (Conversion, error) {
+	quantity := amount.Quantity()
+	query := url.Values{
+		"amount": {quantity.String()},
+		"from":   {amount.Currency().ISOCode()},
+		"to":     {to.ISOCode()},
+	}
+	if !day.IsZero() {
+		query.Set("date", day.Format(time.DateOnly))
+	}
+
+	var answer conversion
+	if err := c.get(ctx, "/v1/convert", query, &answer); err != nil {
+		return Conversion{}, err
+	}
+
+	published, err := parseDate(answer.Date)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	return Conversion{Amount: answer.Amount, Result: answer.Result, Rate: answer.Rate, Date: published, Stale: answer.Stale}, nil
+}
+
+// RatesAgainst returns the rates quoted against the base currency, of the day or of the given day when it isn't zero.
+func (c Client) RatesAgainst(ctx context.Context, base money.Currency, day time.Time) (ecbank.RateTable, error) {
+	query := url.Values{"base": {base.ISOCode()}}
+	if !day.IsZero() {
+		query.Set("date", day.Format(time.DateOnly))
+	}
+
+	var answer rates
+	if err := c.get(ctx, "/v1/rates", query, &answer); err != nil {
+		return ecbank.RateTable{}, err
+	}
+
+	published, err := parseDate(answer.Date)
+	if err != nil {
+		return ecbank.RateTable{}, err
+	}
+
+	return ecbank.RateTable{Base: answer.Base, Date: published, Rates: answer.Rates, Stale: answer.Stale}, nil
+}
+
+// Rates returns the rates of the day, quoted against the euro.
+func (c Client) Rates(ctx context.Context) (ecbank.RateTable, error) {
+	return c.RatesOn(ctx, time.Time{})
+}
+
+// RatesOn returns the rates published on a day, or the latest ones before it, quoted against the euro.
+func (c Client) RatesOn(ctx context.Context, day time.Time) (ecbank.RateTable, error) {
+	base, err := money.ParseCurrency(baseCurrencyCode)
+	if err != nil {
+		return ecbank.RateTable{}, err
+	}
+
+	return c.RatesAgainst(ctx, base, day)
+}
+
+// Currencies returns the currencies the server knows rates for, sorted by code.
+func (c Client) Currencies(ctx context.Context) ([]money.Currency, error) {
+	var answer currencies
+	err := c.get(ctx, "/v1/currencies", nil, &answer)
+	return answer.Currencies, err
+}
+
+// Health returns nil when the server is up.
+func (c Client) Health(ctx context.Context) error {
+	var answer struct {
+		Status string `json:"status"`
+	}
+	return c.get(ctx, "/healthz", nil, &answer)
+}
+
+// FetchExchangeRate fetches the ExchangeRate of the day from the server. It makes a Client usable by money.Convert.
+func (c Client) FetchExchangeRate(source, target money.Currency) (money.ExchangeRate, error) {
+	info, err := c.FetchRateInfo(source, target)
+	return info.Rate, err
+}
+
+// FetchRateInfo fetches the ExchangeRate of the day from the server, along with when it was published and fetched.
+func (c Client) FetchRateInfo(source, target money.Currency) (money.RateInfo, error) {
+	table, err := c.RatesAgainst(context.Background(), source, time.Time{})
+	if err != nil {
+		return money.RateInfo{}, err
+	}
+
+	rate, ok := table.Rates[target]
+	if !ok {
+		return money.RateInfo{}, fmt.Errorf("%w: from %s to %s", ErrRateNotFound, source, target)
+	}
+
+	return money.RateInfo{
+		Rate:        rate,
+		Provider:    providerName,
+		PublishedAt: table.Date,
+		FetchedAt:   time.Now(),
+		Stale:       table.Stale,
+	}, nil
+}
+
+// SupportedCurrencies returns the currencies the server knows rates for, or nil when it cannot be reached.
+func (c Client) SupportedCurrencies() []money.Currency {
+	list, err := c.Currencies(context.Background())
+	if err != nil {
+		return nil
+	}
+	return list
+}
+
+// get requests a path of the server and decodes its JSON answer into v, or its error envelope into an *APIError.
+func (c Client) get(ctx context.Context, path string, query url.Values, v any) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var envelope errorEnvelope
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || envelope.Error.Code == "" {
+			return unexpectedStatus(resp.StatusCode)
+		}
+		return &APIError{Status: resp.StatusCode, Code: envelope.Error.Code, Message: envelope.Error.Message}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s", ErrUnexpectedResponse, err.Error())
+	}
+
+	return nil
+}
+
+// parseDate parses a day of an answer of the server, written as YYYY-MM-DD.
+func parseDate(value string) (time.Time, error) {
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrUnexpectedResponse, err.Error())
+	}
+	return day, nil
+}
+
+// unexpectedStatus returns the error of a failed answer without error envelope, such as one of a proxy.
+func unexpectedStatus(status int) error {
+	if status >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: status %d", ErrUnavailable, status)
+	}
+	return fmt.Errorf("%w: status %d", ErrUnexpectedResponse, status)
+}
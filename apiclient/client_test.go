@@ -0,0 +1,168 @@
+package apiclient_test
+
+import (
+	"context"
+	"errors"
+	"moneyconverter/apiclient"
+	"moneyconverter/ecbank"
+	"moneyconverter/money"
+	"moneyconverter/server"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const feed = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time='2025-04-08'>
+			<Cube currency='USD' rate='2.0000'/>
+			<Cube currency='CHF' rate='0.9349'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+// newServer starts a server answering with the rates of the feed, cached for the day without calling the bank.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "mc_data_"+time.Now().Format("20060102")+".txt")
+	if err := os.WriteFile(filename, []byte(feed), 0o644); err != nil {
+		t.Fatalf("unable to write cache file: %s", err)
+	}
+
+	ts := httptest.NewServer(server.New(ecbank.NewClient(time.Second, ecbank.WithOffline(), ecbank.WithCacheDir(dir))))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func mustParseAmount(t *testing.T, quantity, code string) money.Amount {
+	t.Helper()
+
+	decimal, err := money.ParseDecimal(quantity)
+	if err != nil {
+		t.Fatalf("unable to parse %q: %s", quantity, err)
+	}
+
+	amount, err := money.NewAmount(decimal, mustParseCurrency(t, code))
+	if err != nil {
+		t.Fatalf("unable to build amount: %s", err)
+	}
+	return amount
+}
+
+func mustParseCurrency(t *testing.T, code string) money.Currency {
+	t.Helper()
+
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		t.Fatalf("unable to parse currency %q: %s", code, err)
+	}
+	return currency
+}
+
+func TestClient_Convert(t *testing.T) {
+	client := apiclient.New(newServer(t).URL)
+
+	got, err := client.Convert(context.Background(), mustParseAmount(t, "12.50", "EUR"), mustParseCurrency(t, "USD"), time.Time{})
+	if err != nil {
+		t.Fatalf("unable to convert: %s", err)
+	}
+
+	if got.Result.String() != "25.00 USD" {
+		t.Errorf("expected 25.00 USD, got %s", got.Result)
+	}
+	if got.Date.Format(time.DateOnly) != "2025-04-08" {
+		t.Errorf("expected the rate of 2025-04-08, got %s", got.Date.Format(time.DateOnly))
+	}
+}
+
+func TestClient_RatesAndCurrencies(t *testing.T) {
+	client := apiclient.New(newServer(t).URL)
+
+	table, err := client.RatesAgainst(context.Background(), mustParseCurrency(t, "USD"), time.Time{})
+	if err != nil {
+		t.Fatalf("unable to fetch rates: %s", err)
+	}
+	if rate := table.Rates[mustParseCurrency(t, "EUR")]; rate.String() != "0.5" {
+		t.Errorf("expected 1 USD to be worth 0.5 EUR, got %s", rate.String())
+	}
+
+	currencies := client.SupportedCurrencies()
+	if len(currencies) != 3 || currencies[0].ISOCode() != "CHF" {
+		t.Errorf("expected CHF, EUR and USD, got %v", currencies)
+	}
+
+	if err := client.Health(context.Background()); err != nil {
+		t.Errorf("expected a healthy server, got %s", err)
+	}
+}
+
+func TestClient_FetchExchangeRate(t *testing.T) {
+	client := apiclient.New(newServer(t).URL)
+
+	// the client is a rate provider of its own.
+	got, err := money.Convert(mustParseAmount(t, "10", "USD"), mustParseCurrency(t, "EUR"), client)
+	if err != nil {
+		t.Fatalf("unable to convert: %s", err)
+	}
+	if got.String() != "5.00 EUR" {
+		t.Errorf("expected 5.00 EUR, got %s", got)
+	}
+
+	info, err := money.FetchRateInfo(client, mustParseCurrency(t, "EUR"), mustParseCurrency(t, "CHF"))
+	if err != nil {
+		t.Fatalf("unable to fetch rate info: %s", err)
+	}
+	if info.Provider != "moneyconverter" || info.PublishedAt.Format(time.DateOnly) != "2025-04-08" {
+		t.Errorf("expected the rate of the server published on 2025-04-08, got %+v", info)
+	}
+
+	_, err = client.FetchExchangeRate(mustParseCurrency(t, "EUR"), mustParseCurrency(t, "JPY"))
+	if !errors.Is(err, apiclient.ErrRateNotFound) {
+		t.Errorf("expected %v, got %v", apiclient.ErrRateNotFound, err)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	client := apiclient.New(newServer(t).URL)
+
+	_, err := client.Convert(context.Background(), mustParseAmount(t, "1", "EUR"), mustParseCurrency(t, "JPY"), time.Time{})
+	var apiErr *apiclient.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || apiErr.Code != "rate_not_found" {
+		t.Errorf("expected a rate_not_found API error, got %v", err)
+	}
+	if !errors.Is(err, apiclient.ErrRateNotFound) {
+		t.Errorf("expected %v, got %v", apiclient.ErrRateNotFound, err)
+	}
+
+	_, err = client.RatesOn(context.Background(), time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	if !errors.Is(err, apiclient.ErrRateNotFound) {
+		t.Errorf("expected %v for a date before the history, got %v", apiclient.ErrRateNotFound, err)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if err := apiclient.New(closed.URL).Health(context.Background()); !errors.Is(err, apiclient.ErrUnavailable) {
+		t.Errorf("expected %v, got %v", apiclient.ErrUnavailable, err)
+	}
+}
+
+func TestServer_RelaysAnotherServer(t *testing.T) {
+	upstream := apiclient.New(newServer(t).URL)
+	relay := httptest.NewServer(server.New(upstream))
+	defer relay.Close()
+
+	got, err := apiclient.New(relay.URL).Convert(context.Background(), mustParseAmount(t, "3", "EUR"), mustParseCurrency(t, "USD"), time.Time{})
+	if err != nil {
+		t.Fatalf("unable to convert through the relay: %s", err)
+	}
+	if got.Result.String() != "6.00 USD" {
+		t.Errorf("expected 6.00 USD, got %s", got.Result)
+	}
+}
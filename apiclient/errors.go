@@ -0,0 +1,62 @@
+package apiclient
+
+import (
+	"fmt"
+	"moneyconverter/money"
+)
+
+// apiClientError defines a sentinel error.
+type apiClientError string
+
+// apiClientError implements the error interface.
+func (e apiClientError) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrUnavailable is returned when the server cannot be reached, or cannot reach the bank.
+	ErrUnavailable = apiClientError("money converter unavailable")
+	// ErrRateNotFound is returned when the server knows no rate for the currencies or the date.
+	ErrRateNotFound = apiClientError("couldn't find the exchange rate")
+	// ErrInvalidRequest is returned when the server rejects a parameter.
+	ErrInvalidRequest = apiClientError("invalid request")
+	// ErrUnexpectedResponse is returned when the answer of the server cannot be read.
+	ErrUnexpectedResponse = apiClientError("unexpected response")
+)
+
+// APIError is an error answered by the server, in its error envelope.
+type APIError struct {
+	// Status is the HTTP status of the answer.
+	Status int
+	// Code is the machine-readable cause of the error, such as rate_not_found.
+	Code string
+	// Message describes the error for humans.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.Status, e.Code)
+}
+
+// Unwrap returns the sentinel error matching the code, so that errors.Is works as with a local conversion.
+func (e *APIError) Unwrap() error {
+	switch e.Code {
+	case "invalid_currency":
+		return money.ErrInvalidCurrencyCode
+	case "invalid_amount":
+		return money.ErrInvalidDecimal
+	case "too_precise":
+		return money.ErrTooPrecise
+	case "too_large":
+		return money.ErrTooLarge
+	case "rate_not_found", "no_rates_for_date":
+		return ErrRateNotFound
+	case "provider_unavailable":
+		return ErrUnavailable
+	case "invalid_parameter":
+		return ErrInvalidRequest
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// runCache manages the cached feeds of the bank.
+func (a *app) runCache(args []string) error {
+	actions := map[string]func([]string) error{
+		"clear": a.runCacheClear,
+		"info":  a.runCacheInfo,
+		"prune": a.runCachePrune,
+		"warm":  a.runCacheWarm,
+	}
+
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		_, _ = fmt.Fprintln(a.stderr, "Usage: moneyconverter cache clear|info|prune|warm [flags]")
+		if len(args) == 0 {
+			return usageError("missing cache action")
+		}
+		return nil
+	}
+
+	action, ok := actions[args[0]]
+	if !ok {
+		return usageError(fmt.Sprintf("unknown cache action %q, expected clear, info, prune or warm", args[0]))
+	}
+
+	return action(args[1:])
+}
+
+// runCacheClear deletes every cached feed, keeping the history.
+func (a *app) runCacheClear(args []string) error {
+	flags := a.newFlagSet("cache clear", "", "Deletes every cached feed of the bank. The history is kept.")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	if err := a.newClient().ClearCache(); err != nil {
+		return fmt.Errorf("unable to clear cache files: %w", err)
+	}
+
+	_, _ = fmt.Fprintln(a.stdout, "Cache cleared.")
+	return nil
+}
+
+// runCacheInfo lists the cached feeds and the size of the history.
+func (a *app) runCacheInfo(args []string) error {
+	flags := a.newFlagSet("cache info", "", "Lists the cached feeds of the bank, newest first, and the publications of the history.")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	client := a.newClient()
+	files, err := client.CacheFiles()
+	if err != nil {
+		return fmt.Errorf("unable to list cache files: %w", err)
+	}
+
+	for _, file := range files {
+		_, _ = fmt.Fprintf(a.stdout, "%s %8d bytes %s\n", file.Day.Format(time.DateOnly), file.Size, file.Path)
+	}
+
+	check, err := client.History().Verify()
+	if err != nil {
+		return fmt.Errorf("unable to read history: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(a.stdout, "%d cached feeds, %d publications in the history\n", len(files), check.Publications)
+	return nil
+}
+
+// runCachePrune deletes the cached feeds older than a duration.
+func (a *app) runCachePrune(args []string) error {
+	flags := a.newFlagSet("cache prune", "", "Deletes the cached feeds of the bank older than a duration.")
+	olderThan := flags.Duration("older-than", 7*24*time.Hour, "age of the oldest feed to keep")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	pruned, err := a.newClient().PruneCache(time.Now().Add(-*olderThan))
+	if err != nil {
+		return fmt.Errorf("unable to prune cache files: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(a.stdout, "%d cached feeds deleted.\n", pruned)
+	return nil
+}
+
+// runCacheWarm fetches the feed of the day into the cache.
+func (a *app) runCacheWarm(args []string) error {
+	flags := a.newFlagSet("cache warm", "", "Fetches the feed of the day into the cache, so that later commands work offline.")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	table, err := a.newClient().Rates(context.Background())
+	if err != nil {
+		return fmt.Errorf("unable to fetch exchange rates: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(a.stdout, "Cached rates published on %s%s.\n", table.Date.Format(time.DateOnly), staleNotice(table))
+	return nil
+}
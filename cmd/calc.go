@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"moneyconverter/expr"
+	"moneyconverter/money"
+	"strings"
+)
+
+// calcResult is the JSON output of the calc command.
+type calcResult struct {
+	Expression string       `json:"expression"`
+	Result     money.Amount `json:"result"`
+}
+
+// runCalc evaluates an arithmetic expression over amounts of several currencies.
+func (a *app) runCalc(args []string) error {
+	flags := a.newFlagSet("calc", "<expression>",
+		"Evaluates an arithmetic expression over amounts, such as 120 USD + 80 GBP - 15% in EUR or (3 * 19.99 CHF) / 2 to JPY.\n"+
+			"Every amount is converted to the currency of the result, written after the expression or else that of its first amount.")
+	ratesFile := flags.String("rates-file", "", "read rates from a local .csv, .tsv, .json or ECB .xml file instead of the bank")
+	rounding := flags.String("rounding", "down", "rounding of the result: down, half-up, half-even or up")
+	output := flags.String("output", outputText, "output format: text or json")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	if *output != outputText && *output != outputJSON {
+		return usageError(fmt.Sprintf("unknown output %q, expected text or json", *output))
+	}
+	a.output = *output
+
+	input := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(input) == "" {
+		return usageError("missing expression to evaluate")
+	}
+
+	roundingMode, err := money.ParseRoundingMode(*rounding)
+	if err != nil {
+		return usageError(fmt.Sprintf("unable to parse rounding %q: %s", *rounding, err))
+	}
+
+	format, err := parseLocale(a.globals.locale)
+	if err != nil {
+		return err
+	}
+
+	preferred, err := a.preferredCurrencies()
+	if err != nil {
+		return err
+	}
+
+	expression, err := expr.Parse(input, expr.WithPreferred(preferred...))
+	if err != nil {
+		var syntaxErr *expr.SyntaxError
+		if errors.As(err, &syntaxErr) && a.output == outputText {
+			printColumn(a.stderr, input, syntaxErr.Column)
+		}
+		return fmt.Errorf("unable to parse expression: %w", err)
+	}
+
+	rates, notice, err := a.loadRates(*ratesFile)
+	if err != nil {
+		return fmt.Errorf("unable to fetch exchange rates: %w", err)
+	}
+
+	result, err := expression.Evaluate(rates, roundingMode)
+	if err != nil {
+		return fmt.Errorf("unable to evaluate expression: %w", err)
+	}
+
+	if a.output == outputJSON {
+		return json.NewEncoder(a.stdout).Encode(calcResult{Expression: input, Result: result})
+	}
+
+	_, _ = fmt.Fprintf(a.stdout, "%s%s\n", format.amount(result), notice)
+	return nil
+}
+
+// printColumn writes the input with a caret under the given column, starting at 1.
+func printColumn(w io.Writer, input string, column int) {
+	_, _ = fmt.Fprintf(w, "  %s\n  %s^\n", input, strings.Repeat(" ", column-1))
+}
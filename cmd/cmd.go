@@ -0,0 +1,368 @@
+// Package cmd implements the command line interface of the money converter.
+//
+// Usage:
+//
+//	moneyconverter [global flags] <command> [flags] [arguments]
+//
+// Without a command, the arguments are those of the convert command.
+//
+// Exit codes:
+//
+//	0  success
+//	1  failure, such as exchange rates that cannot be fetched
+//	2  invalid usage: unknown command or flag, invalid argument
+//	3  partial failure: some rows of a file, or some of all the currencies, couldn't be converted
+//
+// An amount written in free form, such as 100 usd to gbp or $100 → €, may replace the command.
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"moneyconverter/apiclient"
+	"moneyconverter/ecbank"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+	ExitPartial = 3
+)
+
+// defaultTimeout is how long to wait for the bank, or the upstream server, to answer unless configured otherwise.
+const defaultTimeout = 30 * time.Second
+
+// command is a subcommand of the CLI.
+type command struct {
+	name    string
+	summary string
+	run     func(a *app, args []string) error
+}
+
+// commands returns the subcommands, sorted by name.
+func commands() []command {
+	return []command{
+		{name: "cache", summary: "manage the cached feeds of the bank: clear, info, prune or warm", run: (*app).runCache},
+		{name: "calc", summary: "evaluate an arithmetic expression over amounts, such as 120 USD + 80 GBP - 15% in EUR", run: (*app).runCalc},
+		{name: "config", summary: "print the effective configuration and where each value comes from", run: (*app).runConfig},
+		{name: "convert", summary: "convert an amount to one or more currencies", run: (*app).runConvert},
+		{name: "convert-file", summary: "convert the amounts of a CSV or TSV file", run: (*app).runConvertFile},
+		{name: "currencies", summary: "list the currencies exchange rates are known for", run: (*app).runCurrencies},
+		{name: "help", summary: "describe a command", run: (*app).runHelp},
+		{name: "history", summary: "query the history of the publications of the bank", run: (*app).runHistory},
+		{name: "rates", summary: "print the exchange rates of the day", run: (*app).runRates},
+		{name: "repl", summary: "start an interactive session keeping the rates loaded", run: (*app).runRepl},
+		{name: "serve", summary: "serve the HTTP JSON API", run: (*app).runServe},
+		{name: "version", summary: "print the version of the program", run: (*app).runVersion},
+		{name: "watch", summary: "notify when the rates cross thresholds or move, such as USD/EUR > 0.95", run: (*app).runWatch},
+	}
+}
+
+// findCommand returns the command of the given name.
+func findCommand(name string) (command, bool) {
+	for _, c := range commands() {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// app holds the streams and the global settings shared by the commands.
+type app struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+	globals        globalFlags
+	// explicit holds the names of the flags given on the command line, which the configuration doesn't override.
+	explicit map[string]bool
+	// output is the format chosen by the running command, errors are reported in it.
+	output string
+}
+
+// globalFlags holds the flags accepted before the command as well as by every command.
+type globalFlags struct {
+	verbose     bool
+	veryVerbose bool
+	offline     bool
+	maxStale    time.Duration
+	cacheDir    string
+	config      string
+	prefer      string
+	upstream    string
+	providers   string
+	timeout     time.Duration
+	locale      string
+}
+
+// defaultGlobals returns the built-in defaults of the global flags.
+func defaultGlobals() globalFlags {
+	return globalFlags{timeout: defaultTimeout}
+}
+
+// register defines the global flags on the flag set, their current values being the defaults.
+// It allows the global flags to be written before or after the command.
+func (g *globalFlags) register(flags *flag.FlagSet) {
+	flags.BoolVar(&g.verbose, "v", g.verbose, "log calls to the bank to stderr")
+	flags.BoolVar(&g.veryVerbose, "vv", g.veryVerbose, "log calls to the bank and cache usage to stderr")
+	flags.BoolVar(&g.offline, "offline", g.offline, "never call the bank, use the newest cached rates whatever their age")
+	flags.DurationVar(&g.maxStale, "max-stale", g.maxStale, "use cached rates up to this old when the bank is unreachable, 0 to disable")
+	flags.StringVar(&g.cacheDir, "cache-dir", g.cacheDir, "directory of the cached feeds and history, the working directory if empty")
+	flags.StringVar(&g.config, "config", g.config, "configuration file, $XDG_CONFIG_HOME/moneyconverter/config.toml or config.json if empty")
+	flags.DurationVar(&g.timeout, "timeout", g.timeout, "how long to wait for the bank or the upstream server to answer")
+	flags.StringVar(&g.locale, "locale", g.locale, "locale of the amounts written as text, such as en-US or fr-FR, plain digits if empty")
+	flags.StringVar(&g.upstream, "upstream", g.upstream, "URL of a money converter server to fetch the rates of the day from, instead of the bank")
+	flags.StringVar(&g.providers, "providers", g.providers, "providers of the rates to convert with, a comma-separated list of bank, upstream and file, each tried when the previous fails")
+	flags.StringVar(&g.prefer, "prefer", g.prefer, "currencies picked for symbols shared by several of them, such as $: a comma-separated list")
+}
+
+// Run runs the command line whose arguments, without the program name, are args, and returns the exit code.
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, output: outputText, globals: defaultGlobals(), explicit: make(map[string]bool)}
+
+	globals := flag.NewFlagSet("moneyconverter", flag.ContinueOnError)
+	globals.SetOutput(io.Discard)
+	a.globals.register(globals)
+
+	name, rest := "convert", args
+	err := globals.Parse(args)
+	globals.Visit(func(f *flag.Flag) {
+		a.explicit[f.Name] = true
+	})
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		a.printUsage()
+		return ExitOK
+	case err != nil:
+		// the flags are not all global ones: they are those of convert, written without the command.
+	case globals.NArg() == 0:
+		a.printUsage()
+		return ExitUsage
+	default:
+		name, rest = globals.Arg(0), globals.Args()[1:]
+	}
+
+	c, ok := findCommand(name)
+	if !ok && !startsWithLetter(name) {
+		// an amount written in free form without the command, such as 100 usd to gbp or $100 → €.
+		c, ok = findCommand("convert")
+		rest = globals.Args()
+	}
+	if !ok {
+		return a.report(usageError(fmt.Sprintf("unknown command %q, run help for the list of commands", name)))
+	}
+
+	return a.report(c.run(a, rest))
+}
+
+// startsWithLetter tells whether a command line argument starts with a letter, as command names do.
+func startsWithLetter(arg string) bool {
+	for _, r := range arg {
+		return unicode.IsLetter(r)
+	}
+	return false
+}
+
+// report writes the error, if any, in the output format of the command and returns the matching exit code.
+func (a *app) report(err error) int {
+	var reported reportedError
+	var partial partialError
+	var usage usageError
+
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &reported):
+		return int(reported)
+	}
+
+	writeError(a.stderr, a.output, err)
+
+	switch {
+	case errors.As(err, &partial):
+		return ExitPartial
+	case errors.As(err, &usage):
+		return ExitUsage
+	default:
+		return ExitFailure
+	}
+}
+
+// reportedError is the exit code of an error already reported to the user, such as an invalid flag.
+type reportedError int
+
+// Error implements the error interface.
+func (e reportedError) Error() string {
+	return fmt.Sprintf("exit code %d", int(e))
+}
+
+// partialError reports that part of the work failed, each failure having been reported already.
+type partialError string
+
+// Error implements the error interface.
+func (e partialError) Error() string {
+	return string(e)
+}
+
+// newFlagSet returns the flag set of a command, accepting the global flags too.
+func (a *app) newFlagSet(name, arguments, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(a.stderr, "Usage: moneyconverter %s [flags] %s\n\n%s\n\nFlags:\n", name, arguments, description)
+		flags.PrintDefaults()
+	}
+	a.globals.register(flags)
+	return flags
+}
+
+// parse parses the flags of a command, then gives those left out the values of the environment or of the configuration.
+// Invalid flags are reported by the flag set itself, or as a JSON error should the command be asked for that output.
+func (a *app) parse(flags *flag.FlagSet, args []string) error {
+	jsonOutput := flags.Lookup("output") != nil && outputFlag(flags, args) == outputJSON
+	usage := flags.Usage
+	if jsonOutput {
+		a.output = outputJSON
+		flags.SetOutput(io.Discard)
+		flags.Usage = func() {}
+	}
+
+	err := flags.Parse(args)
+	switch {
+	case errors.Is(err, flag.ErrHelp) && jsonOutput:
+		flags.SetOutput(a.stderr)
+		usage()
+		return err
+	case errors.Is(err, flag.ErrHelp):
+		return err
+	case err != nil && jsonOutput:
+		return usageError(err.Error())
+	case err != nil:
+		return reportedError(ExitUsage)
+	default:
+		return a.applySettings(flags)
+	}
+}
+
+// outputFlag returns the value of the -output flag in the arguments of a command, read ahead of parsing them.
+// The flags unknown to the command are taken for booleans, their error being reported by the parsing.
+func outputFlag(flags *flag.FlagSet, args []string) string {
+	var output string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		f := flags.Lookup(name)
+		if f == nil {
+			continue
+		}
+
+		if boolean, ok := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && (!ok || !boolean.IsBoolFlag()) {
+			if i++; i < len(args) {
+				value = args[i]
+			}
+		}
+		if name == "output" {
+			output = value
+		}
+	}
+
+	return output
+}
+
+// printUsage writes the list of commands and the global flags.
+func (a *app) printUsage() {
+	var b strings.Builder
+	b.WriteString("Usage: moneyconverter [global flags] <command> [flags] [arguments]\n\nCommands:\n")
+
+	for _, c := range commands() {
+		fmt.Fprintf(&b, "  %-13s %s\n", c.name, c.summary)
+	}
+
+	b.WriteString("\nWithout a command, the arguments are those of convert.\n\nGlobal flags:\n")
+	_, _ = io.WriteString(a.stderr, b.String())
+
+	flags := flag.NewFlagSet("moneyconverter", flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	defaults := defaultGlobals()
+	defaults.register(flags)
+	flags.PrintDefaults()
+
+	_, _ = fmt.Fprintf(a.stderr, "\nExit codes: %d success, %d failure, %d invalid usage, %d partial failure.\n",
+		ExitOK, ExitFailure, ExitUsage, ExitPartial)
+}
+
+// runHelp describes a command, or lists them all.
+func (a *app) runHelp(args []string) error {
+	if len(args) == 0 {
+		a.printUsage()
+		return nil
+	}
+
+	c, ok := findCommand(args[0])
+	if !ok || c.name == "help" {
+		return usageError(fmt.Sprintf("unknown command %q", args[0]))
+	}
+
+	// every command describes itself when asked for help.
+	return c.run(a, []string{"-h"})
+}
+
+// newClient builds a client of the bank configured by the global flags, then by the extra options.
+func (a *app) newClient(extra ...ecbank.Option) ecbank.Client {
+	opts := []ecbank.Option{
+		ecbank.WithLogger(a.newLogger()),
+		ecbank.WithStaleIfError(a.globals.maxStale),
+		ecbank.WithCacheDir(a.globals.cacheDir),
+	}
+	if a.globals.offline {
+		opts = append(opts, ecbank.WithOffline())
+	}
+
+	return ecbank.NewClient(a.globals.timeout, append(opts, extra...)...)
+}
+
+// rateSource provides the rates of the day.
+type rateSource interface {
+	Rates(ctx context.Context) (ecbank.RateTable, error)
+}
+
+// newRateSource returns the source of the rates of the day: the server of the -upstream flag if set,
+// the bank otherwise, through a client built with the extra options.
+func (a *app) newRateSource(extra ...ecbank.Option) rateSource {
+	if a.globals.upstream != "" {
+		return a.newUpstream()
+	}
+	return a.newClient(extra...)
+}
+
+// newUpstream returns a client of the server of the -upstream flag.
+func (a *app) newUpstream() apiclient.Client {
+	return apiclient.New(a.globals.upstream, apiclient.WithHTTPClient(&http.Client{Timeout: a.globals.timeout}))
+}
+
+// newLogger returns a logger writing to stderr, at info level if verbose and debug level if very verbose.
+// It returns nil when neither is requested, so that nothing is logged.
+func (a *app) newLogger() *slog.Logger {
+	level := slog.LevelInfo
+	switch {
+	case a.globals.veryVerbose:
+		level = slog.LevelDebug
+	case !a.globals.verbose:
+		return nil
+	}
+
+	return slog.New(slog.NewTextHandler(a.stderr, &slog.HandlerOptions{Level: level}))
+}
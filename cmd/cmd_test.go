@@ -0,0 +1,575 @@
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"moneyconverter/cmd"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const rates = `base,quote,rate,date
+EUR,USD,2,2025-04-08
+EUR,RON,5,2025-04-08
+`
+
+// writeRates writes the test rates to a file and returns its path.
+func writeRates(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	if err := os.WriteFile(path, []byte(rates), 0o644); err != nil {
+		t.Fatalf("unable to write rates: %s", err)
+	}
+	return path
+}
+
+// run runs the command line with the given stdin and returns its exit code, stdout and stderr.
+func run(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := cmd.Run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	ratesFile := writeRates(t)
+
+	tt := map[string]struct {
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+	}{
+		"convert": {
+			args:   []string{"convert", "-from", "EUR", "-to", "USD", "-rates-file", ratesFile, "10"},
+			code:   cmd.ExitOK,
+			stdout: "10.00 EUR - 20.00 USD\n",
+		},
+		"convert without the command": {
+			args:   []string{"-from", "EUR", "-to", "USD", "-rates-file", ratesFile, "10"},
+			code:   cmd.ExitOK,
+			stdout: "10.00 EUR - 20.00 USD\n",
+		},
+		"free form amount": {
+			args:   []string{"convert", "-rates-file", ratesFile, "1.5k", "usd", "to", "€"},
+			code:   cmd.ExitOK,
+			stdout: "1500.00 USD - 750.00 EUR\n",
+		},
+		"free form amount with a preferred symbol": {
+			args:   []string{"-prefer", "USD", "convert", "-rates-file", ratesFile, "$10", "→", "€"},
+			code:   cmd.ExitOK,
+			stdout: "10.00 USD - 5.00 EUR\n",
+		},
+		"ambiguous symbol": {
+			args: []string{"convert", "-rates-file", ratesFile, "$10 → €"},
+			code: cmd.ExitFailure,
+		},
+		"calc": {
+			args:   []string{"calc", "-rates-file", ratesFile, "(3 * 10 USD - 10%) / 2 in EUR"},
+			code:   cmd.ExitOK,
+			stdout: "6.75 EUR\n",
+		},
+		"calc syntax error": {
+			args: []string{"calc", "-rates-file", ratesFile, "10 EUR + * 3"},
+			code: cmd.ExitFailure,
+		},
+		"global flag before the command": {
+			args:   []string{"-offline", "convert", "-from", "EUR", "-to", "USD", "-rates-file", ratesFile, "10"},
+			code:   cmd.ExitOK,
+			stdout: "10.00 EUR - 20.00 USD\n",
+		},
+		"currencies": {
+			args:   []string{"currencies", "-rates-file", ratesFile},
+			code:   cmd.ExitOK,
+			stdout: "EUR\nRON\nUSD\n",
+		},
+		"convert-file with a failed row": {
+			args:   []string{"convert-file", "-to", "USD", "-rates-file", ratesFile},
+			stdin:  "amount,currency\n10,EUR\n10,JPY\n",
+			code:   cmd.ExitPartial,
+			stdout: "amount,currency,converted_amount,rate,rate_date\n10,EUR,20.00,2,2025-04-08\n10,JPY,,,\n",
+		},
+		"no arguments": {
+			code: cmd.ExitUsage,
+		},
+		"unknown command": {
+			args: []string{"frobnicate"},
+			code: cmd.ExitUsage,
+		},
+		"unknown flag": {
+			args: []string{"convert", "-frobnicate"},
+			code: cmd.ExitUsage,
+		},
+		"missing amount": {
+			args: []string{"convert", "-from", "EUR", "-rates-file", ratesFile},
+			code: cmd.ExitUsage,
+		},
+		"all the currencies, one too large": {
+			args:   []string{"convert", "-from", "EUR", "-to", "all", "-rates-file", ratesFile, "3000000000"},
+			code:   cmd.ExitPartial,
+			stdout: "3000000000.00 EUR\nUSD  6000000000.00  2\n",
+		},
+		"invalid sort": {
+			args: []string{"-offline", "convert", "-from", "EUR", "-sort", "foo", "100"},
+			code: cmd.ExitUsage,
+		},
+		"invalid source currency": {
+			args: []string{"convert", "-from", "US", "-rates-file", ratesFile, "100"},
+			code: cmd.ExitUsage,
+		},
+		"invalid target currency": {
+			args: []string{"convert", "-from", "EUR", "-to", "USD,EURO", "-rates-file", ratesFile, "100"},
+			code: cmd.ExitUsage,
+		},
+		"invalid currency in the free form": {
+			args: []string{"convert", "-rates-file", ratesFile, "100", "usdx", "to", "eur"},
+			code: cmd.ExitUsage,
+		},
+		"invalid rounding": {
+			args: []string{"convert", "-from", "EUR", "-rounding", "foo", "-rates-file", ratesFile, "100"},
+			code: cmd.ExitUsage,
+		},
+		"wanted amount and an amount": {
+			args: []string{"convert", "-from", "EUR", "-to", "USD", "-want", "500USD", "-rates-file", ratesFile, "100"},
+			code: cmd.ExitUsage,
+		},
+		"invalid wanted amount": {
+			args: []string{"convert", "-from", "EUR", "-want", "500", "-rates-file", ratesFile},
+			code: cmd.ExitUsage,
+		},
+		"convert-file with an invalid target currency": {
+			args:  []string{"convert-file", "-to", "US", "-rates-file", ratesFile},
+			stdin: "amount\n10\n",
+			code:  cmd.ExitUsage,
+		},
+		"convert-file with an invalid rounding": {
+			args:  []string{"convert-file", "-to", "USD", "-rounding", "foo", "-rates-file", ratesFile},
+			stdin: "amount\n10\n",
+			code:  cmd.ExitUsage,
+		},
+		"repl with an invalid base currency": {
+			args: []string{"repl", "-base", "EURO", "-rates-file", ratesFile},
+			code: cmd.ExitUsage,
+		},
+		"calc with an invalid rounding": {
+			args: []string{"calc", "-rounding", "foo", "-rates-file", ratesFile, "10 EUR in USD"},
+			code: cmd.ExitUsage,
+		},
+		"unknown rate": {
+			args: []string{"convert", "-from", "EUR", "-to", "JPY", "-rates-file", ratesFile, "10"},
+			code: cmd.ExitFailure,
+		},
+		"help": {
+			args: []string{"help", "convert"},
+			code: cmd.ExitOK,
+		},
+		"version": {
+			args: []string{"version"},
+			code: cmd.ExitOK,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			code, stdout, stderr := run(tc.stdin, tc.args...)
+			if code != tc.code {
+				t.Errorf("expected exit code %d, got %d, stderr: %s", tc.code, code, stderr)
+			}
+			if tc.stdout != "" && stdout != tc.stdout {
+				t.Errorf("expected stdout %q, got %q", tc.stdout, stdout)
+			}
+		})
+	}
+}
+
+func TestRun_ConvertFile(t *testing.T) {
+	ratesFile, dir := writeRates(t), t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("unable to write %s: %s", name, err)
+		}
+		return path
+	}
+
+	tt := map[string]struct {
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		"bad currency": {
+			args:   []string{"-to", "USD"},
+			stdin:  "amount,currency\n10,EUR\n10,EURO\n",
+			code:   cmd.ExitPartial,
+			stdout: "amount,currency,converted_amount,rate,rate_date\n10,EUR,20.00,2,2025-04-08\n10,EURO,,,\n",
+			stderr: `line 3: unable to parse currency "EURO"`,
+		},
+		"short row": {
+			args:   []string{"-to", "USD"},
+			stdin:  "id,amount,currency\n1,10,EUR\n2,10\n",
+			code:   cmd.ExitPartial,
+			stdout: "id,amount,currency,converted_amount,rate,rate_date\n1,10,EUR,20.00,2,2025-04-08\n2,10,,,\n",
+			stderr: "line 3: expected at least 3 fields, got 2",
+		},
+		"source currency without a currency column": {
+			args:   []string{"-from", "EUR", "-to", "RON"},
+			stdin:  "id,amount\n1,10\n2,0.5\n",
+			code:   cmd.ExitOK,
+			stdout: "id,amount,converted_amount,rate,rate_date\n1,10,50.00,5,2025-04-08\n2,0.5,2.50,5,2025-04-08\n",
+		},
+		"missing currency column": {
+			args:  []string{"-to", "RON"},
+			stdin: "id,amount\n1,10\n",
+			code:  cmd.ExitFailure,
+		},
+		"tsv detected by extension": {
+			args:   []string{"-to", "USD", write("amounts.TSV", "amount\tcurrency\n10\tEUR\n")},
+			code:   cmd.ExitOK,
+			stdout: "amount,currency,converted_amount,rate,rate_date\n10,EUR,20.00,2,2025-04-08\n",
+		},
+		"every row failing": {
+			args:   []string{"-to", "JPY"},
+			stdin:  "amount,currency\n10,EUR\n",
+			code:   cmd.ExitPartial,
+			stdout: "amount,currency,converted_amount,rate,rate_date\n10,EUR,,,\n",
+			stderr: "1 rows couldn't be converted",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			args := append([]string{"convert-file", "-rates-file", ratesFile}, tc.args...)
+			code, stdout, stderr := run(tc.stdin, args...)
+			if code != tc.code {
+				t.Errorf("expected exit code %d, got %d, stderr: %s", tc.code, code, stderr)
+			}
+			if tc.stdout != "" && stdout != tc.stdout {
+				t.Errorf("expected stdout %q, got %q", tc.stdout, stdout)
+			}
+			if !strings.Contains(stderr, tc.stderr) {
+				t.Errorf("expected stderr to contain %q, got %q", tc.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestRun_JSONError(t *testing.T) {
+	ratesFile := writeRates(t)
+
+	tt := map[string]struct {
+		args []string
+		code int
+		want string
+	}{
+		"unknown rate": {
+			args: []string{"convert", "-from", "EUR", "-to", "JPY", "-rates-file", ratesFile, "-output", "json", "10"},
+			code: cmd.ExitFailure,
+			want: "rate_not_found",
+		},
+		"invalid rounding": {
+			args: []string{"convert", "-from", "EUR", "-rounding", "foo", "-rates-file", ratesFile, "-output", "json", "10"},
+			code: cmd.ExitUsage,
+			want: "usage",
+		},
+		"unknown flag": {
+			args: []string{"convert", "-output", "json", "-bogus", "10"},
+			code: cmd.ExitUsage,
+			want: "usage",
+		},
+		"invalid flag after the output": {
+			args: []string{"calc", "-rates-file", ratesFile, "-output=json", "-max-stale", "soon", "10 EUR"},
+			code: cmd.ExitUsage,
+			want: "usage",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			code, _, stderr := run("", tc.args...)
+			if code != tc.code {
+				t.Errorf("expected exit code %d, got %d", tc.code, code)
+			}
+
+			var got struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(stderr), &got); err != nil {
+				t.Fatalf("unable to decode %q: %s", stderr, err)
+			}
+			if got.Error.Code != tc.want {
+				t.Errorf("expected code %s, got %q", tc.want, got.Error.Code)
+			}
+		})
+	}
+}
+
+func TestRun_Providers(t *testing.T) {
+	ratesFile := writeRates(t)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	code, stdout, stderr := run("", "-upstream", upstream.URL, "-providers", "upstream,file",
+		"convert", "-from", "EUR", "-to", "USD", "-rates-file", ratesFile, "-explain", "10")
+	if code != cmd.ExitOK {
+		t.Fatalf("expected exit code %d, got %d, stderr: %s", cmd.ExitOK, code, stderr)
+	}
+	for _, want := range []string{"10.00 EUR - 20.00 USD\n", "  provider:     ratefile\n", "  failed:       upstream: "} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected stdout to contain %q, got %q", want, stdout)
+		}
+	}
+
+	for _, providers := range []string{"file,ftp", "upstream", "bank,file"} {
+		args := []string{"-providers", providers, "convert", "-from", "EUR", "-to", "USD", "10"}
+		if code, _, stderr := run("", args...); code != cmd.ExitUsage {
+			t.Errorf("expected exit code %d for providers %q, got %d, stderr: %s", cmd.ExitUsage, providers, code, stderr)
+		}
+	}
+}
+
+func TestRun_ProvidersGraph(t *testing.T) {
+	ratesFile := filepath.Join(t.TempDir(), "crypto.csv")
+	if err := os.WriteFile(ratesFile, []byte("base,quote,rate,date\nBTC,USD,60000,2025-04-08\n"), 0o644); err != nil {
+		t.Fatalf("unable to write rates: %s", err)
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"base":"EUR","date":"2025-04-08","stale":false,"rates":{"EUR":"1","USD":"1.25","JPY":"160"}}`)
+	}))
+	defer upstream.Close()
+
+	args := []string{"-upstream", upstream.URL, "-providers", "file,upstream", "convert", "-from", "BTC", "-to", "JPY", "-rates-file", ratesFile}
+	code, stdout, stderr := run("", append(args, "-explain", "1")...)
+	if code != cmd.ExitOK {
+		t.Fatalf("expected exit code %d, got %d, stderr: %s", cmd.ExitOK, code, stderr)
+	}
+	for _, want := range []string{"1.00 BTC - 7680000.00 JPY\n", "  provider:     graph\n", "  path:         BTC→USD→EUR→JPY\n"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected stdout to contain %q, got %q", want, stdout)
+		}
+	}
+
+	code, stdout, stderr = run("", append(args, "-output", "kv", "1")...)
+	if code != cmd.ExitOK {
+		t.Fatalf("expected exit code %d, got %d, stderr: %s", cmd.ExitOK, code, stderr)
+	}
+	if !strings.HasSuffix(stdout, " provider=graph cache=fresh fetched_at=\"\" path=BTC→USD→EUR→JPY\n") {
+		t.Errorf("expected the path in the record, got %q", stdout)
+	}
+}
+
+func TestRun_Cache(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mc_data_20250408.txt"), []byte("<Envelope/>"), 0o644); err != nil {
+		t.Fatalf("unable to write cache file: %s", err)
+	}
+
+	code, stdout, stderr := run("", "cache", "info", "-cache-dir", dir)
+	if code != cmd.ExitOK {
+		t.Fatalf("expected exit code %d, got %d, stderr: %s", cmd.ExitOK, code, stderr)
+	}
+	if !strings.Contains(stdout, "2025-04-08") || !strings.Contains(stdout, "1 cached feeds") {
+		t.Errorf("expected the cached feed to be listed, got %q", stdout)
+	}
+
+	code, _, stderr = run("", "-cache-dir", dir, "cache", "clear")
+	if code != cmd.ExitOK {
+		t.Fatalf("expected exit code %d, got %d, stderr: %s", cmd.ExitOK, code, stderr)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "mc_data_20250408.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected the cached feed to be deleted, got %v", err)
+	}
+
+	code, _, _ = run("", "cache", "frobnicate")
+	if code != cmd.ExitUsage {
+		t.Errorf("expected exit code %d, got %d", cmd.ExitUsage, code)
+	}
+}
+
+func TestRun_Repl(t *testing.T) {
+	ratesFile, dir := writeRates(t), t.TempDir()
+	session := "a = 10 EUR\nb = 5 usd\na + b\nset base USD\na * 2\nfoo bar\nquit\n"
+
+	code, stdout, stderr := run(session, "repl", "-cache-dir", dir, "-rates-file", ratesFile)
+	if code != cmd.ExitOK {
+		t.Fatalf("expected exit code %d, got %d, stderr: %s", cmd.ExitOK, code, stderr)
+	}
+
+	for _, expected := range []string{"a = 10.00 EUR", "b = 5.00 USD", "12.50 EUR", "Converting to USD.", "40.00 USD"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("expected %q in the output, got %q", expected, stdout)
+		}
+	}
+	if !strings.Contains(stderr, "syntax error at column 5") {
+		t.Errorf("expected the invalid command to be reported, got %q", stderr)
+	}
+
+	code, stdout, _ = run("history\n", "repl", "-cache-dir", dir, "-rates-file", ratesFile)
+	if code != cmd.ExitOK {
+		t.Fatalf("expected exit code %d, got %d", cmd.ExitOK, code)
+	}
+	if !strings.Contains(stdout, "1  a = 10 EUR") || !strings.Contains(stdout, "5  a * 2") {
+		t.Errorf("expected the commands of the previous session in the history, got %q", stdout)
+	}
+	if strings.Contains(stdout, "foo bar") || strings.Contains(stdout, "quit") {
+		t.Errorf("expected the failed commands and quit to be left out of the history, got %q", stdout)
+	}
+}
+
+func TestRun_Watch(t *testing.T) {
+	const feed = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time='2025-04-08'>
+			<Cube currency='USD' rate='2.0000'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mc_data_20250408.txt"), []byte(feed), 0o644); err != nil {
+		t.Fatalf("unable to write cache file: %s", err)
+	}
+
+	code, stdout, stderr := run("", "watch", "-once", "-offline", "-cache-dir", dir, "USD/EUR < 0.6", "EUR/USD > 3")
+	if code != cmd.ExitOK {
+		t.Fatalf("expected exit code %d, got %d, stderr: %s", cmd.ExitOK, code, stderr)
+	}
+
+	var event map[string]any
+	if err := json.Unmarshal([]byte(stdout), &event); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %s", stdout, err)
+	}
+	if event["rule"] != "USD/EUR < 0.6" || event["rate"] != "0.5" || event["date"] != "2025-04-08" {
+		t.Errorf("unexpected event %v", event)
+	}
+
+	received := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- string(body)
+	}))
+	defer receiver.Close()
+
+	code, stdout, stderr = run("", "watch", "-once", "-offline", "-cache-dir", dir, "-webhook", receiver.URL, "EUR/USD >= 2")
+	if code != cmd.ExitOK || stdout != "" {
+		t.Fatalf("expected exit code %d and nothing on stdout, got %d and %q, stderr: %s", cmd.ExitOK, code, stdout, stderr)
+	}
+	if body := <-received; !strings.Contains(body, `"rule":"EUR/USD >= 2"`) {
+		t.Errorf("expected the event to be posted, got %q", body)
+	}
+
+	code, _, stderr = run("", "watch", "-once", "USD/EUR ~ 0.6")
+	if code != cmd.ExitUsage || !strings.Contains(stderr, "invalid rule") {
+		t.Errorf("expected exit code %d for an invalid rule, got %d, stderr: %s", cmd.ExitUsage, code, stderr)
+	}
+
+	for _, retry := range []string{"0s", "-1m"} {
+		code, _, stderr = run("", "watch", "-retry", retry, "USD/EUR > 0.6")
+		if code != cmd.ExitUsage || !strings.Contains(stderr, "invalid retry interval") {
+			t.Errorf("expected exit code %d for a retry of %s, got %d, stderr: %s", cmd.ExitUsage, retry, code, stderr)
+		}
+	}
+}
+
+func TestRun_Config(t *testing.T) {
+	ratesFile, home := writeRates(t), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	config := `{"from": "EUR", "to": "USD", "locale": "de-DE", "convert_file": {"to": "RON"}, "providers": {"rates_file": ` + strconvQuote(ratesFile) + `}}`
+	if err := os.MkdirAll(filepath.Join(home, "moneyconverter"), 0o755); err != nil {
+		t.Fatalf("unable to create configuration directory: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "moneyconverter", "config.json"), []byte(config), 0o644); err != nil {
+		t.Fatalf("unable to write configuration: %s", err)
+	}
+
+	tt := map[string]struct {
+		env    string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+	}{
+		"configuration":          {args: []string{"convert", "1000"}, code: cmd.ExitOK, stdout: "1.000,00 EUR - 2.000,00 USD\n"},
+		"list of targets":        {env: "USD,RON", args: []string{"convert", "1000"}, code: cmd.ExitOK, stdout: "1.000,00 EUR\nRON  5.000,00  5\nUSD  2.000,00  2\n"},
+		"target of convert-file": {env: "USD,RON", args: []string{"convert-file"}, stdin: "amount\n1000\n", code: cmd.ExitOK, stdout: "amount,converted_amount,rate,rate_date\n1000,5000.00,5,2025-04-08\n"},
+		"environment":            {env: "RON", args: []string{"convert", "1000"}, code: cmd.ExitOK, stdout: "1.000,00 EUR - 5.000,00 RON\n"},
+		"flag":                   {env: "RON", args: []string{"-locale", "", "convert", "-to", "USD", "1000"}, code: cmd.ExitOK, stdout: "1000.00 EUR - 2000.00 USD\n"},
+		"free form":              {env: "RON", args: []string{"10", "usd", "in", "eur"}, code: cmd.ExitOK, stdout: "10,00 USD - 5,00 EUR\n"},
+		"missing explicit file":  {args: []string{"convert", "-config", filepath.Join(home, "missing.toml"), "1000"}, code: cmd.ExitFailure},
+		"invalid flag":           {env: "RON", args: []string{"-timeout", "1s", "convert", "-max-stale", "soon", "1000"}, code: cmd.ExitUsage},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if tc.env != "" {
+				t.Setenv("MONEYCONVERTER_TO", tc.env)
+			}
+
+			code, stdout, stderr := run(tc.stdin, tc.args...)
+			if code != tc.code {
+				t.Fatalf("expected exit code %d, got %d, stderr: %s", tc.code, code, stderr)
+			}
+			if stdout != tc.stdout {
+				t.Errorf("expected stdout %q, got %q", tc.stdout, stdout)
+			}
+		})
+	}
+
+	t.Setenv("MONEYCONVERTER_TO", "RON")
+	code, stdout, stderr := run("", "config", "show", "-timeout", "5s")
+	if code != cmd.ExitOK {
+		t.Fatalf("expected exit code %d, got %d, stderr: %s", cmd.ExitOK, code, stderr)
+	}
+
+	shown := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.Fields(line)
+		shown[fields[0]] = strings.Join(fields[1:], " ")
+	}
+
+	for key, want := range map[string]string{
+		"from":            "EUR config " + filepath.Join(home, "moneyconverter", "config.json"),
+		"to":              "RON env MONEYCONVERTER_TO",
+		"timeout":         "5s flag -timeout",
+		"rounding":        "down default",
+		"convert_file.to": "RON config " + filepath.Join(home, "moneyconverter", "config.json"),
+	} {
+		if shown[key] != want {
+			t.Errorf("expected %s to be shown as %q, got %q", key, want, shown[key])
+		}
+	}
+
+	t.Setenv("MONEYCONVERTER_PROVIDERS_ORDER", "upstream,file")
+	code, _, stderr = run("", "convert", "1000")
+	if code != cmd.ExitUsage || !strings.Contains(stderr, "the upstream provider needs the -upstream flag") {
+		t.Errorf("expected exit code %d for an upstream provider without server, got %d, stderr: %s", cmd.ExitUsage, code, stderr)
+	}
+	t.Setenv("MONEYCONVERTER_PROVIDERS_ORDER", "")
+
+	t.Setenv("MONEYCONVERTER_TIMEOUT", "soon")
+	code, _, stderr = run("", "convert", "1000")
+	if code != cmd.ExitUsage || !strings.Contains(stderr, "MONEYCONVERTER_TIMEOUT") {
+		t.Errorf("expected exit code %d naming the invalid variable, got %d, stderr: %s", cmd.ExitUsage, code, stderr)
+	}
+}
+
+// strconvQuote quotes a string for a JSON document.
+func strconvQuote(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
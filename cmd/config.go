@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// runConfig inspects the configuration.
+func (a *app) runConfig(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		_, _ = fmt.Fprintln(a.stderr, "Usage: moneyconverter config show [flags]")
+		if len(args) == 0 {
+			return usageError("missing config action")
+		}
+		return nil
+	}
+
+	if args[0] != "show" {
+		return usageError(fmt.Sprintf("unknown config action %q, expected show", args[0]))
+	}
+	return a.runConfigShow(args[1:])
+}
+
+// configEntry is a setting as printed by config show.
+type configEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	// Origin is the flag, the environment variable or the configuration file the value comes from.
+	Origin string `json:"origin,omitempty"`
+}
+
+// runConfigShow prints the effective value of every setting and where it comes from.
+func (a *app) runConfigShow(args []string) error {
+	flags := a.newFlagSet("config show", "",
+		"Prints the effective value of every setting and where it comes from: a flag, an environment variable,\n"+
+			"the configuration file or the built-in default, flags taking precedence over the environment, then the file.\n"+
+			"The file is that of -config or MONEYCONVERTER_CONFIG, otherwise $XDG_CONFIG_HOME/moneyconverter/config.toml\n"+
+			"or config.json, such as:\n"+
+			"  to = [\"USD\", \"GBP\"]\n"+
+			"  rounding = \"half-even\"\n"+
+			"  [convert_file]\n"+
+			"  to = \"USD\"\n"+
+			"  [providers]\n"+
+			"  upstream = \"http://rates.example.com\"\n"+
+			"  order = [\"upstream\", \"bank\"]\n"+
+			"  [cache]\n"+
+			"  dir = \"/var/cache/moneyconverter\"\n"+
+			"to holds the target currencies of convert, convert_file.to the single one of convert-file,\n"+
+			"and providers.order the providers tried in turn, as the -providers flag does.")
+	output := flags.String("output", outputText, "output format: text or json")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	if *output != outputText && *output != outputJSON {
+		return usageError(fmt.Sprintf("unknown output %q, expected text or json", *output))
+	}
+	a.output = *output
+
+	config, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]configEntry, len(settings))
+	for i, s := range settings {
+		r := a.resolve(s, flags, config)
+		entries[i] = configEntry{Key: s.key, Value: r.value, Source: r.source, Origin: r.origin}
+	}
+
+	if a.output == outputJSON {
+		encoder := json.NewEncoder(a.stdout)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	keyWidth, valueWidth := 0, len(`""`)
+	for _, entry := range entries {
+		keyWidth = max(keyWidth, len(entry.Key))
+		valueWidth = max(valueWidth, len(quoteValue(entry.Value)))
+	}
+
+	for _, entry := range entries {
+		source := entry.Source
+		if entry.Origin != "" {
+			source += " " + entry.Origin
+		}
+		line := fmt.Sprintf("%-*s  %-*s  %s", keyWidth, entry.Key, valueWidth, quoteValue(entry.Value), source)
+		_, _ = fmt.Fprintln(a.stdout, strings.TrimRight(line, " "))
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"moneyconverter/ecbank"
+	"moneyconverter/money"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// runConvert converts an amount to one or more currencies.
+func (a *app) runConvert(args []string) error {
+	flags := a.newFlagSet("convert", "<amount> [[to] <currencies>]",
+		"Converts an amount of the source currency to one or more target currencies.\n"+
+			"The amount may be written in free form, such as 100 usd to gbp, 100USD in EUR, $100 → € or 1.5k JPY to EUR,\n"+
+			"the flags giving the currencies left out.")
+	from := flags.String("from", "", "source currency, required unless written with the amount")
+	to := flags.String("to", "EUR", "target currency, a comma-separated list of them, or all")
+	sortBy := flags.String("sort", sortByCode, "order of the conversions to several currencies: code or value")
+	clearCache := flags.Bool("clear", false, "clears all cache, deprecated: use the cache clear command")
+	ratesFile := flags.String("rates-file", "", "read rates from a local .csv, .tsv, .json or ECB .xml file instead of the bank")
+	rounding := flags.String("rounding", "down", "rounding of the converted amount: down, half-up, half-even or up")
+	explain := flags.Bool("explain", false, "detail the rate, its provider and the rounding of the conversion")
+	want := flags.String("want", "", "amount to end up with, such as 500EUR: computes the smallest amount of the source currency converting to it")
+	output := flags.String("output", outputText, "output format: text, json (one object per line), csv, tsv or kv (key=value)")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	if err := validateOutput(*output); err != nil {
+		return fmt.Errorf("unable to parse output: %w", err)
+	}
+	a.output = *output
+
+	if *clearCache {
+		if err := a.newClient().ClearCache(); err != nil {
+			return fmt.Errorf("unable to clear cache files: %w", err)
+		}
+		_, _ = fmt.Fprintln(a.stdout, "Cache cleared. Exiting...")
+		return nil
+	}
+
+	preferred, err := a.preferredCurrencies()
+	if err != nil {
+		return err
+	}
+
+	if *want != "" && flags.NArg() > 0 {
+		return usageError("-want replaces the amount argument")
+	}
+
+	var value string
+	if flags.NArg() > 0 {
+		input := strings.Join(flags.Args(), " ")
+		q, err := parseQuery(input, preferred)
+		switch {
+		case errors.Is(err, money.ErrAmbiguousCurrency):
+			return fmt.Errorf("unable to parse %q: %w, pick one with -prefer", input, err)
+		case err != nil:
+			return usageError(fmt.Sprintf("unable to parse %q: %s", input, err))
+		}
+
+		value = q.quantity
+		if q.from != "" {
+			*from = q.from
+		}
+		if q.to != "" {
+			*to = q.to
+		}
+	}
+
+	fromCurrency, err := money.ParseCurrencySymbol(*from, preferred...)
+	if err != nil {
+		return usageError(fmt.Sprintf("unable to parse source currency %q: %s", *from, err))
+	}
+
+	targets, err := parseTargets(*to)
+	if err != nil {
+		return usageError(fmt.Sprintf("unable to parse target currency: %s", err))
+	}
+
+	roundingMode, err := money.ParseRoundingMode(*rounding)
+	if err != nil {
+		return usageError(fmt.Sprintf("unable to parse rounding %q: %s", *rounding, err))
+	}
+
+	if err := validateSort(*sortBy); err != nil {
+		return err
+	}
+
+	var amount, wanted money.Amount
+	if *want != "" {
+		wanted, err = parseAmount(*want)
+		if err != nil {
+			return usageError(fmt.Sprintf("unable to parse wanted amount %q: %s", *want, err))
+		}
+		targets = []money.Currency{wanted.Currency()}
+	} else {
+		if value == "" {
+			return usageError("missing amount to convert")
+		}
+
+		amount, err = readAmount(value, fromCurrency)
+		if err != nil {
+			return fmt.Errorf("unable to parse value %q: %w", value, err)
+		}
+	}
+
+	format, err := parseLocale(a.globals.locale)
+	if err != nil {
+		return err
+	}
+
+	if *explain && len(targets) != 1 {
+		return usageError("-explain needs a single target currency")
+	}
+
+	rates, notice, err := a.loadRates(*ratesFile)
+	if err != nil {
+		return fmt.Errorf("unable to fetch exchange rates: %w", err)
+	}
+
+	toAll := targets == nil
+	if toAll {
+		targets, err = supportedTargets(rates, fromCurrency)
+		if err != nil {
+			return fmt.Errorf("unable to list target currencies: %w", err)
+		}
+	}
+
+	if *want != "" {
+		amount, err = money.RequiredSource(wanted, fromCurrency, rates, roundingMode)
+		if err != nil {
+			return fmt.Errorf("unable to compute the %s needed for %s: %w", fromCurrency, wanted, err)
+		}
+	}
+
+	conversions, failures := convertToAll(amount, targets, rates, roundingMode, *sortBy)
+	if len(failures) > 0 && (!toAll || len(conversions) == 0) {
+		return failures[0]
+	}
+
+	switch {
+	case *output != outputText:
+		if err := writeConversions(a.stdout, *output, conversions); err != nil {
+			return fmt.Errorf("unable to write conversions: %w", err)
+		}
+	case len(targets) > 1:
+		_, _ = fmt.Fprintf(a.stdout, "%s%s\n", format.amount(amount), notice)
+		printTable(a.stdout, conversions, format)
+	default:
+		_, _ = fmt.Fprintf(a.stdout, "%s - %s%s\n", format.amount(amount), format.amount(conversions[0].Output), notice)
+		if *explain {
+			printExplanation(a.stdout, conversions[0])
+		}
+	}
+
+	// converting to all the currencies, those that failed are reported after the others.
+	for _, failure := range failures {
+		writeError(a.stderr, a.output, failure)
+	}
+	if len(failures) > 0 {
+		return partialError(fmt.Sprintf("%d currencies couldn't be converted", len(failures)))
+	}
+
+	return nil
+}
+
+// staleNotice returns a warning to append to the output when the rates are outdated.
+func staleNotice(rates ecbank.RateTable) string {
+	if !rates.Stale {
+		return ""
+	}
+	return fmt.Sprintf(" (stale rates published on %s)", rates.Date.Format(time.DateOnly))
+}
+
+// readAmount parses the value of the amount to convert, in the given currency.
+func readAmount(value string, currency money.Currency) (money.Amount, error) {
+	quantity, err := money.ParseDecimal(value)
+	if err != nil {
+		return money.Amount{}, err
+	}
+
+	return money.NewAmount(quantity, currency)
+}
+
+// parseAmount parses an amount followed by its currency code, such as 500EUR or "500 EUR".
+func parseAmount(value string) (money.Amount, error) {
+	value = strings.TrimSpace(value)
+	split := strings.LastIndexFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) + 1
+
+	currency, err := money.ParseCurrency(value[split:])
+	if err != nil {
+		return money.Amount{}, err
+	}
+
+	quantity, err := money.ParseDecimal(strings.TrimSpace(value[:split]))
+	if err != nil {
+		return money.Amount{}, err
+	}
+
+	return money.NewAmount(quantity, currency)
+}
+
+// printExplanation writes the details of a conversion, one per line.
+func printExplanation(w io.Writer, c money.Conversion) {
+	_, _ = fmt.Fprintf(w, "  rate:         %s\n", c.Rate)
+	_, _ = fmt.Fprintf(w, "  inverse rate: %s\n", c.InverseRate)
+	if c.Provider != "" {
+		_, _ = fmt.Fprintf(w, "  provider:     %s\n", c.Provider)
+	}
+	if !c.Path.IsZero() {
+		_, _ = fmt.Fprintf(w, "  path:         %s\n", c.Path)
+	}
+	for _, failure := range c.Failures {
+		_, _ = fmt.Fprintf(w, "  failed:       %s\n", failure)
+	}
+	if !c.PublishedAt.IsZero() {
+		_, _ = fmt.Fprintf(w, "  published:    %s\n", c.PublishedAt.Format(time.DateOnly))
+	}
+	if !c.FetchedAt.IsZero() {
+		_, _ = fmt.Fprintf(w, "  fetched:      %s\n", c.FetchedAt.Format(time.RFC3339))
+	}
+	_, _ = fmt.Fprintf(w, "  rounding:     %s\n", c.Rounding)
+	_, _ = fmt.Fprintf(w, "  remainder:    %s %s\n", c.Remainder.String(), c.Output.Currency().ISOCode())
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"moneyconverter/money"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runConvertFile converts the amounts of a CSV or TSV file, or of stdin, and writes the rows to stdout as CSV
+// with the converted amount, the rate and its date appended.
+func (a *app) runConvertFile(args []string) error {
+	flags := a.newFlagSet("convert-file", "[file]", "Converts the amounts of a CSV or TSV file, reading stdin when the file is missing or -.\n"+
+		"The rows are written to stdout as CSV with the converted amount, the rate and its date appended.")
+	from := flags.String("from", "", "source currency of every amount, instead of reading it from a column")
+	to := flags.String("to", "EUR", "target currency")
+	amountColumn := flags.String("amount-column", "amount", "name of the column holding the amounts")
+	currencyColumn := flags.String("currency-column", "currency", "name of the column holding the currencies of the amounts")
+	tsv := flags.Bool("tsv", false, "read tab-separated values, the default for .tsv files")
+	ratesFile := flags.String("rates-file", "", "read rates from a local .csv, .tsv, .json or ECB .xml file instead of the bank")
+	rounding := flags.String("rounding", "down", "rounding of the converted amounts: down, half-up, half-even or up")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	toCurrency, err := money.ParseCurrency(*to)
+	if err != nil {
+		return usageError(fmt.Sprintf("unable to parse target currency %q: %s", *to, err))
+	}
+
+	var fromCurrency money.Currency
+	if *from != "" {
+		fromCurrency, err = money.ParseCurrency(*from)
+		if err != nil {
+			return usageError(fmt.Sprintf("unable to parse source currency %q: %s", *from, err))
+		}
+	}
+
+	roundingMode, err := money.ParseRoundingMode(*rounding)
+	if err != nil {
+		return usageError(fmt.Sprintf("unable to parse rounding %q: %s", *rounding, err))
+	}
+
+	input := a.stdin
+	if name := flags.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("unable to open input file: %w", err)
+		}
+		defer f.Close()
+
+		input = f
+		*tsv = *tsv || strings.EqualFold(filepath.Ext(name), ".tsv")
+	}
+
+	rates, _, err := a.loadRates(*ratesFile)
+	if err != nil {
+		return fmt.Errorf("unable to fetch exchange rates: %w", err)
+	}
+
+	reader := csv.NewReader(bufio.NewReader(input))
+	if *tsv {
+		reader.Comma = '\t'
+	}
+
+	job := fileConversion{
+		columns:   columnNames{amount: *amountColumn, currency: *currencyColumn},
+		from:      fromCurrency,
+		to:        toCurrency,
+		rates:     rates,
+		rounding:  roundingMode,
+		errOutput: a.stderr,
+	}
+
+	stdout := bufio.NewWriter(a.stdout)
+	failed, err := job.run(reader, csv.NewWriter(stdout))
+	if flushErr := stdout.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to convert file: %w", err)
+	}
+
+	if failed > 0 {
+		return partialError(fmt.Sprintf("%d rows couldn't be converted", failed))
+	}
+
+	return nil
+}
+
+// columnNames names the columns of the input file.
+type columnNames struct {
+	amount   string
+	currency string
+}
+
+// fileConversion converts the amounts of the rows of a file.
+type fileConversion struct {
+	columns columnNames
+	// from is the currency of every amount, or unset to read it from the currency column.
+	from      money.Currency
+	to        money.Currency
+	rates     money.RatesFetcher
+	rounding  money.RoundingMode
+	errOutput io.Writer
+}
+
+// pendingRow is a row read from the input, waiting for its conversion.
+type pendingRow struct {
+	record []string
+	line   int
+	// err tells why the amount of the row couldn't be read, in which case it isn't converted.
+	err error
+}
+
+// run reads the rows of r, writes them to w with the conversion appended, and returns how many rows failed.
+// The rows that fail are written with empty conversion columns, and reported to errOutput with their line number.
+func (c fileConversion) run(r *csv.Reader, w *csv.Writer) (int, error) {
+	// rows missing fields are reported one by one rather than failing the whole file.
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return 0, fmt.Errorf("unable to read header: %w", err)
+	}
+
+	amountIndex, currencyIndex, err := c.columnIndices(header)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.Write(append(header, "converted_amount", "rate", "rate_date")); err != nil {
+		return 0, err
+	}
+
+	// the rows are queued while their amounts are converted by chunks, and written once their result comes.
+	var queue []pendingRow
+	var readErr error
+	failed := 0
+
+	amounts := func(yield func(money.Amount) bool) {
+		for {
+			record, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				readErr = err
+				return
+			}
+
+			line, _ := r.FieldPos(0)
+			amount, err := c.readAmount(record, amountIndex, currencyIndex)
+			queue = append(queue, pendingRow{record: record, line: line, err: err})
+			if err == nil && !yield(amount) {
+				return
+			}
+		}
+	}
+
+	// writeRejected writes the rows at the front of the queue whose amount couldn't be read.
+	writeRejected := func() error {
+		for len(queue) > 0 && queue[0].err != nil {
+			row := queue[0]
+			queue = queue[1:]
+
+			failed++
+			c.reportRow(row.line, row.err)
+			if err := w.Write(append(row.record, "", "", "")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for result := range money.ConvertStream(amounts, c.to, c.rates, money.WithRounding(c.rounding)) {
+		if err := writeRejected(); err != nil {
+			return failed, err
+		}
+
+		row := queue[0]
+		queue = queue[1:]
+
+		converted := []string{"", "", ""}
+		if result.Err != nil {
+			failed++
+			c.reportRow(row.line, result.Err)
+		} else {
+			quantity := result.Amount.Quantity()
+			converted[0] = quantity.String()
+			converted[1] = result.Rate.Rate.String()
+			if !result.Rate.PublishedAt.IsZero() {
+				converted[2] = result.Rate.PublishedAt.Format(time.DateOnly)
+			}
+		}
+
+		if err := w.Write(append(row.record, converted...)); err != nil {
+			return failed, err
+		}
+	}
+
+	if err := writeRejected(); err != nil {
+		return failed, err
+	}
+
+	w.Flush()
+	if readErr != nil {
+		return failed, readErr
+	}
+
+	return failed, w.Error()
+}
+
+// columnIndices returns the indices of the amount and currency columns within the header.
+// The currency index is -1 when every amount is in the same currency.
+func (c fileConversion) columnIndices(header []string) (int, int, error) {
+	find := func(name string) int {
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	amountIndex := find(c.columns.amount)
+	if amountIndex < 0 {
+		return 0, 0, fmt.Errorf("missing amount column %q", c.columns.amount)
+	}
+
+	if c.from != (money.Currency{}) {
+		return amountIndex, -1, nil
+	}
+
+	currencyIndex := find(c.columns.currency)
+	if currencyIndex < 0 {
+		return 0, 0, fmt.Errorf("missing currency column %q, or use -from", c.columns.currency)
+	}
+
+	return amountIndex, currencyIndex, nil
+}
+
+// readAmount returns the amount of a row.
+func (c fileConversion) readAmount(record []string, amountIndex, currencyIndex int) (money.Amount, error) {
+	if amountIndex >= len(record) || currencyIndex >= len(record) {
+		return money.Amount{}, fmt.Errorf("expected at least %d fields, got %d", max(amountIndex, currencyIndex)+1, len(record))
+	}
+
+	currency := c.from
+	if currencyIndex >= 0 {
+		var err error
+		currency, err = money.ParseCurrency(strings.TrimSpace(record[currencyIndex]))
+		if err != nil {
+			return money.Amount{}, fmt.Errorf("unable to parse currency %q: %w", record[currencyIndex], err)
+		}
+	}
+
+	value := strings.TrimSpace(record[amountIndex])
+	amount, err := readAmount(value, currency)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("unable to parse value %q: %w", value, err)
+	}
+
+	return amount, nil
+}
+
+// reportRow writes the error of a row to the error output.
+func (c fileConversion) reportRow(line int, err error) {
+	_, _ = fmt.Fprintf(c.errOutput, "line %d: %s.\n", line, err.Error())
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"moneyconverter/money"
+	"os"
+	"time"
+)
+
+// runHistory queries and maintains the history of the publications of the bank.
+func (a *app) runHistory(args []string) error {
+	actions := map[string]func([]string) error{
+		"show":    a.runHistoryShow,
+		"import":  a.runHistoryImport,
+		"verify":  a.runHistoryVerify,
+		"compact": a.runHistoryCompact,
+	}
+
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		_, _ = fmt.Fprintln(a.stderr, "Usage: moneyconverter history show|import|verify|compact [flags]")
+		if len(args) == 0 {
+			return usageError("missing history action")
+		}
+		return nil
+	}
+
+	action, ok := actions[args[0]]
+	if !ok {
+		return usageError(fmt.Sprintf("unknown history action %q, expected show, import, verify or compact", args[0]))
+	}
+
+	return action(args[1:])
+}
+
+// runHistoryShow prints the rates of a currency between two days, one publication per line.
+func (a *app) runHistoryShow(args []string) error {
+	flags := a.newFlagSet("history show", "<currency>", "Prints the recorded rates of a currency against the base, oldest first.")
+	base := flags.String("base", "EUR", "base currency of the rates")
+	start := flags.String("start", "", "first day of the rates, YYYY-MM-DD, the oldest publication if empty")
+	end := flags.String("end", "", "last day of the rates, YYYY-MM-DD, today if empty")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageError("expected one currency to show")
+	}
+
+	baseCurrency, err := money.ParseCurrency(*base)
+	if err != nil {
+		return usageError(fmt.Sprintf("unable to parse base currency %q: %s", *base, err))
+	}
+
+	currency, err := money.ParseCurrency(flags.Arg(0))
+	if err != nil {
+		return usageError(fmt.Sprintf("unable to parse currency %q: %s", flags.Arg(0), err))
+	}
+
+	startDay, err := parseDay(*start, time.Time{})
+	if err != nil {
+		return usageError(fmt.Sprintf("invalid start day %q", *start))
+	}
+
+	endDay, err := parseDay(*end, time.Now())
+	if err != nil {
+		return usageError(fmt.Sprintf("invalid end day %q", *end))
+	}
+
+	series, err := a.newClient().History().Series(baseCurrency, currency, startDay, endDay)
+	if err != nil {
+		return fmt.Errorf("unable to read history: %w", err)
+	}
+
+	for _, point := range series.Points() {
+		_, _ = fmt.Fprintf(a.stdout, "%s %s\n", point.Date.Format(time.DateOnly), point.Rate)
+	}
+
+	return nil
+}
+
+// runHistoryImport merges the publications of an ECB feed file into the history.
+func (a *app) runHistoryImport(args []string) error {
+	flags := a.newFlagSet("history import", "<file>", "Merges the publications of an ECB feed file, such as the 90 days one, into the history.")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageError("expected one feed file to import")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("unable to open feed: %w", err)
+	}
+	defer f.Close()
+
+	added, err := a.newClient().History().Import(f)
+	if err != nil {
+		return fmt.Errorf("unable to import feed: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(a.stdout, "%d publications imported.\n", added)
+	return nil
+}
+
+// runHistoryVerify reports the duplicated and corrupted lines of the history.
+func (a *app) runHistoryVerify(args []string) error {
+	flags := a.newFlagSet("history verify", "", "Reads the whole history and reports duplicated and corrupted lines.")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	check, err := a.newClient().History().Verify()
+	if err != nil {
+		return fmt.Errorf("unable to read history: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(a.stdout, "%d publications, %d duplicates, %d corrupted lines\n",
+		check.Publications, check.Duplicates, len(check.Corrupted))
+	for _, line := range check.Corrupted {
+		_, _ = fmt.Fprintf(a.stdout, "line %d is corrupted\n", line)
+	}
+
+	if len(check.Corrupted) > 0 {
+		return partialError("the history holds corrupted lines, run history compact to drop them")
+	}
+
+	return nil
+}
+
+// runHistoryCompact rewrites the history with one line per publication.
+func (a *app) runHistoryCompact(args []string) error {
+	flags := a.newFlagSet("history compact", "", "Rewrites the history with one line per publication, dropping duplicated and corrupted lines.")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	if err := a.newClient().History().Compact(); err != nil {
+		return fmt.Errorf("unable to compact history: %w", err)
+	}
+
+	_, _ = fmt.Fprintln(a.stdout, "History compacted.")
+	return nil
+}
+
+// parseDay parses a day written as YYYY-MM-DD, returning the fallback if empty.
+func parseDay(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
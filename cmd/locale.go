@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"moneyconverter/money"
+	"strings"
+)
+
+// numberFormat tells how a locale writes numbers.
+type numberFormat struct {
+	// decimal separates the integer part from the fraction, group separates the thousands, if any.
+	decimal, group string
+}
+
+// plainFormat writes numbers with a dot and no grouping, as the machine-readable outputs do.
+var plainFormat = numberFormat{decimal: "."}
+
+// localeFormats holds the way numbers are written by language, or by language and region when it differs.
+var localeFormats = map[string]numberFormat{
+	"en":    {decimal: ".", group: ","},
+	"ja":    {decimal: ".", group: ","},
+	"ko":    {decimal: ".", group: ","},
+	"zh":    {decimal: ".", group: ","},
+	"de":    {decimal: ",", group: "."},
+	"de-ch": {decimal: ".", group: "'"},
+	"es":    {decimal: ",", group: "."},
+	"it":    {decimal: ",", group: "."},
+	"nl":    {decimal: ",", group: "."},
+	"pt":    {decimal: ",", group: "."},
+	"da":    {decimal: ",", group: "."},
+	"ro":    {decimal: ",", group: "."},
+	"fr":    {decimal: ",", group: " "},
+	"fr-ch": {decimal: ",", group: "'"},
+	"pl":    {decimal: ",", group: " "},
+	"cs":    {decimal: ",", group: " "},
+	"sv":    {decimal: ",", group: " "},
+	"nb":    {decimal: ",", group: " "},
+	"fi":    {decimal: ",", group: " "},
+	"ru":    {decimal: ",", group: " "},
+}
+
+// parseLocale returns the number format of a locale such as fr-FR, fr_FR.UTF-8 or fr, or the plain one if empty.
+func parseLocale(locale string) (numberFormat, error) {
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return plainFormat, nil
+	}
+
+	tag, _, _ := strings.Cut(locale, ".")
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	language, region, _ := strings.Cut(tag, "-")
+
+	if format, ok := localeFormats[language+"-"+region]; ok {
+		return format, nil
+	}
+	if format, ok := localeFormats[language]; ok {
+		return format, nil
+	}
+	return numberFormat{}, usageError(fmt.Sprintf("unknown locale %q", locale))
+}
+
+// quantity writes a decimal number in the format.
+func (f numberFormat) quantity(d money.Decimal) string {
+	text := d.String()
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+
+	integer, fraction, hasFraction := strings.Cut(text, ".")
+	if f.group != "" {
+		var grouped strings.Builder
+		for i, digit := range integer {
+			if i > 0 && (len(integer)-i)%3 == 0 {
+				grouped.WriteString(f.group)
+			}
+			grouped.WriteRune(digit)
+		}
+		integer = grouped.String()
+	}
+
+	if !hasFraction {
+		return sign + integer
+	}
+	return sign + integer + f.decimal + fraction
+}
+
+// amount writes an amount in the format, followed by its currency code.
+func (f numberFormat) amount(a money.Amount) string {
+	return f.quantity(a.Quantity()) + " " + a.Currency().ISOCode()
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"moneyconverter/apiclient"
+	"moneyconverter/ecbank"
+	"moneyconverter/expr"
+	"moneyconverter/money"
+	"moneyconverter/ratefile"
+	"moneyconverter/watch"
+	"strings"
+	"time"
+)
+
+// Output formats of the conversions.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputCSV  = "csv"
+	outputTSV  = "tsv"
+	outputKV   = "kv"
+)
+
+// usageError reports a command line that cannot be run as written.
+type usageError string
+
+// Error implements the error interface.
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// conversionRecord is the stable schema of a conversion in the machine-readable outputs.
+// Every field is always written, empty when unknown.
+type conversionRecord struct {
+	SourceAmount   string `json:"source_amount"`
+	SourceCurrency string `json:"source_currency"`
+	TargetAmount   string `json:"target_amount"`
+	TargetCurrency string `json:"target_currency"`
+	Rate           string `json:"rate"`
+	RateDate       string `json:"rate_date"`
+	Provider       string `json:"provider"`
+	// Cache is fresh, or stale when the rates come from an outdated cache.
+	Cache     string `json:"cache"`
+	FetchedAt string `json:"fetched_at"`
+	// Path is the chain of currencies the rate was computed along, such as BTC→USD→EUR→JPY.
+	Path string `json:"path"`
+}
+
+// recordFields are the names of the fields of a conversionRecord, in the order of the columns.
+var recordFields = []string{
+	"source_amount", "source_currency", "target_amount", "target_currency",
+	"rate", "rate_date", "provider", "cache", "fetched_at", "path",
+}
+
+// newConversionRecord returns the record of a conversion.
+func newConversionRecord(c money.Conversion) conversionRecord {
+	source, target := c.Input.Quantity(), c.Output.Quantity()
+
+	record := conversionRecord{
+		SourceAmount:   source.String(),
+		SourceCurrency: c.Input.Currency().ISOCode(),
+		TargetAmount:   target.String(),
+		TargetCurrency: c.Output.Currency().ISOCode(),
+		Rate:           c.Rate.String(),
+		Provider:       c.Provider,
+		Cache:          "fresh",
+		Path:           c.Path.String(),
+	}
+
+	if c.Stale {
+		record.Cache = "stale"
+	}
+	if !c.PublishedAt.IsZero() {
+		record.RateDate = c.PublishedAt.Format(time.DateOnly)
+	}
+	if !c.FetchedAt.IsZero() {
+		record.FetchedAt = c.FetchedAt.Format(time.RFC3339)
+	}
+
+	return record
+}
+
+// values returns the fields of the record, in the order of recordFields.
+func (r conversionRecord) values() []string {
+	return []string{
+		r.SourceAmount, r.SourceCurrency, r.TargetAmount, r.TargetCurrency,
+		r.Rate, r.RateDate, r.Provider, r.Cache, r.FetchedAt, r.Path,
+	}
+}
+
+// validateOutput returns an error if the format isn't one of the supported outputs.
+func validateOutput(format string) error {
+	switch format {
+	case outputText, outputJSON, outputCSV, outputTSV, outputKV:
+		return nil
+	default:
+		return usageError(fmt.Sprintf("unknown output %q, expected text, json, csv, tsv or kv", format))
+	}
+}
+
+// writeConversions writes the conversions in a machine-readable format:
+// one JSON object or key=value line per conversion, or a header and one row per conversion.
+func writeConversions(w io.Writer, format string, conversions []money.Conversion) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		for _, c := range conversions {
+			if err := encoder.Encode(newConversionRecord(c)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case outputCSV, outputTSV:
+		writer := csv.NewWriter(w)
+		if format == outputTSV {
+			writer.Comma = '\t'
+		}
+
+		if err := writer.Write(recordFields); err != nil {
+			return err
+		}
+		for _, c := range conversions {
+			if err := writer.Write(newConversionRecord(c).values()); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case outputKV:
+		for _, c := range conversions {
+			values := newConversionRecord(c).values()
+			pairs := make([]string, len(recordFields))
+			for i, field := range recordFields {
+				pairs[i] = field + "=" + quoteValue(values[i])
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(pairs, " ")); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return usageError(fmt.Sprintf("unknown output %q", format))
+	}
+}
+
+// quoteValue quotes a value of a key=value line when it is empty or holds spaces.
+func quoteValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\"=") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
+
+// errorCode returns a machine-readable code for an error.
+func errorCode(err error) string {
+	var usage usageError
+	var partial partialError
+	var syntaxErr *expr.SyntaxError
+	switch {
+	case errors.As(err, &usage), errors.Is(err, money.ErrInvalidRoundingMode):
+		return "usage"
+	case errors.As(err, &partial):
+		return "partial_failure"
+	case errors.Is(err, expr.ErrInvalidOperation), errors.Is(err, expr.ErrNoAmount), errors.As(err, &syntaxErr):
+		return "invalid_expression"
+	case errors.Is(err, money.ErrInvalidCurrencyCode):
+		return "invalid_currency"
+	case errors.Is(err, money.ErrAmbiguousCurrency):
+		return "ambiguous_currency"
+	case errors.Is(err, money.ErrInvalidDecimal), errors.Is(err, money.ErrTooPrecise), errors.Is(err, money.ErrTooLarge):
+		return "invalid_amount"
+	case errors.Is(err, ecbank.ErrChangeRateNotFound), errors.Is(err, ratefile.ErrChangeRateNotFound),
+		errors.Is(err, money.ErrNoPath), errors.Is(err, money.ErrNoProvider), errors.Is(err, apiclient.ErrRateNotFound):
+		return "rate_not_found"
+	case errors.Is(err, ecbank.ErrTimeout), errors.Is(err, money.ErrProviderTimeout):
+		return "timeout"
+	case errors.Is(err, ecbank.ErrNoCachedRates):
+		return "no_cached_rates"
+	case errors.Is(err, ecbank.ErrCallingServer), errors.Is(err, ecbank.ErrClientSide),
+		errors.Is(err, ecbank.ErrServerSide), errors.Is(err, ecbank.ErrUnknownStatusCode),
+		errors.Is(err, ecbank.ErrUnexpectedFormat), errors.Is(err, apiclient.ErrUnavailable),
+		errors.Is(err, apiclient.ErrUnexpectedResponse):
+		return "provider_unavailable"
+	case errors.Is(err, ratefile.ErrUnknownFormat), errors.Is(err, ratefile.ErrInvalidRecord):
+		return "invalid_rates_file"
+	case errors.Is(err, watch.ErrNotification):
+		return "notification_failed"
+	default:
+		return "internal"
+	}
+}
+
+// jsonError is the structured error written to stderr with the JSON output.
+type jsonError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// writeError writes the error in the output format: a JSON object with a machine-readable code for JSON, a line of text otherwise.
+func writeError(w io.Writer, format string, err error) {
+	if format == outputJSON {
+		var report jsonError
+		report.Error.Code = errorCode(err)
+		report.Error.Message = err.Error()
+		_ = json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "%s.\n", err.Error())
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"moneyconverter/money"
+	"testing"
+	"time"
+)
+
+func TestWriteConversions(t *testing.T) {
+	conversions := []money.Conversion{
+		newTestConversion(t, "10.00", "EUR", "20.00", "USD", "2", "ECB"),
+		newTestConversion(t, "1.50", "USD", "0.75", "EUR", "0.5", `my "bank", inc`),
+	}
+	conversions[0].PublishedAt = time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC)
+	conversions[0].FetchedAt = time.Date(2025, 4, 8, 16, 30, 0, 0, time.UTC)
+	conversions[1].Stale = true
+	if err := conversions[1].Path.UnmarshalText([]byte("USD→EUR")); err != nil {
+		t.Fatalf("unable to parse path: %s", err)
+	}
+
+	tt := map[string]struct {
+		format string
+		want   string
+	}{
+		"json": {
+			format: outputJSON,
+			want: `{"source_amount":"10.00","source_currency":"EUR","target_amount":"20.00","target_currency":"USD","rate":"2",` +
+				`"rate_date":"2025-04-08","provider":"ECB","cache":"fresh","fetched_at":"2025-04-08T16:30:00Z","path":""}` + "\n" +
+				`{"source_amount":"1.50","source_currency":"USD","target_amount":"0.75","target_currency":"EUR","rate":"0.5",` +
+				`"rate_date":"","provider":"my \"bank\", inc","cache":"stale","fetched_at":"","path":"USD→EUR"}` + "\n",
+		},
+		"csv": {
+			format: outputCSV,
+			want: "source_amount,source_currency,target_amount,target_currency,rate,rate_date,provider,cache,fetched_at,path\n" +
+				"10.00,EUR,20.00,USD,2,2025-04-08,ECB,fresh,2025-04-08T16:30:00Z,\n" +
+				`1.50,USD,0.75,EUR,0.5,,"my ""bank"", inc",stale,,USD→EUR` + "\n",
+		},
+		"tsv": {
+			format: outputTSV,
+			want: "source_amount\tsource_currency\ttarget_amount\ttarget_currency\trate\trate_date\tprovider\tcache\tfetched_at\tpath\n" +
+				"10.00\tEUR\t20.00\tUSD\t2\t2025-04-08\tECB\tfresh\t2025-04-08T16:30:00Z\t\n" +
+				"1.50\tUSD\t0.75\tEUR\t0.5\t\t\"my \"\"bank\"\", inc\"\tstale\t\tUSD→EUR\n",
+		},
+		"kv": {
+			format: outputKV,
+			want: `source_amount=10.00 source_currency=EUR target_amount=20.00 target_currency=USD rate=2 rate_date=2025-04-08 ` +
+				`provider=ECB cache=fresh fetched_at=2025-04-08T16:30:00Z path=""` + "\n" +
+				`source_amount=1.50 source_currency=USD target_amount=0.75 target_currency=EUR rate=0.5 rate_date="" ` +
+				`provider="my \"bank\", inc" cache=stale fetched_at="" path=USD→EUR` + "\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := writeConversions(&got, tc.format, conversions); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("got\n%s\nwant\n%s", got.String(), tc.want)
+			}
+		})
+	}
+
+	if err := writeConversions(&bytes.Buffer{}, "xml", conversions); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestQuoteValue(t *testing.T) {
+	tt := map[string]string{
+		"":          `""`,
+		"ECB":       "ECB",
+		"BTC→USD":   "BTC→USD",
+		"my bank":   `"my bank"`,
+		"tab\there": `"tab\there"`,
+		`say "hi"`:  `"say \"hi\""`,
+		"a=b":       `"a=b"`,
+	}
+
+	for value, want := range tt {
+		if got := quoteValue(value); got != want {
+			t.Errorf("quoteValue(%q) got %s, want %s", value, got, want)
+		}
+	}
+}
+
+// newTestConversion returns the conversion of an amount at a rate, from a provider.
+func newTestConversion(t *testing.T, input, from, output, to, rate, provider string) money.Conversion {
+	t.Helper()
+
+	amount := func(value, code string) money.Amount {
+		t.Helper()
+
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			t.Fatalf("unable to parse currency %q: %s", code, err)
+		}
+		quantity, err := money.ParseDecimal(value)
+		if err != nil {
+			t.Fatalf("unable to parse decimal %q: %s", value, err)
+		}
+		a, err := money.NewAmount(quantity, currency)
+		if err != nil {
+			t.Fatalf("unable to build amount %s %s: %s", value, code, err)
+		}
+		return a
+	}
+
+	r, err := money.ParseDecimal(rate)
+	if err != nil {
+		t.Fatalf("unable to parse rate %q: %s", rate, err)
+	}
+
+	return money.Conversion{
+		Input:    amount(input, from),
+		Output:   amount(output, to),
+		Rate:     money.ExchangeRate(r),
+		Provider: provider,
+	}
+}
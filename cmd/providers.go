@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"moneyconverter/money"
+	"moneyconverter/ratefile"
+	"sort"
+	"strings"
+)
+
+// Providers of exchange rates the -providers flag orders.
+const (
+	providerBank     = "bank"
+	providerUpstream = "upstream"
+	providerFile     = "file"
+)
+
+// providerNames names each provider in the reports of the conversions, as its own rates do.
+var providerNames = map[string]string{
+	providerBank:     "ECB",
+	providerUpstream: "upstream",
+	providerFile:     "ratefile",
+}
+
+// providerOrder returns the providers to try, in order: those of the -providers flag if set,
+// otherwise the rates file if given, the upstream server if set, or the bank.
+func (a *app) providerOrder(ratesFile string) ([]string, error) {
+	switch {
+	case a.globals.providers != "":
+	case ratesFile != "":
+		return []string{providerFile}, nil
+	case a.globals.upstream != "":
+		return []string{providerUpstream}, nil
+	default:
+		return []string{providerBank}, nil
+	}
+
+	var order []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(a.globals.providers, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case providerNames[name] == "":
+			return nil, usageError(fmt.Sprintf("unknown provider %q, expected bank, upstream or file", name))
+		case name == providerUpstream && a.globals.upstream == "":
+			return nil, usageError("the upstream provider needs the -upstream flag")
+		case name == providerFile && ratesFile == "":
+			return nil, usageError("the file provider needs the -rates-file flag")
+		case seen[name]:
+			continue
+		}
+
+		seen[name] = true
+		order = append(order, name)
+	}
+
+	return order, nil
+}
+
+// graphProvider names the last provider tried when several are loaded: a graph chaining the rates of all of them,
+// such as the price of a crypto-currency in USD of a rates file with the rates of the bank.
+const graphProvider = "graph"
+
+// loadRates returns the rates of the providers, tried in the order of providerOrder: a conversion falls back
+// to the next provider when one fails or doesn't know the currencies, and reports which one answered.
+// It also returns a notice to append to the output, should the rates of the bank or the upstream server be outdated.
+func (a *app) loadRates(ratesFile string) (money.RatesFetcher, string, error) {
+	order, err := a.providerOrder(ratesFile)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		chain  providerChain
+		links  []money.Provider
+		graph  money.RateGraph
+		notice string
+		errs   []error
+	)
+	for _, name := range order {
+		rates, providerNotice, err := a.loadProvider(name, ratesFile)
+		if err != nil {
+			// the provider is kept in the chain so that its failure is reported with the conversions.
+			links = append(links, money.Provider{Name: providerNames[name], Rates: failedRates{err: err}})
+			errs = append(errs, err)
+			continue
+		}
+
+		links = append(links, money.Provider{Name: providerNames[name], Rates: rates, Timeout: a.globals.timeout})
+		chain.listers = append(chain.listers, rates)
+		if err := rates.AddTo(&graph, providerNames[name]); err != nil {
+			return nil, "", err
+		}
+		if notice == "" {
+			notice = providerNotice
+		}
+	}
+
+	if len(chain.listers) == 0 {
+		return nil, "", errors.Join(errs...)
+	}
+
+	if len(chain.listers) > 1 {
+		links = append(links, money.Provider{Name: graphProvider, Rates: &graph})
+	}
+
+	chain.Chain = money.NewChain(links...)
+	return chain, notice, nil
+}
+
+// ratesProvider is the rates of a provider, which know their currencies and can be chained with those of others.
+type ratesProvider interface {
+	money.RatesFetcher
+	currencyLister
+	AddTo(graph *money.RateGraph, provider string) error
+}
+
+// loadProvider reads the rates file or fetches the rates of the day from the bank or the upstream server.
+func (a *app) loadProvider(name, ratesFile string) (ratesProvider, string, error) {
+	switch name {
+	case providerFile:
+		provider, err := ratefile.Load(ratesFile)
+		if err != nil {
+			return nil, "", err
+		}
+		return provider, "", nil
+
+	case providerUpstream:
+		table, err := a.newUpstream().Rates(context.Background())
+		if err != nil {
+			return nil, "", err
+		}
+		return table, staleNotice(table), nil
+
+	default:
+		table, err := a.newClient().Rates(context.Background())
+		if err != nil {
+			return nil, "", err
+		}
+		return table, staleNotice(table), nil
+	}
+}
+
+// providerChain tries the providers in order, and knows the currencies of every provider that could be loaded.
+type providerChain struct {
+	money.Chain
+	listers []currencyLister
+}
+
+// SupportedCurrencies returns the currencies known to any of the providers, sorted by code.
+func (c providerChain) SupportedCurrencies() []money.Currency {
+	seen := make(map[money.Currency]bool)
+	var currencies []money.Currency
+	for _, lister := range c.listers {
+		for _, currency := range lister.SupportedCurrencies() {
+			if !seen[currency] {
+				seen[currency] = true
+				currencies = append(currencies, currency)
+			}
+		}
+	}
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].ISOCode() < currencies[j].ISOCode()
+	})
+
+	return currencies
+}
+
+// failedRates stands for a provider whose rates couldn't be loaded.
+type failedRates struct {
+	err error
+}
+
+// FetchExchangeRate implements the interface money.RatesFetcher, returning the error of the loading.
+func (f failedRates) FetchExchangeRate(_, _ money.Currency) (money.ExchangeRate, error) {
+	return money.ExchangeRate{}, f.err
+}
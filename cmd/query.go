@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"moneyconverter/expr"
+	"moneyconverter/money"
+	"strings"
+	"unicode"
+)
+
+// query is a conversion written in free form, such as "100 usd to gbp", "100USD in EUR", "$100 → €" or "1.5k JPY to EUR".
+// Its parts are kept as text, so that missing ones can be taken from the flags.
+type query struct {
+	// quantity is the amount to convert, without thousands separators nor magnitude suffix.
+	quantity string
+	// from is the ISO code of the source currency, empty if not written.
+	from string
+	// to is the comma-separated list of the ISO codes of the target currencies, or all, empty if not written.
+	to string
+}
+
+// parseQuery parses a conversion written in free form. Symbols shared by several currencies, such as $,
+// resolve to the first preferred currency they may stand for.
+func parseQuery(input string, preferred []money.Currency) (query, error) {
+	source, target := splitQuery(input)
+
+	var q query
+	var err error
+	q.quantity, q.from, err = parseQuerySource(source, preferred)
+	if err != nil {
+		return query{}, err
+	}
+
+	q.to, err = parseQueryTargets(target, preferred)
+	if err != nil {
+		return query{}, err
+	}
+
+	return q, nil
+}
+
+// splitQuery splits a query at its first keyword, into the amount and the target currencies.
+func splitQuery(input string) (string, string) {
+	// arrows may be glued to the amount, as in $100→€.
+	for _, arrow := range []string{"→", "->", "=>"} {
+		input = strings.ReplaceAll(input, arrow, " "+arrow+" ")
+	}
+
+	fields := strings.Fields(input)
+	for i, field := range fields {
+		if expr.IsTargetKeyword(field) {
+			return strings.Join(fields[:i], " "), strings.Join(fields[i+1:], " ")
+		}
+	}
+
+	return strings.Join(fields, " "), ""
+}
+
+// parseQuerySource parses the amount of a query, such as "$100", "100 usd", "-12.50EUR" or "1.5k JPY",
+// and returns its quantity and the ISO code of its currency, empty if not written.
+func parseQuerySource(source string, preferred []money.Currency) (string, string, error) {
+	start := strings.IndexFunc(source, unicode.IsDigit)
+	if start < 0 {
+		return "", "", usageError(fmt.Sprintf("missing amount to convert in %q", source))
+	}
+
+	end := start + strings.IndexFunc(source[start:], func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != ',' && r != '_'
+	})
+	if end < start {
+		end = len(source)
+	}
+
+	prefix := strings.TrimSpace(source[:start])
+	negative := strings.HasPrefix(prefix, "-")
+	prefix = strings.TrimSpace(strings.TrimPrefix(prefix, "-"))
+
+	quantity := strings.NewReplacer(",", "", "_", "").Replace(source[start:end])
+	suffix := strings.Fields(source[end:])
+
+	zeros := 0
+	var symbol string
+	switch {
+	case len(suffix) > 2:
+		return "", "", usageError(fmt.Sprintf("unexpected %q after the amount", strings.Join(suffix[1:], " ")))
+	case len(suffix) == 2:
+		var ok bool
+		if zeros, ok = expr.Magnitude(suffix[0]); !ok {
+			return "", "", usageError(fmt.Sprintf("unexpected %q after the amount", suffix[0]))
+		}
+		symbol = suffix[1]
+	case len(suffix) == 1:
+		symbol = suffix[0]
+		if z, ok := expr.Magnitude(symbol); ok {
+			zeros, symbol = z, ""
+		} else if _, err := money.ParseCurrencySymbol(symbol, preferred...); err != nil {
+			// the magnitude may be glued to the currency, as in 1.5kJPY.
+			if z, rest, ok := expr.SplitMagnitude(symbol); ok {
+				zeros, symbol = z, rest
+			}
+		}
+	}
+
+	if prefix != "" && symbol != "" {
+		return "", "", usageError(fmt.Sprintf("both %q and %q are written as the currency of the amount", prefix, symbol))
+	}
+	if prefix != "" {
+		symbol = prefix
+	}
+
+	quantity = shiftDecimal(quantity, zeros)
+	if negative {
+		quantity = "-" + quantity
+	}
+
+	if symbol == "" {
+		return quantity, "", nil
+	}
+
+	currency, err := money.ParseCurrencySymbol(symbol, preferred...)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to parse currency %q: %w", symbol, err)
+	}
+
+	return quantity, currency.ISOCode(), nil
+}
+
+// shiftDecimal multiplies the decimal number written in value by 10^zeros, moving its separator to the right.
+func shiftDecimal(value string, zeros int) string {
+	if zeros == 0 {
+		return value
+	}
+
+	integer, fraction, _ := strings.Cut(value, ".")
+	if len(fraction) < zeros {
+		fraction += strings.Repeat("0", zeros-len(fraction))
+	}
+
+	if len(fraction) == zeros {
+		return integer + fraction
+	}
+	return integer + fraction[:zeros] + "." + fraction[zeros:]
+}
+
+// parseQueryTargets parses the target currencies of a query, such as "gbp", "€" or "EUR, USD",
+// and returns their comma-separated ISO codes, all, or an empty string if not written.
+func parseQueryTargets(target string, preferred []money.Currency) (string, error) {
+	if strings.TrimSpace(target) == "" {
+		return "", nil
+	}
+
+	if strings.EqualFold(strings.TrimSpace(target), allTargets) {
+		return allTargets, nil
+	}
+
+	symbols := strings.FieldsFunc(target, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	codes := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		currency, err := money.ParseCurrencySymbol(symbol, preferred...)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse currency %q: %w", symbol, err)
+		}
+		codes = append(codes, currency.ISOCode())
+	}
+
+	return strings.Join(codes, ","), nil
+}
+
+// preferredCurrencies returns the currencies of the -prefer flag, picked for symbols shared by several currencies.
+func (a *app) preferredCurrencies() ([]money.Currency, error) {
+	if a.globals.prefer == "" {
+		return nil, nil
+	}
+
+	codes := strings.Split(a.globals.prefer, ",")
+	preferred := make([]money.Currency, 0, len(codes))
+	for _, code := range codes {
+		currency, err := money.ParseCurrency(strings.TrimSpace(code))
+		if err != nil {
+			return nil, usageError(fmt.Sprintf("unable to parse preferred currency %q: %s", code, err))
+		}
+		preferred = append(preferred, currency)
+	}
+
+	return preferred, nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"moneyconverter/money"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	cad, err := money.ParseCurrency("CAD")
+	if err != nil {
+		t.Fatalf("unable to parse CAD: %s", err)
+	}
+
+	tt := map[string]struct {
+		input     string
+		preferred []money.Currency
+		expected  query
+	}{
+		"codes and keyword":      {input: "100 usd to gbp", expected: query{quantity: "100", from: "USD", to: "GBP"}},
+		"glued code":             {input: "100USD in EUR", expected: query{quantity: "100", from: "USD", to: "EUR"}},
+		"preferred symbols":      {input: "$100 → €", preferred: []money.Currency{cad}, expected: query{quantity: "100", from: "CAD", to: "EUR"}},
+		"glued arrow":            {input: "£100->€", expected: query{quantity: "100", from: "GBP", to: "EUR"}},
+		"magnitude":              {input: "1.5k JPY to EUR", expected: query{quantity: "1500", from: "JPY", to: "EUR"}},
+		"glued magnitude":        {input: "2.25mGBP", expected: query{quantity: "2250000", from: "GBP"}},
+		"code starting like one": {input: "3 MXN", expected: query{quantity: "3", from: "MXN"}},
+		"magnitude alone":        {input: "1.23456k", expected: query{quantity: "1234.56"}},
+		"thousands separators":   {input: "12,500.50 chf", expected: query{quantity: "12500.50", from: "CHF"}},
+		"negative amount":        {input: "-$5 in zł", preferred: []money.Currency{cad}, expected: query{quantity: "-5", from: "CAD", to: "PLN"}},
+		"code before the amount": {input: "usd 100 into eur", expected: query{quantity: "100", from: "USD", to: "EUR"}},
+		"several targets":        {input: "100 eur to usd, gbp", expected: query{quantity: "100", from: "EUR", to: "USD,GBP"}},
+		"all targets":            {input: "100 eur in ALL", expected: query{quantity: "100", from: "EUR", to: allTargets}},
+		"amount only":            {input: "100", expected: query{quantity: "100"}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseQuery(tc.input, tc.preferred)
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tt := map[string]struct {
+		input string
+		err   error
+	}{
+		"ambiguous symbol": {input: "$100 to eur", err: money.ErrAmbiguousCurrency},
+		"ambiguous target": {input: "100 eur to kr", err: money.ErrAmbiguousCurrency},
+		"unknown currency": {input: "100 euros", err: money.ErrInvalidCurrencyCode},
+		"no amount":        {input: "usd to eur", err: usageError("")},
+		"two currencies":   {input: "€100 usd", err: usageError("")},
+		"trailing words":   {input: "100 k usd please", err: usageError("")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseQuery(tc.input, nil)
+
+			var usage usageError
+			if _, isUsage := tc.err.(usageError); isUsage {
+				if !errors.As(err, &usage) {
+					t.Errorf("expected a usage error, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
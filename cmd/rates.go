@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"moneyconverter/money"
+	"time"
+)
+
+// runRates prints the table of the exchange rates published for the day against a chosen base currency.
+func (a *app) runRates(args []string) error {
+	flags := a.newFlagSet("rates", "", "Prints the exchange rates published by the bank for the day.")
+	base := flags.String("base", "EUR", "base currency of the table")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	baseCurrency, err := money.ParseCurrency(*base)
+	if err != nil {
+		return usageError(fmt.Sprintf("unable to parse base currency %q: %s", *base, err))
+	}
+
+	table, err := a.newRateSource().Rates(context.Background())
+	if err != nil {
+		return fmt.Errorf("unable to fetch exchange rates: %w", err)
+	}
+
+	table, err = table.Rebase(baseCurrency)
+	if err != nil {
+		return fmt.Errorf("unable to quote exchange rates against %s: %w", baseCurrency, err)
+	}
+
+	_, _ = fmt.Fprintf(a.stdout, "1 %s on %s%s\n", table.Base, table.Date.Format(time.DateOnly), staleNotice(table))
+	for _, currency := range table.SupportedCurrencies() {
+		_, _ = fmt.Fprintf(a.stdout, "%s %s\n", currency, table.Rates[currency])
+	}
+
+	return nil
+}
+
+// runCurrencies lists the currencies exchange rates are known for, one per line.
+func (a *app) runCurrencies(args []string) error {
+	flags := a.newFlagSet("currencies", "", "Lists the currencies exchange rates are known for, one per line.")
+	ratesFile := flags.String("rates-file", "", "list the currencies of a local .csv, .tsv, .json or ECB .xml file instead of the bank")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	rates, _, err := a.loadRates(*ratesFile)
+	if err != nil {
+		return fmt.Errorf("unable to fetch exchange rates: %w", err)
+	}
+
+	lister, ok := rates.(currencyLister)
+	if !ok {
+		return fmt.Errorf("the rates cannot list their currencies")
+	}
+
+	for _, currency := range lister.SupportedCurrencies() {
+		_, _ = fmt.Fprintln(a.stdout, currency.ISOCode())
+	}
+
+	return nil
+}
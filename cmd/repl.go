@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"moneyconverter/ecbank"
+	"moneyconverter/expr"
+	"moneyconverter/money"
+	"moneyconverter/ratefile"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// replHistoryFilename is the file of the commands typed in the REPL, in the cache directory.
+	replHistoryFilename = "mc_repl_history.txt"
+	// replHistoryLimit is how many commands the history keeps.
+	replHistoryLimit = 1000
+)
+
+// replHelp describes the commands of the REPL.
+const replHelp = `Commands:
+  <expression>            convert or compute, such as 100 usd to gbp or a + 20 EUR, in the base currency unless written
+  <name> = <expression>   store an amount in a variable, such as a = 100 USD
+  vars                    list the variables
+  rates                   print the exchange rates against the base currency
+  history                 list the previous commands
+  history <currency>      print the recorded rates of a currency against the base currency
+  set base <currency>     change the base currency
+  set date <YYYY-MM-DD>   use the rates published on a day, or latest for the rates of the day
+  set rounding <mode>     round results down, half-up, half-even or up
+  help                    print this help
+  quit                    leave, as does end of input
+`
+
+// errQuit is returned by a command of the REPL to leave it.
+var errQuit = errors.New("quit")
+
+// repl is an interactive session keeping the rates loaded between commands.
+type repl struct {
+	a      *app
+	client ecbank.Client
+	// rates are those of the chosen date, table holding them unless they come from a rates file.
+	rates     money.RatesFetcher
+	table     *ecbank.RateTable
+	base      money.Currency
+	rounding  money.RoundingMode
+	preferred []money.Currency
+	variables map[string]money.Amount
+	// history holds the previous commands, oldest first, and is appended to historyFile.
+	history     []string
+	historyFile string
+}
+
+// runRepl reads commands from stdin until its end or quit.
+func (a *app) runRepl(args []string) error {
+	flags := a.newFlagSet("repl", "", "Starts an interactive session converting amounts with rates loaded once.\n"+
+		"The commands are kept in the cache directory, across sessions.\n\n"+replHelp)
+	base := flags.String("base", "EUR", "base currency, the target of conversions that don't write one")
+	ratesFile := flags.String("rates-file", "", "read rates from a local .csv, .tsv, .json or ECB .xml file instead of the bank")
+	rounding := flags.String("rounding", "down", "rounding of the results: down, half-up, half-even or up")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	r := &repl{
+		a:           a,
+		client:      a.newClient(),
+		variables:   make(map[string]money.Amount),
+		historyFile: filepath.Join(a.globals.cacheDir, replHistoryFilename),
+	}
+
+	var err error
+	if r.preferred, err = a.preferredCurrencies(); err != nil {
+		return err
+	}
+	if r.base, err = money.ParseCurrencySymbol(*base, r.preferred...); err != nil {
+		return usageError(fmt.Sprintf("unable to parse base currency %q: %s", *base, err))
+	}
+	if r.rounding, err = money.ParseRoundingMode(*rounding); err != nil {
+		return usageError(fmt.Sprintf("unable to parse rounding %q: %s", *rounding, err))
+	}
+
+	if *ratesFile != "" {
+		if r.rates, err = ratefile.Load(*ratesFile); err != nil {
+			return fmt.Errorf("unable to fetch exchange rates: %w", err)
+		}
+	} else if err := r.setDate("latest"); err != nil {
+		return err
+	}
+
+	if err := r.loadHistory(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(a.stdin)
+	for {
+		_, _ = fmt.Fprint(a.stdout, "> ")
+		if !scanner.Scan() {
+			_, _ = fmt.Fprintln(a.stdout)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		err := r.execute(line)
+		switch {
+		case errors.Is(err, errQuit):
+			return nil
+		case err != nil:
+			writeError(a.stderr, outputText, err)
+		default:
+			// only the commands that ran are recorded, so that typos and quit don't clutter the history.
+			if err := r.record(line); err != nil {
+				writeError(a.stderr, outputText, err)
+			}
+		}
+	}
+}
+
+// execute runs one command of the REPL.
+func (r *repl) execute(line string) error {
+	fields := strings.Fields(line)
+
+	switch {
+	case line == "quit" || line == "exit":
+		return errQuit
+	case line == "help":
+		_, _ = io.WriteString(r.a.stdout, replHelp)
+		return nil
+	case line == "vars":
+		r.printVariables()
+		return nil
+	case line == "rates":
+		return r.printRates()
+	case fields[0] == "history" && len(fields) <= 2:
+		if len(fields) == 1 {
+			r.printHistory()
+			return nil
+		}
+		return r.printSeries(fields[1])
+	case fields[0] == "set":
+		if len(fields) != 3 {
+			return usageError("expected set base, date or rounding followed by a value")
+		}
+		return r.set(fields[1], fields[2])
+	}
+
+	if name, expression, ok := splitAssignment(line); ok {
+		return r.assign(name, expression)
+	}
+
+	result, err := r.evaluate(line, expr.WithDefaultTarget(r.base))
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(r.a.stdout, result)
+	return nil
+}
+
+// evaluate computes an expression with the variables and the current rates.
+func (r *repl) evaluate(input string, opts ...expr.Option) (money.Amount, error) {
+	opts = append(opts, expr.WithPreferred(r.preferred...), expr.WithVariables(r.variables))
+
+	expression, err := expr.Parse(input, opts...)
+	if err != nil {
+		var syntaxErr *expr.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			printColumn(r.a.stderr, input, syntaxErr.Column)
+		}
+		return money.Amount{}, err
+	}
+
+	return expression.Evaluate(r.rates, r.rounding)
+}
+
+// splitAssignment splits an assignment such as a = 100 USD into the name of the variable and the expression.
+func splitAssignment(line string) (string, string, bool) {
+	for i := strings.IndexByte(line, '='); i >= 0; {
+		if i+1 == len(line) || line[i+1] != '>' {
+			return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+		}
+
+		next := strings.IndexByte(line[i+1:], '=')
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+
+	return "", "", false
+}
+
+// assign stores the result of an expression, in the currency of its first amount unless written, in a variable.
+func (r *repl) assign(name, expression string) error {
+	if !expr.IsVariableName(name) {
+		return usageError(fmt.Sprintf("invalid variable name %q: use letters and underscores, other than a currency or a keyword", name))
+	}
+
+	result, err := r.evaluate(expression)
+	if err != nil {
+		return err
+	}
+
+	r.variables[name] = result
+	_, _ = fmt.Fprintf(r.a.stdout, "%s = %s\n", name, result)
+	return nil
+}
+
+// set changes a setting of the session: base, date or rounding.
+func (r *repl) set(setting, value string) error {
+	switch setting {
+	case "base":
+		base, err := money.ParseCurrencySymbol(value, r.preferred...)
+		if err != nil {
+			return usageError(fmt.Sprintf("unable to parse base currency %q: %s", value, err))
+		}
+		r.base = base
+		_, _ = fmt.Fprintf(r.a.stdout, "Converting to %s.\n", base)
+
+	case "date":
+		if r.table == nil {
+			return usageError("dates are only known for the rates of the bank, not those of a rates file")
+		}
+		if err := r.setDate(value); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(r.a.stdout, "Using the rates published on %s.\n", r.table.Date.Format(time.DateOnly))
+
+	case "rounding":
+		rounding, err := money.ParseRoundingMode(value)
+		if err != nil {
+			return usageError(fmt.Sprintf("unable to parse rounding %q: %s", value, err))
+		}
+		r.rounding = rounding
+		_, _ = fmt.Fprintf(r.a.stdout, "Rounding %s.\n", rounding)
+
+	default:
+		return usageError(fmt.Sprintf("unknown setting %q, expected base, date or rounding", setting))
+	}
+
+	return nil
+}
+
+// setDate loads the rates of the bank published on a day, or those of the day for latest.
+func (r *repl) setDate(value string) error {
+	var table ecbank.RateTable
+	var err error
+
+	if value == "latest" {
+		table, err = r.a.newRateSource().Rates(context.Background())
+		if err != nil {
+			return fmt.Errorf("unable to fetch exchange rates: %w", err)
+		}
+	} else {
+		day, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return usageError(fmt.Sprintf("invalid date %q, expected YYYY-MM-DD or latest", value))
+		}
+
+		table, err = r.client.History().RatesOn(day)
+		if err != nil {
+			return fmt.Errorf("unable to find the rates of %s, run history import to record them: %w", value, err)
+		}
+	}
+
+	r.table, r.rates = &table, table
+	return nil
+}
+
+// printVariables writes the variables, sorted by name.
+func (r *repl) printVariables() {
+	names := make([]string, 0, len(r.variables))
+	for name := range r.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, _ = fmt.Fprintf(r.a.stdout, "%s = %s\n", name, r.variables[name])
+	}
+}
+
+// printRates writes the rate from the base currency to every other known currency.
+func (r *repl) printRates() error {
+	lister, ok := r.rates.(currencyLister)
+	if !ok {
+		return fmt.Errorf("the rates cannot list their currencies")
+	}
+
+	if r.table != nil {
+		_, _ = fmt.Fprintf(r.a.stdout, "1 %s on %s%s\n", r.base, r.table.Date.Format(time.DateOnly), staleNotice(*r.table))
+	}
+
+	for _, currency := range lister.SupportedCurrencies() {
+		if currency == r.base {
+			continue
+		}
+
+		rate, err := r.rates.FetchExchangeRate(r.base, currency)
+		if err != nil {
+			return fmt.Errorf("unable to quote %s against %s: %w", currency, r.base, err)
+		}
+		_, _ = fmt.Fprintf(r.a.stdout, "%s %s\n", currency, rate)
+	}
+
+	return nil
+}
+
+// printSeries writes the recorded rates of a currency against the base currency, oldest first.
+func (r *repl) printSeries(code string) error {
+	currency, err := money.ParseCurrencySymbol(code, r.preferred...)
+	if err != nil {
+		return fmt.Errorf("unable to parse currency %q: %w", code, err)
+	}
+
+	series, err := r.client.History().Series(r.base, currency, time.Time{}, time.Now())
+	if err != nil {
+		return fmt.Errorf("unable to read history: %w", err)
+	}
+
+	for _, point := range series.Points() {
+		_, _ = fmt.Fprintf(r.a.stdout, "%s %s\n", point.Date.Format(time.DateOnly), point.Rate)
+	}
+
+	return nil
+}
+
+// printHistory writes the previous commands, numbered, oldest first.
+func (r *repl) printHistory() {
+	for i, line := range r.history {
+		_, _ = fmt.Fprintf(r.a.stdout, "%5d  %s\n", i+1, line)
+	}
+}
+
+// loadHistory reads the commands of the previous sessions, keeping the last replHistoryLimit ones.
+func (r *repl) loadHistory() error {
+	data, err := os.ReadFile(r.historyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the command history: %w", err)
+	}
+
+	r.history = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(r.history) <= replHistoryLimit {
+		return nil
+	}
+
+	r.history = r.history[len(r.history)-replHistoryLimit:]
+	if err := os.WriteFile(r.historyFile, []byte(strings.Join(r.history, "\n")+"\n"), 0o600); err != nil {
+		return fmt.Errorf("unable to trim the command history: %w", err)
+	}
+
+	return nil
+}
+
+// record appends a command to the history, in memory and on disk.
+func (r *repl) record(line string) error {
+	r.history = append(r.history, line)
+
+	f, err := os.OpenFile(r.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to save the command history: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		return fmt.Errorf("unable to save the command history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"moneyconverter/ecbank"
+	"moneyconverter/server"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout is how long the server waits for the requests in flight when stopped.
+const shutdownTimeout = 10 * time.Second
+
+// runServe answers the HTTP API until interrupted.
+func (a *app) runServe(args []string) error {
+	flags := a.newFlagSet("serve", "",
+		"Serves the HTTP JSON API until interrupted:\n"+
+			"  GET /v1/convert?amount=12.50&from=USD&to=EUR[&date=YYYY-MM-DD]\n"+
+			"  GET /v1/rates[?base=USD][&date=YYYY-MM-DD]\n"+
+			"  GET /v1/currencies\n"+
+			"  GET /healthz\n"+
+			"  GET /metrics")
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	refresh := flags.Duration("refresh", time.Hour, "how long the rates of the day are served from memory before being fetched again")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", *addr, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	metrics := server.NewMetrics()
+	return a.serve(ctx, listener, server.New(a.newRateSource(ecbank.WithObserver(metrics)),
+		server.WithRefreshInterval(*refresh),
+		server.WithLogger(a.newLogger()),
+		server.WithMetrics(metrics),
+	))
+}
+
+// serve answers the requests of the listener with the handler until the context is done.
+func (a *app) serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	httpServer := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.Serve(listener)
+	}()
+	_, _ = fmt.Fprintf(a.stderr, "Listening on http://%s\n", listener.Addr())
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("unable to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("unable to stop the server: %w", err)
+	}
+
+	return nil
+}
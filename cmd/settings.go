@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// envPrefix starts the names of the environment variables overriding the configuration.
+const envPrefix = "MONEYCONVERTER_"
+
+// setting is a default of the command line that the environment or the configuration file can change.
+type setting struct {
+	// key names the setting in the configuration file, sections being separated by dots, such as cache.dir.
+	key string
+	// flag is the flag the setting gives the default of.
+	flag string
+	// command restricts the setting to the flag of one command, the flag of every command if empty.
+	command string
+	// def is the built-in default.
+	def string
+}
+
+// settings lists the settings, in the order config show prints them.
+var settings = []setting{
+	{key: "from", flag: "from", def: ""},
+	// convert accepts a list of target currencies, convert-file a single one.
+	{key: "to", flag: "to", command: "convert", def: "EUR"},
+	{key: "convert_file.to", flag: "to", command: "convert-file", def: "EUR"},
+	{key: "timeout", flag: "timeout", def: defaultTimeout.String()},
+	{key: "locale", flag: "locale", def: ""},
+	{key: "rounding", flag: "rounding", def: "down"},
+	{key: "prefer", flag: "prefer", def: ""},
+	{key: "providers.order", flag: "providers", def: ""},
+	{key: "providers.upstream", flag: "upstream", def: ""},
+	{key: "providers.rates_file", flag: "rates-file", def: ""},
+	{key: "cache.dir", flag: "cache-dir", def: ""},
+	{key: "cache.max_stale", flag: "max-stale", def: "0s"},
+	{key: "cache.offline", flag: "offline", def: "false"},
+}
+
+// env returns the name of the environment variable of the setting, such as MONEYCONVERTER_CACHE_DIR.
+func (s setting) env() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+// findSetting returns the setting of the given key.
+func findSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// Sources of the value of a setting.
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceConfig  = "config"
+	sourceDefault = "default"
+)
+
+// resolved is the value of a setting along with where it comes from.
+type resolved struct {
+	value  string
+	source string
+	// origin details the source: the flag, the environment variable or the configuration file.
+	origin string
+}
+
+// configFile holds the settings read from a configuration file.
+type configFile struct {
+	// path is the file read, empty if there is none.
+	path   string
+	values map[string]string
+}
+
+// resolve returns the value of a setting: that of its flag if given on the command line,
+// otherwise that of its environment variable, of the configuration file, or the built-in default.
+func (a *app) resolve(s setting, flags *flag.FlagSet, config configFile) resolved {
+	if a.explicit[s.flag] {
+		if f := flags.Lookup(s.flag); f != nil {
+			return resolved{value: f.Value.String(), source: sourceFlag, origin: "-" + s.flag}
+		}
+	}
+	if value, ok := os.LookupEnv(s.env()); ok {
+		return resolved{value: value, source: sourceEnv, origin: s.env()}
+	}
+	if value, ok := config.values[s.key]; ok {
+		return resolved{value: value, source: sourceConfig, origin: config.path}
+	}
+	return resolved{value: s.def, source: sourceDefault}
+}
+
+// applySettings gives the flags of the command left out on the command line
+// the values of the environment or of the configuration file.
+func (a *app) applySettings(flags *flag.FlagSet) error {
+	flags.Visit(func(f *flag.Flag) {
+		a.explicit[f.Name] = true
+	})
+
+	config, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range settings {
+		if flags.Lookup(s.flag) == nil || (s.command != "" && s.command != flags.Name()) {
+			continue
+		}
+
+		r := a.resolve(s, flags, config)
+		if r.source != sourceEnv && r.source != sourceConfig {
+			continue
+		}
+		if err := flags.Set(s.flag, r.value); err != nil {
+			return usageError(fmt.Sprintf("invalid %s %q from %s: %s", s.key, r.value, r.origin, err))
+		}
+	}
+
+	return nil
+}
+
+// configPath returns the configuration file to read: that of the -config flag, of MONEYCONVERTER_CONFIG,
+// or config.toml or config.json in $XDG_CONFIG_HOME/moneyconverter. It tells whether the file was asked for explicitly.
+func (a *app) configPath() (string, bool) {
+	if a.globals.config != "" {
+		return a.globals.config, true
+	}
+	if path := os.Getenv(envPrefix + "CONFIG"); path != "" {
+		return path, true
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	for _, name := range []string{"config.toml", "config.json"} {
+		path := filepath.Join(dir, "moneyconverter", name)
+		if _, err := os.Stat(path); err == nil {
+			return path, false
+		}
+	}
+	return "", false
+}
+
+// loadConfig reads the configuration file, if any. A missing file is an error only when asked for explicitly.
+func (a *app) loadConfig() (configFile, error) {
+	path, explicit := a.configPath()
+	if path == "" {
+		return configFile{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+		return configFile{}, nil
+	case err != nil:
+		return configFile{}, fmt.Errorf("unable to read configuration: %w", err)
+	}
+
+	var values map[string]string
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		values, err = parseTOML(data)
+	} else {
+		values, err = parseJSONConfig(data)
+	}
+	if err != nil {
+		return configFile{}, usageError(fmt.Sprintf("invalid configuration %s: %s", path, err))
+	}
+
+	for key := range values {
+		if _, ok := findSetting(key); !ok {
+			return configFile{}, usageError(fmt.Sprintf("invalid configuration %s: unknown setting %q", path, key))
+		}
+	}
+
+	return configFile{path: path, values: values}, nil
+}
+
+// parseJSONConfig reads the settings of a JSON object, the keys of nested objects being joined with dots.
+func parseJSONConfig(data []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	return values, flattenJSON("", document, values)
+}
+
+// flattenJSON records the values of an object under their dotted keys.
+func flattenJSON(prefix string, object map[string]any, values map[string]string) error {
+	for name, value := range object {
+		key := prefix + name
+		if nested, ok := value.(map[string]any); ok {
+			if err := flattenJSON(key+".", nested, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		text, err := jsonScalar(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = text
+	}
+	return nil
+}
+
+// jsonScalar writes a JSON value as a flag would be given it, lists being comma-separated.
+func jsonScalar(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			if _, isList := item.([]any); isList {
+				return "", errors.New("nested lists are not supported")
+			}
+			text, err := jsonScalar(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = text
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// parseTOML reads the subset of TOML the configuration needs: tables, and keys holding strings, numbers, booleans
+// or lists of them, which are joined with commas.
+func parseTOML(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	prefix := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(stripTOMLComment(scanner.Text()))
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			table := strings.TrimSpace(text[1 : len(text)-1])
+			if table == "" || strings.HasPrefix(table, "[") {
+				return nil, fmt.Errorf("line %d: unsupported table %s", line, text)
+			}
+			prefix = table + "."
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+
+		parsed, err := tomlValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		values[prefix+strings.Trim(strings.TrimSpace(key), `"`)] = parsed
+	}
+
+	return values, scanner.Err()
+}
+
+// stripTOMLComment removes a comment from a line, leaving the # written in strings.
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// tomlValue reads a TOML value as a flag would be given it.
+func tomlValue(value string) (string, error) {
+	switch {
+	case value == "":
+		return "", errors.New("missing value")
+	case strings.HasPrefix(value, `"`):
+		return strconv.Unquote(value)
+	case strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) > 1:
+		return value[1 : len(value)-1], nil
+	case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+		var items []string
+		for _, item := range strings.Split(value[1:len(value)-1], ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			parsed, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, parsed)
+		}
+		return strings.Join(items, ","), nil
+	case value == "true", value == "false":
+		return value, nil
+	default:
+		if _, err := strconv.ParseFloat(strings.ReplaceAll(value, "_", ""), 64); err != nil {
+			return "", fmt.Errorf("unsupported value %s", value)
+		}
+		return strings.ReplaceAll(value, "_", ""), nil
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"errors"
+	"maps"
+	"moneyconverter/money"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	want := map[string]string{
+		"to":                 "USD,GBP",
+		"timeout":            "10s",
+		"rounding":           "half-even",
+		"providers.upstream": "http://localhost:8080/#api",
+		"cache.dir":          `C:\cache`,
+		"cache.offline":      "true",
+		"cache.max_stale":    "72h",
+	}
+
+	toml := `# defaults of the money converter
+to = ["USD", "GBP"]
+timeout = "10s"
+rounding = 'half-even'   # a comment
+
+[providers]
+upstream = "http://localhost:8080/#api"
+
+[cache]
+dir = 'C:\cache'
+offline = true
+max_stale = "72h"
+`
+	got, err := parseTOML([]byte(toml))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("parseTOML got %v, want %v", got, want)
+	}
+
+	json := `{
+	"to": ["USD", "GBP"],
+	"timeout": "10s",
+	"rounding": "half-even",
+	"providers": {"upstream": "http://localhost:8080/#api"},
+	"cache": {"dir": "C:\\cache", "offline": true, "max_stale": "72h"}
+}`
+	got, err = parseJSONConfig([]byte(json))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("parseJSONConfig got %v, want %v", got, want)
+	}
+
+	for _, invalid := range []string{"to", "to = ", "to = USD", "[[providers]]", `to = "USD`} {
+		if _, err := parseTOML([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+	for _, invalid := range []string{"[]", `{"to": null}`, `{"to": [["USD"]]}`} {
+		if _, err := parseJSONConfig([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	quantity, err := money.ParseDecimal("-1234567.89")
+	if err != nil {
+		t.Fatalf("unable to parse quantity: %s", err)
+	}
+
+	tt := map[string]struct {
+		locale   string
+		expected string
+		err      error
+	}{
+		"plain":                 {locale: "", expected: "-1234567.89"},
+		"english":               {locale: "en-US", expected: "-1,234,567.89"},
+		"german":                {locale: "de_DE.UTF-8", expected: "-1.234.567,89"},
+		"swiss german":          {locale: "de-CH", expected: "-1'234'567.89"},
+		"french":                {locale: "fr", expected: "-1 234 567,89"},
+		"language of a country": {locale: "es-MX", expected: "-1.234.567,89"},
+		"unknown":               {locale: "tlh", err: usageError("")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			format, err := parseLocale(tc.locale)
+			var usage usageError
+			if (tc.err != nil) != errors.As(err, &usage) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tc.err != nil {
+				return
+			}
+
+			if got := format.quantity(quantity); got != tc.expected {
+				t.Errorf("got %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
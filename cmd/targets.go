@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"moneyconverter/money"
+	"sort"
+	"strings"
+)
+
+// allTargets is the value of -to converting to every currency known to the rates.
+const allTargets = "all"
+
+// Orders of the conversions to several currencies.
+const (
+	sortByCode  = "code"
+	sortByValue = "value"
+)
+
+// currencyLister lists the currencies exchange rates are known for.
+type currencyLister interface {
+	SupportedCurrencies() []money.Currency
+}
+
+// parseTargets parses a comma-separated list of target currencies, such as EUR,GBP,JPY.
+// It returns nil for all the currencies.
+func parseTargets(value string) ([]money.Currency, error) {
+	if strings.EqualFold(strings.TrimSpace(value), allTargets) {
+		return nil, nil
+	}
+
+	var targets []money.Currency
+	seen := make(map[money.Currency]bool)
+	for _, code := range strings.Split(value, ",") {
+		currency, err := money.ParseCurrency(strings.TrimSpace(code))
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", strings.TrimSpace(code), err)
+		}
+
+		if !seen[currency] {
+			seen[currency] = true
+			targets = append(targets, currency)
+		}
+	}
+
+	return targets, nil
+}
+
+// supportedTargets returns every currency known to the rates but the source currency.
+func supportedTargets(rates money.RatesFetcher, source money.Currency) ([]money.Currency, error) {
+	lister, ok := rates.(currencyLister)
+	if !ok {
+		return nil, fmt.Errorf("the rates cannot list their currencies")
+	}
+
+	var targets []money.Currency
+	for _, currency := range lister.SupportedCurrencies() {
+		if currency != source {
+			targets = append(targets, currency)
+		}
+	}
+
+	return targets, nil
+}
+
+// validateSort returns an error if the order isn't one of the supported sorts.
+func validateSort(sortBy string) error {
+	switch sortBy {
+	case sortByCode, sortByValue:
+		return nil
+	default:
+		return usageError(fmt.Sprintf("unknown sort %q, expected code or value", sortBy))
+	}
+}
+
+// convertToAll converts the amount to each target currency, sorted by code or by converted value.
+// The targets that cannot be converted are left out, and their errors returned in the order of the targets.
+func convertToAll(amount money.Amount, targets []money.Currency, rates money.RatesFetcher, rounding money.RoundingMode, sortBy string) ([]money.Conversion, []error) {
+	conversions := make([]money.Conversion, 0, len(targets))
+	var failures []error
+	for _, target := range targets {
+		conversion, err := money.Explain(amount, target, rates, rounding)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("unable to convert %s to %s: %w", amount, target, err))
+			continue
+		}
+		conversions = append(conversions, conversion)
+	}
+
+	byCode := func(i, j int) bool {
+		return conversions[i].Output.Currency().ISOCode() < conversions[j].Output.Currency().ISOCode()
+	}
+
+	if sortBy == sortByValue {
+		sort.Slice(conversions, func(i, j int) bool {
+			if c := conversions[i].Output.Quantity().Cmp(conversions[j].Output.Quantity()); c != 0 {
+				return c < 0
+			}
+			return byCode(i, j)
+		})
+	} else {
+		sort.Slice(conversions, byCode)
+	}
+
+	return conversions, failures
+}
+
+// printTable writes the converted amounts in the number format, with their codes and rates, in aligned columns.
+func printTable(w io.Writer, conversions []money.Conversion, format numberFormat) {
+	values := make([]string, len(conversions))
+	width := 0
+	for i, c := range conversions {
+		values[i] = format.quantity(c.Output.Quantity())
+		width = max(width, len(values[i]))
+	}
+
+	for i, c := range conversions {
+		_, _ = fmt.Fprintf(w, "%s  %*s  %s\n", c.Output.Currency().ISOCode(), width, values[i], c.Rate)
+	}
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// version is the version of the program, set at build time with -ldflags "-X moneyconverter/cmd.version=v1.2.3".
+var version = ""
+
+// runVersion prints the version of the program and of the Go toolchain that built it.
+func (a *app) runVersion(args []string) error {
+	flags := a.newFlagSet("version", "", "Prints the version of the program.")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(a.stdout, "moneyconverter %s %s\n", currentVersion(), runtime.Version())
+	return nil
+}
+
+// currentVersion returns the version set at build time, or the one of the main module.
+func currentVersion() string {
+	if version != "" {
+		return version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+}
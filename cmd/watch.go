@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"moneyconverter/watch"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// runWatch checks the rates at each publication of the bank and notifies the rules met, until interrupted.
+func (a *app) runWatch(args []string) error {
+	flags := a.newFlagSet("watch", "rule...",
+		"Checks the rates when the bank publishes them, around 16:00 CET on working days,\n"+
+			"and notifies the rules met as JSON lines on stdout, or to a command or a webhook:\n"+
+			"  USD/EUR > 0.95          the rate of USD in EUR crosses above 0.95\n"+
+			"  EUR/USD <= 1.05         the rate of EUR in USD crosses down to 1.05\n"+
+			"  GBP moves > 1%          the rate of GBP against the euro changed by more than 1% since the previous publication")
+	exec := flags.String("exec", "", "command, with its arguments separated by spaces, run for each event given as JSON on its standard input")
+	webhook := flags.String("webhook", "", "URL each event is posted to as JSON")
+	retry := flags.Duration("retry", 15*time.Minute, "how often to check the rates while the publication of the day is late")
+	once := flags.Bool("once", false, "check the rates once and exit")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return usageError("watch needs at least one rule, such as 'USD/EUR > 0.95'")
+	}
+	if *retry <= 0 {
+		return usageError(fmt.Sprintf("invalid retry interval %s, expected a positive duration", *retry))
+	}
+
+	rules := make([]watch.Rule, 0, flags.NArg())
+	for _, text := range flags.Args() {
+		rule, err := watch.ParseRule(text)
+		if err != nil {
+			return usageError(err.Error())
+		}
+		rules = append(rules, rule)
+	}
+
+	var notifiers []watch.Notifier
+	if command := strings.Fields(*exec); len(command) > 0 {
+		notifiers = append(notifiers, watch.Command(command[0], command[1:]...))
+	}
+	if *webhook != "" {
+		notifiers = append(notifiers, watch.Webhook(*webhook, &http.Client{Timeout: a.globals.timeout}))
+	}
+	if len(notifiers) == 0 {
+		notifiers = append(notifiers, watch.JSONLines(a.stdout))
+	}
+
+	watcher := watch.New(a.newRateSource(), rules, notifiers,
+		watch.WithRetryInterval(*retry),
+		watch.WithLogger(a.newLogger()),
+	)
+
+	if *once {
+		_, err := watcher.Check(context.Background())
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return watcher.Run(ctx)
+}
@@ -5,26 +5,55 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
-const dateLayout = "20060102"
+const (
+	dateLayout  = "20060102"
+	cachePrefix = "mc_data_"
+	cacheSuffix = ".txt"
+)
 
 type Cache struct {
 	filename  string
 	cacheFile *os.File
 }
 
-// newCache is a constructor and it generates the filename to use
-func newCache() *Cache {
+// newCache is a constructor and it generates the filename to use within the given directory
+func newCache(dir string) *Cache {
 	dayToLive := time.Now().Format(dateLayout)
 
 	return &Cache{
-		filename:  fmt.Sprintf("mc_data_%s.txt", dayToLive),
+		filename:  filepath.Join(dir, cachePrefix+dayToLive+cacheSuffix),
 		cacheFile: nil,
 	}
 }
 
+// newestCache finds the most recent cache file within the given directory and returns it with the day it was written.
+func newestCache(dir string) (*Cache, time.Time, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, cachePrefix+"*"+cacheSuffix))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("glob error: %w", err)
+	}
+
+	// the day in the filenames makes them sort chronologically, skip the ones that are not named after a day.
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+	for _, filename := range matches {
+		day := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(filename), cachePrefix), cacheSuffix)
+
+		written, err := time.ParseInLocation(dateLayout, day, time.Local)
+		if err != nil {
+			continue
+		}
+
+		return &Cache{filename: filename}, written, nil
+	}
+
+	return nil, time.Time{}, fmt.Errorf("no cache file in %q", dir)
+}
+
 // writeCache byte reads from given io.Reader and writes to cache file
 func (c *Cache) writeCache(data io.Reader) error {
 	var err error
@@ -59,10 +88,28 @@ func (c *Cache) readCache(data io.Writer) error {
 	return nil
 }
 
+// modTime returns when the cache file was last written.
+func (c *Cache) modTime() (time.Time, error) {
+	info, err := os.Stat(c.filename)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("couldn't stat cache file: %w", err)
+	}
+	return info.ModTime(), nil
+}
+
 // ClearCache looks for expired cache files and deletes them
 func ClearCache() error {
+	return clearCache("")
+}
 
-	matches, err := filepath.Glob("mc_data_*.txt")
+// ClearCache deletes every cache file of the client. The history is kept.
+func (c Client) ClearCache() error {
+	return clearCache(c.cacheDir)
+}
+
+// clearCache deletes every cache file within the given directory.
+func clearCache(dir string) error {
+	matches, err := filepath.Glob(filepath.Join(dir, cachePrefix+"*"+cacheSuffix))
 	if err != nil {
 		return fmt.Errorf("glob error: %w", err)
 	}
@@ -75,3 +122,64 @@ func ClearCache() error {
 	}
 	return nil
 }
+
+// CacheFile describes a daily feed kept in the cache.
+type CacheFile struct {
+	Path string
+	// Day is the day the feed was cached for.
+	Day  time.Time
+	Size int64
+}
+
+// CacheFiles returns the cache files of the client, newest first.
+func (c Client) CacheFiles() ([]CacheFile, error) {
+	matches, err := filepath.Glob(filepath.Join(c.cacheDir, cachePrefix+"*"+cacheSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("glob error: %w", err)
+	}
+
+	var files []CacheFile
+	for _, filename := range matches {
+		day := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(filename), cachePrefix), cacheSuffix)
+
+		written, err := time.ParseInLocation(dateLayout, day, time.Local)
+		if err != nil {
+			continue
+		}
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't stat cache file: %w", err)
+		}
+
+		files = append(files, CacheFile{Path: filename, Day: written, Size: info.Size()})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Day.After(files[j].Day)
+	})
+
+	return files, nil
+}
+
+// PruneCache deletes the cache files of the days before the given one, and returns how many were deleted.
+func (c Client) PruneCache(before time.Time) (int, error) {
+	files, err := c.CacheFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	for _, file := range files {
+		if !file.Day.Before(before) {
+			continue
+		}
+
+		if err := os.Remove(file.Path); err != nil {
+			return pruned, fmt.Errorf("cache deletion error: %w", err)
+		}
+		pruned++
+	}
+
+	return pruned, nil
+}
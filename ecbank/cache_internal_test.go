@@ -0,0 +1,57 @@
+package ecbank
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClient_CacheFilesAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	today := time.Now()
+	writeCacheFile(t, dir, today, dailyResponse)
+	writeCacheFile(t, dir, today.AddDate(0, 0, -3), "old")
+	writeCacheFile(t, dir, today.AddDate(0, 0, -10), "older")
+
+	// neither the history nor files named after no day are cache files.
+	if err := os.WriteFile(filepath.Join(dir, cachePrefix+"notaday"+cacheSuffix), nil, 0o644); err != nil {
+		t.Fatalf("cannot write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, historyFilename), nil, 0o644); err != nil {
+		t.Fatalf("cannot write file: %v", err)
+	}
+
+	c := NewClient(time.Second, WithCacheDir(dir))
+
+	files, err := c.CacheFiles()
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	if len(files) != 3 {
+		t.Fatalf("expected 3 cache files, got %d", len(files))
+	}
+	if files[0].Day.Format(dateLayout) != today.Format(dateLayout) || files[2].Size != int64(len("older")) {
+		t.Errorf("expected cache files newest first, got %v", files)
+	}
+
+	pruned, err := c.PruneCache(today.AddDate(0, 0, -5))
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("expected 1 pruned file, got %d", pruned)
+	}
+
+	if err := c.ClearCache(); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, historyFilename)); err != nil {
+		t.Errorf("expected the history to be kept, got %v", err)
+	}
+	if files, _ := c.CacheFiles(); len(files) != 0 {
+		t.Errorf("expected no cache file left, got %v", files)
+	}
+}
@@ -2,9 +2,11 @@ package ecbank
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"moneyconverter/money"
 	"net/http"
 	"net/url"
@@ -19,62 +21,299 @@ const (
 	ErrClientSide         = ecBankError("client side error when contacting ECB")
 	ErrServerSide         = ecBankError("server side error when contacting ECB")
 	ErrUnknownStatusCode  = ecBankError("unknown status code contacting ECB")
+	ErrNoCachedRates      = ecBankError("no cached exchange rates available")
 )
 
 // Client can call the bank to retrieve exchange rates.
 type Client struct {
 	client *http.Client
+	// cacheDir is the directory holding the cache files, the working directory if empty.
+	cacheDir string
+	// logger receives the client's events, nothing is logged if nil.
+	logger *slog.Logger
+	// offline prevents any call to the bank, the newest cached feed is used instead.
+	offline bool
+	// maxStaleness is how old a cached feed may be to be used when the bank is unreachable, 0 disables the fallback.
+	maxStaleness time.Duration
+	// observer receives the client's events to measure them, nothing is measured if nil.
+	observer Observer
+}
+
+// Observer receives the events of a Client, such as to measure them. Its methods must be safe for concurrent use.
+type Observer interface {
+	// CacheHit is called when the feed is read from the cache.
+	CacheHit()
+	// CacheMiss is called when the feed of the day isn't in the cache.
+	CacheMiss()
+	// Fetched is called after each call to the bank with how long it took and the class of its status:
+	// 2xx, 4xx, 5xx or unknown as classified for the errors of the client, timeout, or error when no response was received.
+	Fetched(statusClass string, latency time.Duration)
+}
+
+// noObserver discards the events of a client.
+type noObserver struct{}
+
+func (noObserver) CacheHit()                     {}
+func (noObserver) CacheMiss()                    {}
+func (noObserver) Fetched(string, time.Duration) {}
+
+// Option customises a Client built by NewClient.
+type Option func(*Client)
+
+// WithLogger makes the client log cache hits and misses, and the outcome of its calls to the bank.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithOffline makes the client never call the bank and use the newest cached feed, regardless of its age.
+func WithOffline() Option {
+	return func(c *Client) {
+		c.offline = true
+	}
+}
+
+// WithStaleIfError makes the client fall back to the newest cached feed when the bank cannot be reached,
+// as long as it was cached less than maxStaleness ago, counting from the start of the day it was written.
+func WithStaleIfError(maxStaleness time.Duration) Option {
+	return func(c *Client) {
+		c.maxStaleness = maxStaleness
+	}
+}
+
+// WithCacheDir makes the client keep its cache files and history in dir rather than in the working directory.
+func WithCacheDir(dir string) Option {
+	return func(c *Client) {
+		c.cacheDir = dir
+	}
+}
+
+// WithObserver makes the client report its cache hits and misses, and its calls to the bank, to the observer.
+func WithObserver(observer Observer) Option {
+	return func(c *Client) {
+		c.observer = observer
+	}
 }
 
 // NewClient builds a client that can fetch exchange rates within a given timeout.
-func NewClient(timeout time.Duration) Client {
-	return Client{
+func NewClient(timeout time.Duration, opts ...Option) Client {
+	c := Client{
 		client: &http.Client{Timeout: timeout},
 	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+// log returns the logger of the client, or one discarding everything if none was set.
+func (c Client) log() *slog.Logger {
+	if c.logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return c.logger
+}
+
+// observe returns the observer of the client, or one discarding everything if none was set.
+func (c Client) observe() Observer {
+	if c.observer == nil {
+		return noObserver{}
+	}
+	return c.observer
 }
 
 // FetchExchangeRate fetches the ExchangeRate for the day and returns in.
 func (c Client) FetchExchangeRate(source, target money.Currency) (money.ExchangeRate, error) {
+	f, err := c.fetchFeed(context.Background())
+	if err != nil {
+		return money.ExchangeRate{}, err
+	}
+
+	rate, err := readRateFromResponse(source.ISOCode(), target.ISOCode(), f.data)
+	if err != nil {
+		return money.ExchangeRate{}, err
+	}
+
+	return rate, nil
+}
+
+// FetchRateInfo fetches the ExchangeRate for the day, along with when it was published and fetched.
+func (c Client) FetchRateInfo(source, target money.Currency) (money.RateInfo, error) {
+	table, err := c.Rates(context.Background())
+	if err != nil {
+		return money.RateInfo{}, err
+	}
+
+	return table.FetchRateInfo(source, target)
+}
+
+// Rates fetches all the exchange rates published for the day, quoted against the euro.
+// The table is marked as Stale when it comes from an outdated cache.
+func (c Client) Rates(ctx context.Context) (RateTable, error) {
+	f, err := c.fetchFeed(ctx)
+	if err != nil {
+		return RateTable{}, err
+	}
+
+	return f.rates()
+}
+
+// Refresh downloads the exchange rates from the bank even when those of the day are cached,
+// such as to pick up a publication made since, and caches them. Offline, it reads the cache as Rates does.
+func (c Client) Refresh(ctx context.Context) (RateTable, error) {
+	if c.offline {
+		return c.Rates(ctx)
+	}
+
 	dataBuffer := bytes.NewBuffer(make([]byte, 0, 4096))
-	err := readFromCache(dataBuffer)
+	if err := c.download(ctx, dataBuffer); err != nil {
+		return RateTable{}, err
+	}
 
+	return feed{data: dataBuffer, fetchedAt: time.Now()}.rates()
+}
+
+// RatesOn returns the rates published on a day, or the latest ones before it, as recorded in the history of the client.
+// It may return ErrNoHistory.
+func (c Client) RatesOn(_ context.Context, day time.Time) (RateTable, error) {
+	return c.History().RatesOn(day)
+}
+
+// feed is the daily feed of the bank, as downloaded or read from the cache.
+type feed struct {
+	data *bytes.Buffer
+	// fetchedAt is when the feed was downloaded from the bank.
+	fetchedAt time.Time
+	// stale reports whether the feed comes from an outdated cache.
+	stale bool
+}
+
+// rates reads the table of the feed.
+func (f feed) rates() (RateTable, error) {
+	table, err := readRateTableFromResponse(f.data)
 	if err != nil {
-		fmt.Print("[API CALL] ")
-		const path = "http://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+		return RateTable{}, err
+	}
+	table.Stale = f.stale
+	table.FetchedAt = f.fetchedAt
+
+	return table, nil
+}
 
-		resp, err := c.client.Get(path)
+// fetchFeed returns the daily feed of the bank, from the cache if possible.
+func (c Client) fetchFeed(ctx context.Context) (feed, error) {
+	dataBuffer := bytes.NewBuffer(make([]byte, 0, 4096))
 
+	if c.offline {
+		written, fetchedAt, err := readFromNewestCache(c.cacheDir, dataBuffer)
 		if err != nil {
-			var urlError *url.Error
-			if ok := errors.As(err, &urlError); ok && urlError.Timeout() {
-				return money.ExchangeRate{}, fmt.Errorf("%w: %s", ErrTimeout, err.Error())
-			}
-
-			return money.ExchangeRate{}, fmt.Errorf("%w: %s", ErrCallingServer, err.Error())
+			return feed{}, fmt.Errorf("%w: %s", ErrNoCachedRates, err.Error())
 		}
-		defer resp.Body.Close()
 
-		if err = checkStatusCode(resp.StatusCode); err != nil {
-			return money.ExchangeRate{}, err
-		}
+		stale := !isToday(written)
+		c.observe().CacheHit()
+		c.log().Debug("offline, using the newest cache", "dir", c.cacheDir, "written", written, "stale", stale)
+		return feed{data: dataBuffer, fetchedAt: fetchedAt, stale: stale}, nil
+	}
 
-		err = writeToCache(dataBuffer, resp.Body)
-		if err != nil {
-			return money.ExchangeRate{}, err
+	fetchedAt, err := readFromCache(c.cacheDir, dataBuffer)
+	if err == nil {
+		c.observe().CacheHit()
+		c.log().Debug("cache hit", "dir", c.cacheDir, "bytes", dataBuffer.Len())
+		return feed{data: dataBuffer, fetchedAt: fetchedAt}, nil
+	}
+	c.observe().CacheMiss()
+	c.log().Debug("cache miss", "dir", c.cacheDir, "error", err)
+
+	err = c.download(ctx, dataBuffer)
+	if err == nil {
+		return feed{data: dataBuffer, fetchedAt: time.Now()}, nil
+	}
+
+	if c.maxStaleness <= 0 {
+		return feed{}, err
+	}
+
+	dataBuffer.Reset()
+	written, fetchedAt, cacheErr := readFromNewestCache(c.cacheDir, dataBuffer)
+	if cacheErr != nil || time.Since(written) > c.maxStaleness {
+		c.log().Debug("no cache recent enough to fall back to", "dir", c.cacheDir, "max_staleness", c.maxStaleness)
+		return feed{}, err
+	}
+
+	c.log().Warn("serving stale exchange rates", "written", written, "error", err)
+	return feed{data: dataBuffer, fetchedAt: fetchedAt, stale: true}, nil
+}
+
+// download calls the bank for its daily feed, writes it to the cache and to the buffer.
+func (c Client) download(ctx context.Context, dataBuffer *bytes.Buffer) error {
+	const path = "http://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrCallingServer, err.Error())
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		c.log().Warn("calling the bank failed", "url", path, "latency", time.Since(start), "error", err)
+
+		var urlError *url.Error
+		if ok := errors.As(err, &urlError); ok && urlError.Timeout() {
+			c.observe().Fetched("timeout", time.Since(start))
+			return fmt.Errorf("%w: %s", ErrTimeout, err.Error())
 		}
+
+		c.observe().Fetched("error", time.Since(start))
+		return fmt.Errorf("%w: %s", ErrCallingServer, err.Error())
 	}
+	defer resp.Body.Close()
 
-	rate, err := readRateFromResponse(source.ISOCode(), target.ISOCode(), dataBuffer)
+	c.observe().Fetched(statusClassName(resp.StatusCode), time.Since(start))
+
+	if err = checkStatusCode(resp.StatusCode); err != nil {
+		c.log().Warn("unexpected response from the bank", "url", path, "status", resp.StatusCode, "latency", time.Since(start))
+		return err
+	}
+
+	err = writeToCache(c.cacheDir, dataBuffer, resp.Body)
 	if err != nil {
-		return money.ExchangeRate{}, err
+		return err
 	}
 
-	return rate, nil
+	c.log().Info("fetched exchange rates from the bank", "url", path, "status", resp.StatusCode, "latency", time.Since(start), "bytes", dataBuffer.Len())
+
+	c.recordHistory(dataBuffer.Bytes())
+
+	return nil
+}
+
+// recordHistory merges the publication of a downloaded feed into the history.
+// The history is a convenience: failing to record it doesn't fail the download.
+func (c Client) recordHistory(feed []byte) {
+	table, err := readRateTableFromResponse(bytes.NewReader(feed))
+	if err != nil {
+		c.log().Warn("couldn't record the publication in the history", "error", err)
+		return
+	}
+
+	added, err := c.History().Merge(table)
+	if err != nil {
+		c.log().Warn("couldn't record the publication in the history", "error", err)
+		return
+	}
+
+	c.log().Debug("recorded the publication in the history", "date", table.Date, "added", added)
 }
 
 // writeToCache creates a buffer and attempts to write to file cache
-func writeToCache(buf *bytes.Buffer, data io.ReadCloser) error {
-	cache := newCache()
+func writeToCache(dir string, buf *bytes.Buffer, data io.ReadCloser) error {
+	cache := newCache(dir)
 	err := cache.writeCache(io.TeeReader(data, buf))
 	if err != nil {
 		return fmt.Errorf("couldn't write to cache: %w", err)
@@ -82,14 +321,39 @@ func writeToCache(buf *bytes.Buffer, data io.ReadCloser) error {
 	return nil
 }
 
-// readFromCache creates a buffer and attempts to read from file cache
-func readFromCache(buf *bytes.Buffer) error {
-	cache := newCache()
+// readFromCache creates a buffer and attempts to read from file cache, and returns when the cache was written
+func readFromCache(dir string, buf *bytes.Buffer) (time.Time, error) {
+	cache := newCache(dir)
 	err := cache.readCache(buf)
 	if err != nil {
-		return fmt.Errorf("couldn't read from cache: %w", err)
+		return time.Time{}, fmt.Errorf("couldn't read from cache: %w", err)
 	}
-	return nil
+	return cache.modTime()
+}
+
+// readFromNewestCache attempts to read the most recent cache file, and returns the day it is named after
+// along with when it was written.
+func readFromNewestCache(dir string, buf *bytes.Buffer) (time.Time, time.Time, error) {
+	cache, day, err := newestCache(dir)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("couldn't find a cache file: %w", err)
+	}
+
+	err = cache.readCache(buf)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("couldn't read from cache: %w", err)
+	}
+
+	written, err := cache.modTime()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return day, written, nil
+}
+
+// isToday returns whether the given time is within the current day.
+func isToday(t time.Time) bool {
+	return t.Format(dateLayout) == time.Now().Format(dateLayout)
 }
 
 const (
@@ -111,6 +375,21 @@ func checkStatusCode(statusCode int) error {
 	}
 }
 
+// statusClassName returns the name of the class of an http status code, such as 4xx,
+// or unknown for the codes checkStatusCode doesn't classify.
+func statusClassName(statusCode int) string {
+	switch {
+	case statusCode == http.StatusOK:
+		return "2xx"
+	case httpStatusClass(statusCode) == clientErrorClass:
+		return "4xx"
+	case httpStatusClass(statusCode) == serverErrorClass:
+		return "5xx"
+	default:
+		return "unknown"
+	}
+}
+
 // httpStatusClass returns the class of an http status code.
 func httpStatusClass(statusCode int) int {
 	const httpErrorClassSize = 100
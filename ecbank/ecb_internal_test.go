@@ -1,33 +1,26 @@
 package ecbank
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"moneyconverter/money"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestEuroCentralBank_FetchExchangeRate_Success(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintln(w, `<?xml version="1.0" encoding="UTF-8"?>
-<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
-	<gesmes:subject>Reference rates</gesmes:subject>
-	<gesmes:Sender>
-		<gesmes:name>European Central Bank</gesmes:name>
-	</gesmes:Sender>
-	<Cube>
-		<Cube time='2025-04-08'>
-			<Cube currency='USD' rate='2.0000'/>
-			<Cube currency='RON' rate='6.0000'/>
-			<Cube currency='SEK' rate='10.9775'/>
-			<Cube currency='CHF' rate='0.9349'/>
-		</Cube>
-	</Cube>
-</gesmes:Envelope>`)
+		fmt.Fprintln(w, dailyResponse)
 	}))
 	defer ts.Close()
 
@@ -43,6 +36,7 @@ func TestEuroCentralBank_FetchExchangeRate_Success(t *testing.T) {
 			},
 			Timeout: time.Second,
 		},
+		cacheDir: t.TempDir(),
 	}
 
 	got, err := ecb.FetchExchangeRate(mustParseCurrency(t, "USD"), mustParseCurrency(t, "RON"))
@@ -75,6 +69,7 @@ func TestEuroCentralBank_FetchExchangeRate_Timeout(t *testing.T) {
 			},
 			Timeout: time.Second,
 		},
+		cacheDir: t.TempDir(),
 	}
 
 	_, err = ecb.FetchExchangeRate(mustParseCurrency(t, "USD"), mustParseCurrency(t, "RON"))
@@ -83,6 +78,217 @@ func TestEuroCentralBank_FetchExchangeRate_Timeout(t *testing.T) {
 	}
 }
 
+func TestEuroCentralBank_Rates(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, dailyResponse)
+	}))
+	defer ts.Close()
+
+	proxyURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to parse proxy URL: %v", err)
+	}
+
+	ecb := Client{
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyURL(proxyURL),
+			},
+			Timeout: time.Second,
+		},
+		cacheDir: t.TempDir(),
+	}
+
+	before := time.Now()
+	got, err := ecb.Rates(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	if got.FetchedAt.Before(before) || got.FetchedAt.After(time.Now()) {
+		t.Errorf("expected rates fetched during the call, got %v", got.FetchedAt)
+	}
+
+	if got.Base != mustParseCurrency(t, "EUR") {
+		t.Errorf("expected base EUR, got %v", got.Base)
+	}
+
+	if want := time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC); !got.Date.Equal(want) {
+		t.Errorf("expected date %v, got %v", want, got.Date)
+	}
+
+	if len(got.Rates) != 5 {
+		t.Errorf("expected 5 rates, got %d", len(got.Rates))
+	}
+
+	if rate := got.Rates[mustParseCurrency(t, "RON")]; money.Decimal(rate) != mustParseDecimal(t, "6") {
+		t.Errorf("expected RON rate 6, got %v", rate)
+	}
+}
+
+func TestEuroCentralBank_FetchExchangeRate_Logs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, dailyResponse)
+	}))
+	defer ts.Close()
+
+	proxyURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to parse proxy URL: %v", err)
+	}
+
+	logs := &bytes.Buffer{}
+	ecb := NewClient(time.Second, WithLogger(slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	ecb.client.Transport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+	ecb.cacheDir = t.TempDir()
+
+	for range 2 {
+		if _, err := ecb.FetchExchangeRate(mustParseCurrency(t, "USD"), mustParseCurrency(t, "RON")); err != nil {
+			t.Fatalf("unexpected error, %v", err)
+		}
+	}
+
+	for _, want := range []string{"cache miss", "fetched exchange rates from the bank", "status=200", "bytes=", "latency=", "cache hit"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected logs to contain %q, got %s", want, logs.String())
+		}
+	}
+}
+
+func TestEuroCentralBank_Rates_Offline(t *testing.T) {
+	dir := t.TempDir()
+	writeCacheFile(t, dir, time.Now().AddDate(0, 0, -30), dailyResponse)
+
+	written := time.Date(2025, 3, 9, 10, 0, 0, 0, time.UTC)
+	filename := filepath.Join(dir, cachePrefix+time.Now().AddDate(0, 0, -30).Format(dateLayout)+cacheSuffix)
+	if err := os.Chtimes(filename, written, written); err != nil {
+		t.Fatalf("cannot date cache file: %v", err)
+	}
+
+	ecb := NewClient(time.Second, WithOffline())
+	ecb.cacheDir = dir
+
+	got, err := ecb.Rates(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	if !got.Stale {
+		t.Errorf("expected rates from an old cache to be stale")
+	}
+
+	if !got.FetchedAt.Equal(written) {
+		t.Errorf("expected rates fetched when the cache was written %v, got %v", written, got.FetchedAt)
+	}
+
+	ecb.cacheDir = t.TempDir()
+	if _, err := ecb.Rates(context.Background()); !errors.Is(err, ErrNoCachedRates) {
+		t.Errorf("unexpected error: %v, expected: %v", err, ErrNoCachedRates)
+	}
+}
+
+func TestEuroCentralBank_Rates_StaleIfError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	proxyURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to parse proxy URL: %v", err)
+	}
+
+	tt := map[string]struct {
+		cachedDaysAgo int
+		maxStaleness  time.Duration
+		err           error
+	}{
+		"recent enough cache":  {cachedDaysAgo: 2, maxStaleness: 7 * 24 * time.Hour},
+		"too old cache":        {cachedDaysAgo: 10, maxStaleness: 7 * 24 * time.Hour, err: ErrServerSide},
+		"fallback not enabled": {cachedDaysAgo: 2, maxStaleness: 0, err: ErrServerSide},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			ecb := NewClient(time.Second, WithStaleIfError(tc.maxStaleness))
+			ecb.client.Transport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+			ecb.cacheDir = t.TempDir()
+			writeCacheFile(t, ecb.cacheDir, time.Now().AddDate(0, 0, -tc.cachedDaysAgo), dailyResponse)
+
+			got, err := ecb.Rates(context.Background())
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error: %v, expected: %v", err, tc.err)
+			}
+
+			if tc.err == nil && !got.Stale {
+				t.Errorf("expected rates served after an error to be stale")
+			}
+		})
+	}
+}
+
+// recordingObserver records the events of a client.
+type recordingObserver struct {
+	hits, misses int
+	fetches      []string
+}
+
+func (o *recordingObserver) CacheHit()  { o.hits++ }
+func (o *recordingObserver) CacheMiss() { o.misses++ }
+func (o *recordingObserver) Fetched(statusClass string, _ time.Duration) {
+	o.fetches = append(o.fetches, statusClass)
+}
+
+func TestEuroCentralBank_Rates_Observer(t *testing.T) {
+	tt := map[string]struct {
+		status int
+		want   string
+	}{
+		"success":      {status: http.StatusOK, want: "2xx"},
+		"client side":  {status: http.StatusNotFound, want: "4xx"},
+		"server side":  {status: http.StatusBadGateway, want: "5xx"},
+		"unclassified": {status: http.StatusSeeOther, want: "unknown"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprintln(w, dailyResponse)
+			}))
+			defer ts.Close()
+
+			proxyURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatalf("failed to parse proxy URL: %v", err)
+			}
+
+			observer := &recordingObserver{}
+			ecb := NewClient(time.Second, WithCacheDir(t.TempDir()), WithObserver(observer))
+			ecb.client.Transport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+
+			_, err = ecb.Rates(context.Background())
+			if (err == nil) != (tc.status == http.StatusOK) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// a successful download is cached and read back from the cache.
+			_, _ = ecb.Rates(context.Background())
+
+			wantHits, wantFetches := 0, []string{tc.want, tc.want}
+			if tc.status == http.StatusOK {
+				wantHits, wantFetches = 1, []string{tc.want}
+			}
+			if observer.hits != wantHits || observer.misses != len(wantFetches) {
+				t.Errorf("got %d hits and %d misses, want %d and %d", observer.hits, observer.misses, wantHits, len(wantFetches))
+			}
+			if !slices.Equal(observer.fetches, wantFetches) {
+				t.Errorf("got fetches %v, want %v", observer.fetches, wantFetches)
+			}
+		})
+	}
+}
+
 // func TestEuroCentralBank_FetchExchangeRate_ErrCallingServer(t *testing.T) {
 // 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 // 		fmt.Fprintln(w, ``)
@@ -185,6 +391,32 @@ func TestEuroCentralBank_FetchExchangeRate_Timeout(t *testing.T) {
 // 	}
 // }
 
+const dailyResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time='2025-04-08'>
+			<Cube currency='USD' rate='2.0000'/>
+			<Cube currency='RON' rate='6.0000'/>
+			<Cube currency='SEK' rate='10.9775'/>
+			<Cube currency='CHF' rate='0.9349'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+// writeCacheFile writes a cache file into dir as if it had been written on the given day.
+func writeCacheFile(t *testing.T, dir string, day time.Time, content string) {
+	t.Helper()
+
+	filename := filepath.Join(dir, cachePrefix+day.Format(dateLayout)+cacheSuffix)
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatalf("cannot write cache file %s: %v", filename, err)
+	}
+}
+
 func mustParseCurrency(t *testing.T, code string) money.Currency {
 	t.Helper()
 
@@ -5,10 +5,17 @@ import (
 	"fmt"
 	"io"
 	"moneyconverter/money"
+	"time"
 )
 
 type envelope struct {
-	Rates []currencyRate `xml:"Cube>Cube>Cube"`
+	Publications []publication `xml:"Cube>Cube"`
+}
+
+// publication holds the rates published by the bank on a given day.
+type publication struct {
+	Time  string         `xml:"time,attr"`
+	Rates []currencyRate `xml:"Cube"`
 }
 
 type currencyRate struct {
@@ -16,13 +23,30 @@ type currencyRate struct {
 	Rate     float64 `xml:"rate,attr"`
 }
 
-const baseCurrencyCode = "EUR"
+const (
+	baseCurrencyCode = "EUR"
+	// publicationLayout is the format of the dates in the bank's feeds.
+	publicationLayout = "2006-01-02"
+)
+
+// latest returns the most recent publication of the envelope, feeds are sorted from newest to oldest.
+func (e envelope) latest() publication {
+	if len(e.Publications) == 0 {
+		return publication{}
+	}
+	return e.Publications[0]
+}
 
 // exchangeRates builds a map of all the supported exchange rates.
 func (e envelope) exchangeRates() map[string]float64 {
-	rates := make(map[string]float64, len(e.Rates)+1)
+	return e.latest().exchangeRates()
+}
+
+// exchangeRates builds a map of all the exchange rates of the publication.
+func (p publication) exchangeRates() map[string]float64 {
+	rates := make(map[string]float64, len(p.Rates)+1)
 
-	for _, c := range e.Rates {
+	for _, c := range p.Rates {
 		rates[c.Currency] = c.Rate
 	}
 
@@ -54,26 +78,99 @@ func (e envelope) exchangeRate(source, target string) (money.ExchangeRate, error
 		return money.ExchangeRate{}, fmt.Errorf("failed to find the target currency %s", target)
 	}
 
+	rate, err := rateFromFactor(targetFactor / sourceFactor)
+	if err != nil {
+		return money.ExchangeRate{}, fmt.Errorf("unable to parse exchange rate from %s to %s: %w", source, target, err)
+	}
+
+	return rate, nil
+}
+
+// rateTable builds a RateTable of the latest publication of the envelope.
+func (e envelope) rateTable() (RateTable, error) {
+	return e.latest().rateTable()
+}
+
+// rateTables builds a RateTable of each publication of the envelope, in the order of the feed.
+func (e envelope) rateTables() ([]RateTable, error) {
+	tables := make([]RateTable, 0, len(e.Publications))
+	for _, p := range e.Publications {
+		table, err := p.rateTable()
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// rateTable builds a RateTable of the publication.
+func (p publication) rateTable() (RateTable, error) {
+	date, err := time.Parse(publicationLayout, p.Time)
+	if err != nil {
+		return RateTable{}, fmt.Errorf("%w: invalid publication date %q", ErrUnexpectedFormat, p.Time)
+	}
+
+	base, err := money.ParseCurrency(baseCurrencyCode)
+	if err != nil {
+		return RateTable{}, fmt.Errorf("unable to parse base currency: %w", err)
+	}
+
+	table := RateTable{
+		Base:  base,
+		Date:  date,
+		Rates: make(map[money.Currency]money.ExchangeRate, len(p.Rates)+1),
+	}
+
+	for code, factor := range p.exchangeRates() {
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			return RateTable{}, fmt.Errorf("%w: %s: %s", ErrUnexpectedFormat, code, err)
+		}
+
+		rate, err := rateFromFactor(factor)
+		if err != nil {
+			return RateTable{}, fmt.Errorf("unable to parse exchange rate of %s: %w", code, err)
+		}
+
+		table.Rates[currency] = rate
+	}
+
+	return table, nil
+}
+
+// rateFromFactor converts a factor published by the bank into an ExchangeRate.
+func rateFromFactor(factor float64) (money.ExchangeRate, error) {
 	// use a precision of 10 digits after the decimal separator.
 	// This precision should be enough as most currencies only use 5 digits
-
-	rate, err := money.ParseDecimal(fmt.Sprintf("%.10f", targetFactor/sourceFactor)[:12])
+	rate, err := money.ParseDecimal(fmt.Sprintf("%.10f", factor)[:12])
 	if err != nil {
-		return money.ExchangeRate{}, fmt.Errorf("unable to parse exchange rate from %s to %s: %w", source, target, err)
+		return money.ExchangeRate{}, err
 	}
 
 	return money.ExchangeRate(rate), nil
 }
 
-// readRateFromResponse decodes XML response into an envelope and returns the exchange rate between given currencies
-func readRateFromResponse(source, target string, respBody io.Reader) (money.ExchangeRate, error) {
-	// read the response
+// readEnvelope decodes an XML feed of the bank into an envelope.
+func readEnvelope(respBody io.Reader) (envelope, error) {
 	decoder := xml.NewDecoder(respBody)
 
 	var ecbMessage envelope
 	err := decoder.Decode(&ecbMessage)
 	if err != nil {
-		return money.ExchangeRate{}, fmt.Errorf("%w: %s", ErrUnexpectedFormat, err)
+		return envelope{}, fmt.Errorf("%w: %s", ErrUnexpectedFormat, err)
+	}
+
+	return ecbMessage, nil
+}
+
+// readRateFromResponse decodes XML response into an envelope and returns the exchange rate between given currencies
+func readRateFromResponse(source, target string, respBody io.Reader) (money.ExchangeRate, error) {
+	// read the response
+	ecbMessage, err := readEnvelope(respBody)
+	if err != nil {
+		return money.ExchangeRate{}, err
 	}
 
 	rate, err := ecbMessage.exchangeRate(source, target)
@@ -83,3 +180,18 @@ func readRateFromResponse(source, target string, respBody io.Reader) (money.Exch
 
 	return rate, nil
 }
+
+// ReadRateTable decodes a feed of the bank, such as eurofxref-daily.xml saved to disk, into a RateTable of its latest rates.
+func ReadRateTable(feed io.Reader) (RateTable, error) {
+	return readRateTableFromResponse(feed)
+}
+
+// readRateTableFromResponse decodes XML response into an envelope and returns the table of its latest rates.
+func readRateTableFromResponse(respBody io.Reader) (RateTable, error) {
+	ecbMessage, err := readEnvelope(respBody)
+	if err != nil {
+		return RateTable{}, err
+	}
+
+	return ecbMessage.rateTable()
+}
@@ -14,35 +14,35 @@ func TestExchangeRate(t *testing.T) {
 		err      error
 	}{
 		"EUR to USD": {
-			envelope: envelope{Rates: []currencyRate{{Currency: "USD", Rate: 1.5}}},
+			envelope: envelope{Publications: []publication{{Rates: []currencyRate{{Currency: "USD", Rate: 1.5}}}}},
 			source:   "EUR",
 			target:   "USD",
 			want:     mustParseExchangeRate(t, "1.5"),
 			err:      nil,
 		},
 		"EUR to EUR": {
-			envelope: envelope{Rates: []currencyRate{{Currency: "EUR", Rate: 1}}},
+			envelope: envelope{Publications: []publication{{Rates: []currencyRate{{Currency: "EUR", Rate: 1}}}}},
 			source:   "EUR",
 			target:   "EUR",
 			want:     mustParseExchangeRate(t, "1"),
 			err:      nil,
 		},
 		"CAD to EUR": {
-			envelope: envelope{Rates: []currencyRate{{Currency: "CAD", Rate: 1.5}}},
+			envelope: envelope{Publications: []publication{{Rates: []currencyRate{{Currency: "CAD", Rate: 1.5}}}}},
 			source:   "CAD",
 			target:   "EUR",
 			want:     mustParseExchangeRate(t, "0.6666666667"),
 			err:      nil,
 		},
 		"CAD to USD": {
-			envelope: envelope{Rates: []currencyRate{{Currency: "USD", Rate: 4}, {Currency: "CAD", Rate: 2}}},
+			envelope: envelope{Publications: []publication{{Rates: []currencyRate{{Currency: "USD", Rate: 4}, {Currency: "CAD", Rate: 2}}}}},
 			source:   "CAD",
 			target:   "USD",
 			want:     mustParseExchangeRate(t, "2"),
 			err:      nil,
 		},
 		"CAD to XYZ": {
-			envelope: envelope{Rates: []currencyRate{{Currency: "XYZ", Rate: 9}, {Currency: "CAD", Rate: 2}}},
+			envelope: envelope{Publications: []publication{{Rates: []currencyRate{{Currency: "XYZ", Rate: 9}, {Currency: "CAD", Rate: 2}}}}},
 			source:   "CAD",
 			target:   "XYZ",
 			want:     mustParseExchangeRate(t, "4.5"),
@@ -0,0 +1,387 @@
+package ecbank
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"moneyconverter/money"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ErrCorruptedHistory = ecBankError("corrupted history")
+	ErrNoHistory        = ecBankError("no history for the date")
+)
+
+// historyFilename is the name of the history file within the cache directory.
+// It doesn't match the pattern of the daily cache files, so that clearing the cache keeps it.
+const historyFilename = "mc_history.log"
+
+// History is an append-only store of the publications of the bank, kept in the cache directory.
+//
+// Each line of its file holds one publication as the date, the base currency, the rates
+// and a CRC-32 checksum of the previous fields, separated by spaces:
+//
+//	2025-04-08 EUR CHF=0.9349,EUR=1,USD=1.0969 3f2b9a1c
+type History struct {
+	filename string
+}
+
+// HistoryCheck reports the state of a history file.
+type HistoryCheck struct {
+	// Publications is the number of distinct publication dates.
+	Publications int
+	// Duplicates is the number of lines holding a date already seen.
+	Duplicates int
+	// Corrupted holds the numbers of the lines that cannot be read, starting at 1.
+	Corrupted []int
+}
+
+// OpenHistory returns the History kept in the given directory, the working directory if empty.
+// The file is only created when publications are first merged into it.
+func OpenHistory(dir string) History {
+	return History{filename: filepath.Join(dir, historyFilename)}
+}
+
+// History returns the history of the publications fetched by the client.
+func (c Client) History() History {
+	return OpenHistory(c.cacheDir)
+}
+
+// Merge appends the tables whose publication date isn't in the history yet, and returns how many were added.
+func (h History) Merge(tables ...RateTable) (int, error) {
+	// corrupted lines don't count, so that a publication whose line was damaged can be merged again.
+	known := make(map[string]bool)
+	err := h.scan(func(_ int, line string) error {
+		if table, err := parseHistoryLine(line); err == nil {
+			known[table.Date.Format(publicationLayout)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(h.filename, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't open history file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+
+	// after a torn write, the file ends in the middle of a line: end it so that the next one isn't glued to it.
+	complete, err := endsWithNewline(f)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't read history file: %w", err)
+	}
+	if !complete {
+		_ = w.WriteByte('\n')
+	}
+	added := 0
+	for _, table := range sortedByDate(tables) {
+		date := table.Date.Format(publicationLayout)
+		if known[date] {
+			continue
+		}
+		known[date] = true
+
+		if _, err := w.WriteString(formatHistoryLine(table) + "\n"); err != nil {
+			return added, fmt.Errorf("couldn't write to history file: %w", err)
+		}
+		added++
+	}
+
+	if err := w.Flush(); err != nil {
+		return added, fmt.Errorf("couldn't write to history file: %w", err)
+	}
+
+	return added, nil
+}
+
+// endsWithNewline reports whether the file is empty or its last byte is a newline.
+func endsWithNewline(f *os.File) (bool, error) {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return true, err
+	}
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+
+	return last[0] == '\n', nil
+}
+
+// Import merges every publication of a feed of the bank, such as eurofxref-hist.xml, into the history.
+func (h History) Import(feed io.Reader) (int, error) {
+	ecbMessage, err := readEnvelope(feed)
+	if err != nil {
+		return 0, err
+	}
+
+	tables, err := ecbMessage.rateTables()
+	if err != nil {
+		return 0, err
+	}
+
+	return h.Merge(tables...)
+}
+
+// Load returns every publication of the history, oldest first.
+// When a date was appended several times, the first one is kept.
+// It returns ErrCorruptedHistory if any line cannot be read, which Compact repairs.
+func (h History) Load() ([]RateTable, error) {
+	seen := make(map[time.Time]bool)
+	var tables []RateTable
+
+	err := h.scan(func(number int, line string) error {
+		table, err := parseHistoryLine(line)
+		if err != nil {
+			return fmt.Errorf("%w: line %d: %s", ErrCorruptedHistory, number, err)
+		}
+
+		if !seen[table.Date] {
+			seen[table.Date] = true
+			tables = append(tables, table)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedByDate(tables), nil
+}
+
+// RatesOn returns the publication of the given day, or the latest one before it when the bank published nothing that day.
+func (h History) RatesOn(day time.Time) (RateTable, error) {
+	tables, err := h.Load()
+	if err != nil {
+		return RateTable{}, err
+	}
+
+	i := sort.Search(len(tables), func(i int) bool {
+		return tables[i].Date.After(day)
+	})
+	if i == 0 {
+		return RateTable{}, fmt.Errorf("%w: %s", ErrNoHistory, day.Format(publicationLayout))
+	}
+
+	return tables[i-1], nil
+}
+
+// Series returns the rates from source to target of each publication between two days included.
+func (h History) Series(source, target money.Currency, start, end time.Time) (money.RateSeries, error) {
+	tables, err := h.Load()
+	if err != nil {
+		return money.RateSeries{}, err
+	}
+
+	var points []money.RatePoint
+	for _, table := range tables {
+		if table.Date.Before(start) || table.Date.After(end) {
+			continue
+		}
+
+		rate, err := table.FetchExchangeRate(source, target)
+		if errors.Is(err, ErrChangeRateNotFound) {
+			// the currency wasn't published that day.
+			continue
+		}
+		if err != nil {
+			return money.RateSeries{}, err
+		}
+
+		points = append(points, money.RatePoint{Date: table.Date, Rate: rate})
+	}
+
+	return money.NewRateSeries(points)
+}
+
+// Verify reads the whole history and reports duplicated and corrupted lines.
+func (h History) Verify() (HistoryCheck, error) {
+	var check HistoryCheck
+	seen := make(map[time.Time]bool)
+
+	err := h.scan(func(number int, line string) error {
+		table, err := parseHistoryLine(line)
+		switch {
+		case err != nil:
+			check.Corrupted = append(check.Corrupted, number)
+		case seen[table.Date]:
+			check.Duplicates++
+		default:
+			seen[table.Date] = true
+			check.Publications++
+		}
+		return nil
+	})
+
+	return check, err
+}
+
+// Compact rewrites the history with one line per publication date, oldest first, dropping corrupted lines.
+// The file is replaced atomically so that an interrupted compaction leaves the history untouched.
+func (h History) Compact() error {
+	seen := make(map[time.Time]bool)
+	var tables []RateTable
+
+	err := h.scan(func(_ int, line string) error {
+		table, err := parseHistoryLine(line)
+		if err == nil && !seen[table.Date] {
+			seen[table.Date] = true
+			tables = append(tables, table)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(h.filename), historyFilename+".*")
+	if err != nil {
+		return fmt.Errorf("couldn't create compacted history file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, table := range sortedByDate(tables) {
+		if _, err := w.WriteString(formatHistoryLine(table) + "\n"); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("couldn't write compacted history file: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("couldn't write compacted history file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("couldn't write compacted history file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), h.filename); err != nil {
+		return fmt.Errorf("couldn't replace history file: %w", err)
+	}
+
+	return nil
+}
+
+// scan calls fn with each non-empty line of the history file and its number. A missing file is an empty history.
+func (h History) scan(fn func(number int, line string) error) error {
+	f, err := os.Open(h.filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't open history file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// a publication of the bank holds about 30 rates, leave room for many more.
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if err := fn(number, line); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("couldn't read history file: %w", err)
+	}
+
+	return nil
+}
+
+// formatHistoryLine returns the line of the history holding the table, without line feed.
+func formatHistoryLine(table RateTable) string {
+	currencies := table.SupportedCurrencies()
+	rates := make([]string, len(currencies))
+	for i, currency := range currencies {
+		rates[i] = currency.ISOCode() + "=" + table.Rates[currency].String()
+	}
+
+	content := table.Date.Format(publicationLayout) + " " + table.Base.ISOCode() + " " + strings.Join(rates, ",")
+	return fmt.Sprintf("%s %08x", content, crc32.ChecksumIEEE([]byte(content)))
+}
+
+// parseHistoryLine reads a line of the history, checking its checksum.
+func parseHistoryLine(line string) (RateTable, error) {
+	sep := strings.LastIndexByte(line, ' ')
+	if sep < 0 {
+		return RateTable{}, fmt.Errorf("missing checksum")
+	}
+
+	content, checksum := line[:sep], line[sep+1:]
+	sum, err := strconv.ParseUint(checksum, 16, 32)
+	if err != nil || uint32(sum) != crc32.ChecksumIEEE([]byte(content)) {
+		return RateTable{}, fmt.Errorf("checksum mismatch")
+	}
+
+	fields := strings.Fields(content)
+	if len(fields) != 3 {
+		return RateTable{}, fmt.Errorf("expected 3 fields, got %d", len(fields))
+	}
+
+	date, err := time.Parse(publicationLayout, fields[0])
+	if err != nil {
+		return RateTable{}, fmt.Errorf("invalid date %q", fields[0])
+	}
+
+	base, err := money.ParseCurrency(fields[1])
+	if err != nil {
+		return RateTable{}, fmt.Errorf("invalid base currency %q", fields[1])
+	}
+
+	table := RateTable{Base: base, Date: date, Rates: make(map[money.Currency]money.ExchangeRate)}
+	for _, field := range strings.Split(fields[2], ",") {
+		code, value, found := strings.Cut(field, "=")
+		if !found {
+			return RateTable{}, fmt.Errorf("invalid rate %q", field)
+		}
+
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			return RateTable{}, fmt.Errorf("invalid currency %q", code)
+		}
+
+		rate, err := money.ParseDecimal(value)
+		if err != nil {
+			return RateTable{}, fmt.Errorf("invalid rate %q", value)
+		}
+
+		table.Rates[currency] = money.ExchangeRate(rate)
+	}
+
+	return table, nil
+}
+
+// sortedByDate returns a copy of the tables sorted by publication date, oldest first.
+func sortedByDate(tables []RateTable) []RateTable {
+	sorted := make([]RateTable, len(tables))
+	copy(sorted, tables)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	return sorted
+}
@@ -0,0 +1,218 @@
+package ecbank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"moneyconverter/money"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const historyResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2025-04-08">
+			<Cube currency="USD" rate="1.5"/>
+			<Cube currency="RON" rate="6"/>
+		</Cube>
+		<Cube time="2025-04-07">
+			<Cube currency="USD" rate="1.2"/>
+			<Cube currency="RON" rate="5"/>
+		</Cube>
+		<Cube time="2025-04-04">
+			<Cube currency="USD" rate="1"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestHistory_ImportAndLoad(t *testing.T) {
+	history := OpenHistory(t.TempDir())
+
+	added, err := history.Import(strings.NewReader(historyResponse))
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if added != 3 {
+		t.Errorf("expected 3 publications added, got %d", added)
+	}
+
+	// importing the same feed again adds nothing.
+	added, err = history.Import(strings.NewReader(historyResponse))
+	if err != nil || added != 0 {
+		t.Errorf("expected no publication added, got %d, %v", added, err)
+	}
+
+	tables, err := history.Load()
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	var dates []string
+	for _, table := range tables {
+		dates = append(dates, table.Date.Format(publicationLayout))
+	}
+	if got := strings.Join(dates, ","); got != "2025-04-04,2025-04-07,2025-04-08" {
+		t.Errorf("expected publications oldest first, got %s", got)
+	}
+
+	if rate := tables[2].Rates[mustParseCurrency(t, "USD")]; rate.String() != "1.5" {
+		t.Errorf("expected USD rate 1.5, got %s", rate)
+	}
+}
+
+func TestHistory_RatesOnAndSeries(t *testing.T) {
+	history := OpenHistory(t.TempDir())
+	if _, err := history.Import(strings.NewReader(historyResponse)); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	// nothing is published on sundays, the publication of the friday before applies.
+	table, err := history.RatesOn(time.Date(2025, 4, 6, 0, 0, 0, 0, time.UTC))
+	if err != nil || table.Date.Format(publicationLayout) != "2025-04-04" {
+		t.Errorf("expected the publication of 2025-04-04, got %v, %v", table.Date, err)
+	}
+
+	if _, err := history.RatesOn(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrNoHistory) {
+		t.Errorf("unexpected error: %v, expected: %v", err, ErrNoHistory)
+	}
+
+	series, err := history.Series(mustParseCurrency(t, "USD"), mustParseCurrency(t, "RON"),
+		time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	var got []string
+	for _, p := range series.Points() {
+		got = append(got, fmt.Sprintf("%s %s", p.Date.Format(publicationLayout), p.Rate))
+	}
+	if strings.Join(got, ",") != "2025-04-07 4.1666666667,2025-04-08 4" {
+		t.Errorf("unexpected series %v", got)
+	}
+}
+
+func TestHistory_VerifyAndCompact(t *testing.T) {
+	dir := t.TempDir()
+	history := OpenHistory(dir)
+
+	if _, err := history.Import(strings.NewReader(historyResponse)); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	content, err := os.ReadFile(history.filename)
+	if err != nil {
+		t.Fatalf("cannot read history: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	// duplicate the first line, and tamper with a rate of the last one.
+	tampered := append([]string{lines[0]}, lines...)
+	tampered[len(tampered)-1] = strings.Replace(tampered[len(tampered)-1], "USD=1.5", "USD=9.5", 1)
+	if err := os.WriteFile(history.filename, []byte(strings.Join(tampered, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("cannot write history: %v", err)
+	}
+
+	check, err := history.Verify()
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if check.Publications != 2 || check.Duplicates != 1 || len(check.Corrupted) != 1 || check.Corrupted[0] != 4 {
+		t.Errorf("unexpected check %+v", check)
+	}
+
+	if _, err := history.Load(); !errors.Is(err, ErrCorruptedHistory) {
+		t.Errorf("unexpected error: %v, expected: %v", err, ErrCorruptedHistory)
+	}
+
+	if err := history.Compact(); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	check, err = history.Verify()
+	if err != nil || check.Publications != 2 || check.Duplicates != 0 || len(check.Corrupted) != 0 {
+		t.Errorf("unexpected check after compaction %+v, %v", check, err)
+	}
+}
+
+func TestEuroCentralBank_Rates_RecordsHistory(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, dailyResponse)
+	}))
+	defer ts.Close()
+
+	proxyURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to parse proxy URL: %v", err)
+	}
+
+	ecb := NewClient(time.Second)
+	ecb.client.Transport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+	ecb.cacheDir = t.TempDir()
+
+	if _, err := ecb.Rates(context.Background()); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	// clearing the daily cache keeps the history.
+	if err := os.Remove(newCache(ecb.cacheDir).filename); err != nil {
+		t.Fatalf("cannot remove cache file: %v", err)
+	}
+
+	table, err := ecb.History().RatesOn(time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	if rate := table.Rates[mustParseCurrency(t, "RON")]; money.Decimal(rate) != mustParseDecimal(t, "6") {
+		t.Errorf("expected RON rate 6, got %v", rate)
+	}
+}
+
+func TestHistory_MergeAfterTornWrite(t *testing.T) {
+	history := OpenHistory(t.TempDir())
+	if _, err := history.Import(strings.NewReader(historyResponse)); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	content, err := os.ReadFile(history.filename)
+	if err != nil {
+		t.Fatalf("cannot read history: %v", err)
+	}
+
+	// the write of the last line was interrupted halfway.
+	torn := strings.TrimSuffix(string(content), "\n")
+	torn = torn[:strings.LastIndex(torn, "\n")+20]
+	if err := os.WriteFile(history.filename, []byte(torn), 0o644); err != nil {
+		t.Fatalf("cannot write history: %v", err)
+	}
+
+	// the publication of the torn line is merged again, on a line of its own.
+	added, err := history.Import(strings.NewReader(historyResponse))
+	if err != nil || added != 1 {
+		t.Fatalf("expected 1 publication added, got %d, %v", added, err)
+	}
+
+	check, err := history.Verify()
+	if err != nil || check.Publications != 3 || len(check.Corrupted) != 1 || check.Corrupted[0] != 3 {
+		t.Errorf("unexpected check %+v, %v", check, err)
+	}
+
+	if err := history.Compact(); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	tables, err := history.Load()
+	if err != nil || len(tables) != 3 {
+		t.Fatalf("expected the 3 publications after compaction, got %d, %v", len(tables), err)
+	}
+	if rate := tables[2].Rates[mustParseCurrency(t, "USD")]; rate.String() != "1.5" {
+		t.Errorf("expected USD rate 1.5, got %s", rate)
+	}
+}
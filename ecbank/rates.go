@@ -0,0 +1,146 @@
+package ecbank
+
+import (
+	"fmt"
+	"moneyconverter/money"
+	"sort"
+	"time"
+)
+
+// RateTable is a snapshot of the exchange rates published on a given day, quoted against a base currency.
+type RateTable struct {
+	// Base is the currency every rate is quoted against.
+	Base money.Currency
+	// Date is the day the rates were published.
+	Date time.Time
+	// Rates holds the amount of each currency that one unit of the Base is worth.
+	Rates map[money.Currency]money.ExchangeRate
+	// Stale reports whether the rates come from an outdated cache rather than from the bank's latest feed.
+	Stale bool
+	// FetchedAt is when the rates were downloaded from the bank, zero if unknown.
+	FetchedAt time.Time
+}
+
+// providerName identifies the bank as the provider of the rates of a table.
+const providerName = "ECB"
+
+// SupportedCurrencies returns the currencies of the table, sorted by code.
+func (t RateTable) SupportedCurrencies() []money.Currency {
+	currencies := make([]money.Currency, 0, len(t.Rates))
+	for currency := range t.Rates {
+		currencies = append(currencies, currency)
+	}
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].ISOCode() < currencies[j].ISOCode()
+	})
+
+	return currencies
+}
+
+// Rebase returns a copy of the table where every rate is quoted against the given base currency.
+func (t RateTable) Rebase(base money.Currency) (RateTable, error) {
+	baseRate, ok := t.Rates[base]
+	if !ok {
+		return RateTable{}, fmt.Errorf("%w: failed to find the base currency %s", ErrChangeRateNotFound, base)
+	}
+
+	rebased := RateTable{
+		Base:      base,
+		Date:      t.Date,
+		Rates:     make(map[money.Currency]money.ExchangeRate, len(t.Rates)),
+		Stale:     t.Stale,
+		FetchedAt: t.FetchedAt,
+	}
+
+	for currency, rate := range t.Rates {
+		crossRate, err := money.CrossRate(baseRate, rate)
+		if err != nil {
+			return RateTable{}, fmt.Errorf("unable to compute exchange rate from %s to %s: %w", base, currency, err)
+		}
+		rebased.Rates[currency] = crossRate
+	}
+
+	return rebased, nil
+}
+
+// FetchExchangeRate returns the ExchangeRate between two currencies of the table.
+// It makes a RateTable usable wherever exchange rates are fetched, without further calls to the bank.
+func (t RateTable) FetchExchangeRate(source, target money.Currency) (money.ExchangeRate, error) {
+	sourceRate, sourceFound := t.Rates[source]
+	if !sourceFound {
+		return money.ExchangeRate{}, fmt.Errorf("%w: failed to find the source currency %s", ErrChangeRateNotFound, source)
+	}
+
+	targetRate, targetFound := t.Rates[target]
+	if !targetFound {
+		return money.ExchangeRate{}, fmt.Errorf("%w: failed to find the target currency %s", ErrChangeRateNotFound, target)
+	}
+
+	rate, err := money.CrossRate(sourceRate, targetRate)
+	if err != nil {
+		return money.ExchangeRate{}, fmt.Errorf("unable to compute exchange rate from %s to %s: %w", source, target, err)
+	}
+
+	return rate, nil
+}
+
+// AddTo records every rate of the table into the graph, so that they can be chained with the rates of other providers.
+func (t RateTable) AddTo(graph *money.RateGraph, provider string) error {
+	for _, currency := range t.SupportedCurrencies() {
+		if currency == t.Base {
+			continue
+		}
+
+		if err := graph.AddRate(t.Base, currency, t.Rates[currency], provider); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FetchRateInfo returns the ExchangeRate between two currencies of the table, along with when it was published and fetched,
+// and the path through the base currency it was computed along.
+func (t RateTable) FetchRateInfo(source, target money.Currency) (money.RateInfo, error) {
+	rate, err := t.FetchExchangeRate(source, target)
+	if err != nil {
+		return money.RateInfo{}, err
+	}
+
+	path, err := t.path(source, target)
+	if err != nil {
+		return money.RateInfo{}, err
+	}
+	path.Rate = rate
+
+	return money.RateInfo{
+		Rate:        rate,
+		Provider:    providerName,
+		PublishedAt: t.Date,
+		FetchedAt:   t.FetchedAt,
+		Stale:       t.Stale,
+		Path:        path,
+	}, nil
+}
+
+// path returns the steps from source to target through the base currency, without the resulting rate.
+func (t RateTable) path(source, target money.Currency) (money.Path, error) {
+	var path money.Path
+	if source == target {
+		return path, nil
+	}
+
+	if source != t.Base {
+		inverse, err := t.Rates[source].Inverse()
+		if err != nil {
+			return money.Path{}, fmt.Errorf("unable to invert exchange rate from %s to %s: %w", t.Base, source, err)
+		}
+		path.Steps = append(path.Steps, money.PathStep{From: source, To: t.Base, Rate: inverse, Provider: providerName, Inverted: true})
+	}
+	if target != t.Base {
+		path.Steps = append(path.Steps, money.PathStep{From: t.Base, To: target, Rate: t.Rates[target], Provider: providerName})
+	}
+
+	return path, nil
+}
@@ -0,0 +1,173 @@
+package ecbank
+
+import (
+	"errors"
+	"moneyconverter/money"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRateTable_FetchExchangeRate(t *testing.T) {
+	table := RateTable{
+		Base: mustParseCurrency(t, "EUR"),
+		Rates: map[money.Currency]money.ExchangeRate{
+			mustParseCurrency(t, "EUR"): mustParseExchangeRate(t, "1"),
+			mustParseCurrency(t, "USD"): mustParseExchangeRate(t, "4"),
+			mustParseCurrency(t, "CAD"): mustParseExchangeRate(t, "1.5"),
+		},
+	}
+
+	tt := map[string]struct {
+		source string
+		target string
+		want   money.ExchangeRate
+		err    error
+	}{
+		"EUR to USD": {source: "EUR", target: "USD", want: mustParseExchangeRate(t, "4")},
+		"CAD to EUR": {source: "CAD", target: "EUR", want: mustParseExchangeRate(t, "0.6666666667")},
+		"USD to CAD": {source: "USD", target: "CAD", want: mustParseExchangeRate(t, "0.375")},
+		"USD to USD": {source: "USD", target: "USD", want: mustParseExchangeRate(t, "1")},
+		"XYZ to EUR": {source: "XYZ", target: "EUR", err: ErrChangeRateNotFound},
+		"EUR to XYZ": {source: "EUR", target: "XYZ", err: ErrChangeRateNotFound},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := table.FetchExchangeRate(mustParseCurrency(t, tc.source), mustParseCurrency(t, tc.target))
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRateTable_Rebase(t *testing.T) {
+	table := RateTable{
+		Base: mustParseCurrency(t, "EUR"),
+		Rates: map[money.Currency]money.ExchangeRate{
+			mustParseCurrency(t, "EUR"): mustParseExchangeRate(t, "1"),
+			mustParseCurrency(t, "USD"): mustParseExchangeRate(t, "2"),
+			mustParseCurrency(t, "RON"): mustParseExchangeRate(t, "6"),
+		},
+	}
+
+	got, err := table.Rebase(mustParseCurrency(t, "USD"))
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	want := RateTable{
+		Base: mustParseCurrency(t, "USD"),
+		Rates: map[money.Currency]money.ExchangeRate{
+			mustParseCurrency(t, "EUR"): mustParseExchangeRate(t, "0.5"),
+			mustParseCurrency(t, "USD"): mustParseExchangeRate(t, "1"),
+			mustParseCurrency(t, "RON"): mustParseExchangeRate(t, "3"),
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, err := table.Rebase(mustParseCurrency(t, "XYZ")); !errors.Is(err, ErrChangeRateNotFound) {
+		t.Errorf("expected error %v, got %v", ErrChangeRateNotFound, err)
+	}
+}
+
+func TestRateTable_SupportedCurrencies(t *testing.T) {
+	table := RateTable{
+		Rates: map[money.Currency]money.ExchangeRate{
+			mustParseCurrency(t, "USD"): mustParseExchangeRate(t, "2"),
+			mustParseCurrency(t, "EUR"): mustParseExchangeRate(t, "1"),
+			mustParseCurrency(t, "CHF"): mustParseExchangeRate(t, "0.9"),
+		},
+	}
+
+	got := table.SupportedCurrencies()
+	want := []money.Currency{mustParseCurrency(t, "CHF"), mustParseCurrency(t, "EUR"), mustParseCurrency(t, "USD")}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestRateTable_AddTo(t *testing.T) {
+	table := RateTable{
+		Base: mustParseCurrency(t, "EUR"),
+		Rates: map[money.Currency]money.ExchangeRate{
+			mustParseCurrency(t, "EUR"): mustParseExchangeRate(t, "1"),
+			mustParseCurrency(t, "USD"): mustParseExchangeRate(t, "2"),
+			mustParseCurrency(t, "JPY"): mustParseExchangeRate(t, "160"),
+		},
+	}
+
+	var graph money.RateGraph
+	if err := table.AddTo(&graph, "ecb"); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	// a provider quoting against the dollar.
+	if err := graph.AddRate(mustParseCurrency(t, "BTC"), mustParseCurrency(t, "USD"), mustParseExchangeRate(t, "60000"), "crypto"); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	path, err := graph.FindPath(mustParseCurrency(t, "BTC"), mustParseCurrency(t, "JPY"))
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	if path.String() != "BTC→USD→EUR→JPY" || path.Rate != mustParseExchangeRate(t, "4800000") {
+		t.Errorf("unexpected path %s at rate %s", path, path.Rate)
+	}
+}
+
+func TestRateTable_FetchRateInfo(t *testing.T) {
+	published := time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC)
+	fetched := time.Date(2025, 4, 8, 16, 30, 0, 0, time.UTC)
+	table := RateTable{
+		Base: mustParseCurrency(t, "EUR"),
+		Date: published,
+		Rates: map[money.Currency]money.ExchangeRate{
+			mustParseCurrency(t, "EUR"): mustParseExchangeRate(t, "1"),
+			mustParseCurrency(t, "USD"): mustParseExchangeRate(t, "4"),
+		},
+		Stale:     true,
+		FetchedAt: fetched,
+	}
+
+	got, err := table.FetchRateInfo(mustParseCurrency(t, "USD"), mustParseCurrency(t, "EUR"))
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	want := money.RateInfo{
+		Rate:        mustParseExchangeRate(t, "0.25"),
+		Provider:    "ECB",
+		PublishedAt: published,
+		FetchedAt:   fetched,
+		Stale:       true,
+		Path: money.Path{
+			Steps: []money.PathStep{{
+				From:     mustParseCurrency(t, "USD"),
+				To:       mustParseCurrency(t, "EUR"),
+				Rate:     mustParseExchangeRate(t, "0.25"),
+				Provider: "ECB",
+				Inverted: true,
+			}},
+			Rate: mustParseExchangeRate(t, "0.25"),
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, err := table.FetchRateInfo(mustParseCurrency(t, "XYZ"), mustParseCurrency(t, "EUR")); !errors.Is(err, ErrChangeRateNotFound) {
+		t.Errorf("unexpected error: %v, expected: %v", err, ErrChangeRateNotFound)
+	}
+}
@@ -0,0 +1,52 @@
+package ecbank
+
+import (
+	"time"
+)
+
+// publicationHour and publicationMinute tell when the bank is expected to have published the rates of a working day,
+// in Central European Time: it publishes them around 16:00, a few minutes are left for the feed to be updated.
+const (
+	publicationHour   = 16
+	publicationMinute = 5
+)
+
+// centralEuropeanTime returns the time zone of the bank, approximated by UTC+1 if the time zone database is missing.
+func centralEuropeanTime() *time.Location {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		return time.FixedZone("CET", 60*60)
+	}
+	return location
+}
+
+// isWorkingDay reports whether the bank publishes rates on the day. Holidays are not known, and only delay the expectation.
+func isWorkingDay(day time.Time) bool {
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+}
+
+// publicationTime returns when the rates of the day are expected, in the time zone of the bank.
+func publicationTime(day time.Time) time.Time {
+	day = day.In(centralEuropeanTime())
+	return time.Date(day.Year(), day.Month(), day.Day(), publicationHour, publicationMinute, 0, 0, day.Location())
+}
+
+// ExpectedPublication returns the day of the latest publication expected at a time, as dated by the bank.
+// Rates dated before it are those of a previous publication, such as cached before the bank published the day's.
+func ExpectedPublication(now time.Time) time.Time {
+	published := publicationTime(now)
+	for !isWorkingDay(published) || published.After(now) {
+		published = publicationTime(published.AddDate(0, 0, -1))
+	}
+
+	return time.Date(published.Year(), published.Month(), published.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// NextPublication returns when the next publication is expected after a time.
+func NextPublication(now time.Time) time.Time {
+	next := publicationTime(now)
+	for !isWorkingDay(next) || !next.After(now) {
+		next = publicationTime(next.AddDate(0, 0, 1))
+	}
+	return next
+}
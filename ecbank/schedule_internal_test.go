@@ -0,0 +1,65 @@
+package ecbank
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	cet := centralEuropeanTime()
+	at := func(day string, hour, minute int) time.Time {
+		t.Helper()
+
+		date, err := time.ParseInLocation(time.DateOnly, day, cet)
+		if err != nil {
+			t.Fatalf("invalid day %q: %s", day, err)
+		}
+		return date.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	day := func(day string) time.Time {
+		t.Helper()
+
+		date, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			t.Fatalf("invalid day %q: %s", day, err)
+		}
+		return date
+	}
+
+	tt := map[string]struct {
+		now      time.Time
+		expected time.Time
+		next     time.Time
+	}{
+		"morning": {
+			now: at("2025-04-08", 9, 0), expected: day("2025-04-07"), next: at("2025-04-08", 16, 5),
+		},
+		"at the publication": {
+			now: at("2025-04-08", 16, 5), expected: day("2025-04-08"), next: at("2025-04-09", 16, 5),
+		},
+		"evening": {
+			now: at("2025-04-08", 17, 0), expected: day("2025-04-08"), next: at("2025-04-09", 16, 5),
+		},
+		"monday morning": {
+			now: at("2025-04-14", 9, 0), expected: day("2025-04-11"), next: at("2025-04-14", 16, 5),
+		},
+		"friday evening": {
+			now: at("2025-04-11", 18, 0), expected: day("2025-04-11"), next: at("2025-04-14", 16, 5),
+		},
+		"sunday": {
+			now: at("2025-04-13", 12, 0), expected: day("2025-04-11"), next: at("2025-04-14", 16, 5),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if got := ExpectedPublication(tc.now); !got.Equal(tc.expected) {
+				t.Errorf("ExpectedPublication got %s, want %s", got, tc.expected)
+			}
+
+			if got := NextPublication(tc.now); !got.Equal(tc.next) {
+				t.Errorf("NextPublication got %s, want %s", got, tc.next)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package ecbank
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"moneyconverter/money"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dataAPIURL is the root of the bank's SDMX REST API serving the EXR dataflow.
+const dataAPIURL = "https://data-api.ecb.europa.eu/service/data/EXR"
+
+// Frequency is the periodicity of the observations of a time series.
+type Frequency string
+
+const (
+	// Daily series hold the reference rate of each publication day.
+	Daily Frequency = "D"
+	// Monthly series hold the average of the reference rates over each month.
+	Monthly Frequency = "M"
+	// Quarterly series hold the average of the reference rates over each quarter.
+	Quarterly Frequency = "Q"
+	// Annual series hold the average of the reference rates over each year.
+	Annual Frequency = "A"
+)
+
+// Observation is the exchange rate of a period of a time series.
+type Observation struct {
+	// Period is the first day of the period the rate applies to.
+	Period time.Time
+	Rate   money.ExchangeRate
+}
+
+// TimeSeries holds the exchange rates of a currency against the euro over consecutive periods, oldest first.
+type TimeSeries struct {
+	Base         money.Currency
+	Currency     money.Currency
+	Frequency    Frequency
+	Observations []Observation
+}
+
+// RateSeries returns the observations of the time series as a money.RateSeries, to compute statistics over them.
+func (t TimeSeries) RateSeries() (money.RateSeries, error) {
+	points := make([]money.RatePoint, len(t.Observations))
+	for i, o := range t.Observations {
+		points[i] = money.RatePoint{Date: o.Period, Rate: o.Rate}
+	}
+
+	return money.NewRateSeries(points)
+}
+
+// SeriesClient queries the bank's data API for time series of exchange rates.
+type SeriesClient struct {
+	client  *http.Client
+	baseURL string
+	logger  *slog.Logger
+}
+
+// Series returns a client of the bank's data API sharing the http client and logger of c.
+func (c Client) Series() SeriesClient {
+	return SeriesClient{
+		client:  c.client,
+		baseURL: dataAPIURL,
+		logger:  c.logger,
+	}
+}
+
+// log returns the logger of the client, or one discarding everything if none was set.
+func (s SeriesClient) log() *slog.Logger {
+	if s.logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return s.logger
+}
+
+// FetchSeries returns the reference rates of a currency against the euro between two dates included,
+// with one observation per period of the given frequency.
+func (s SeriesClient) FetchSeries(ctx context.Context, currency money.Currency, frequency Frequency, start, end time.Time) (TimeSeries, error) {
+	base, err := money.ParseCurrency(baseCurrencyCode)
+	if err != nil {
+		return TimeSeries{}, fmt.Errorf("unable to parse base currency: %w", err)
+	}
+
+	if err := frequency.validate(); err != nil {
+		return TimeSeries{}, err
+	}
+
+	query := url.Values{}
+	query.Set("startPeriod", start.Format(time.DateOnly))
+	query.Set("endPeriod", end.Format(time.DateOnly))
+	query.Set("format", "csvdata")
+
+	// the series key is FREQ.CURRENCY.CURRENCY_DENOM.EXR_TYPE.EXR_SUFFIX, SP00.A being the reference rates' average or standardised measure.
+	path := fmt.Sprintf("%s/%s.%s.%s.SP00.A?%s", s.baseURL, frequency, currency.ISOCode(), base.ISOCode(), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return TimeSeries{}, fmt.Errorf("%w: %s", ErrCallingServer, err.Error())
+	}
+
+	startTime := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.log().Warn("calling the data API failed", "url", path, "latency", time.Since(startTime), "error", err)
+
+		var urlError *url.Error
+		if ok := errors.As(err, &urlError); ok && urlError.Timeout() {
+			return TimeSeries{}, fmt.Errorf("%w: %s", ErrTimeout, err.Error())
+		}
+
+		return TimeSeries{}, fmt.Errorf("%w: %s", ErrCallingServer, err.Error())
+	}
+	defer resp.Body.Close()
+
+	s.log().Info("fetched time series from the data API", "url", path, "status", resp.StatusCode, "latency", time.Since(startTime))
+
+	// the API answers 404 when the series has no observation over the period.
+	if resp.StatusCode == http.StatusNotFound {
+		return TimeSeries{}, fmt.Errorf("%w: no %s observations for %s", ErrChangeRateNotFound, frequency, currency)
+	}
+
+	if err = checkStatusCode(resp.StatusCode); err != nil {
+		return TimeSeries{}, err
+	}
+
+	observations, err := readObservations(resp.Body, frequency)
+	if err != nil {
+		return TimeSeries{}, err
+	}
+
+	return TimeSeries{
+		Base:         base,
+		Currency:     currency,
+		Frequency:    frequency,
+		Observations: observations,
+	}, nil
+}
+
+// validate returns an error if the frequency is not one served by the data API.
+func (f Frequency) validate() error {
+	switch f {
+	case Daily, Monthly, Quarterly, Annual:
+		return nil
+	default:
+		return fmt.Errorf("unsupported frequency %q", string(f))
+	}
+}
+
+// readObservations decodes the observations of an SDMX-CSV response.
+// Periods without value are skipped.
+func readObservations(body io.Reader, frequency Frequency) ([]Observation, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedFormat, err)
+	}
+
+	periodColumn, valueColumn := -1, -1
+	for i, name := range header {
+		switch name {
+		case "TIME_PERIOD":
+			periodColumn = i
+		case "OBS_VALUE":
+			valueColumn = i
+		}
+	}
+
+	if periodColumn < 0 || valueColumn < 0 {
+		return nil, fmt.Errorf("%w: missing TIME_PERIOD or OBS_VALUE column", ErrUnexpectedFormat)
+	}
+
+	var observations []Observation
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnexpectedFormat, err)
+		}
+
+		if len(record) <= periodColumn || len(record) <= valueColumn {
+			return nil, fmt.Errorf("%w: truncated record %v", ErrUnexpectedFormat, record)
+		}
+
+		if record[valueColumn] == "" {
+			continue
+		}
+
+		period, err := parsePeriod(record[periodColumn], frequency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnexpectedFormat, err)
+		}
+
+		rate, err := money.ParseRate(record[valueColumn])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid value %q of %s: %s", ErrUnexpectedFormat, record[valueColumn], record[periodColumn], err)
+		}
+
+		observations = append(observations, Observation{Period: period, Rate: rate})
+	}
+
+	return observations, nil
+}
+
+// parsePeriod returns the first day of a period written as in the data API, such as 2024-03-15, 2024-03, 2024-Q1 or 2024.
+func parsePeriod(period string, frequency Frequency) (time.Time, error) {
+	switch frequency {
+	case Daily:
+		return time.Parse(time.DateOnly, period)
+	case Monthly:
+		return time.Parse("2006-01", period)
+	case Annual:
+		return time.Parse("2006", period)
+	}
+
+	year, quarter, found := strings.Cut(period, "-Q")
+	if !found {
+		return time.Time{}, fmt.Errorf("invalid period %q", period)
+	}
+
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid period %q", period)
+	}
+
+	q, err := strconv.Atoi(quarter)
+	if err != nil || q < 1 || q > 4 {
+		return time.Time{}, fmt.Errorf("invalid period %q", period)
+	}
+
+	return time.Date(y, time.Month(3*(q-1)+1), 1, 0, 0, 0, 0, time.UTC), nil
+}
@@ -0,0 +1,133 @@
+package ecbank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// recorded responses of the data API, trimmed to a few columns.
+const (
+	dailySeriesResponse = `KEY,FREQ,CURRENCY,CURRENCY_DENOM,EXR_TYPE,EXR_SUFFIX,TIME_PERIOD,OBS_VALUE,OBS_STATUS,TITLE
+EXR.D.USD.EUR.SP00.A,D,USD,EUR,SP00,A,2024-01-02,1.0956,A,US dollar/Euro
+EXR.D.USD.EUR.SP00.A,D,USD,EUR,SP00,A,2024-01-03,1.0919,A,US dollar/Euro
+EXR.D.USD.EUR.SP00.A,D,USD,EUR,SP00,A,2024-01-04,,M,US dollar/Euro
+EXR.D.USD.EUR.SP00.A,D,USD,EUR,SP00,A,2024-01-05,1.0921,A,US dollar/Euro
+`
+	monthlySeriesResponse = `KEY,FREQ,CURRENCY,CURRENCY_DENOM,EXR_TYPE,EXR_SUFFIX,TIME_PERIOD,OBS_VALUE,OBS_STATUS,TITLE
+EXR.M.JPY.EUR.SP00.A,M,JPY,EUR,SP00,A,2024-01,159.4547826087,A,Japanese yen/Euro
+EXR.M.JPY.EUR.SP00.A,M,JPY,EUR,SP00,A,2024-02,161.3795,A,Japanese yen/Euro
+`
+	quarterlySeriesResponse = `KEY,FREQ,CURRENCY,CURRENCY_DENOM,EXR_TYPE,EXR_SUFFIX,TIME_PERIOD,OBS_VALUE
+EXR.Q.GBP.EUR.SP00.A,Q,GBP,EUR,SP00,A,2024-Q1,0.85602
+EXR.Q.GBP.EUR.SP00.A,Q,GBP,EUR,SP00,A,2024-Q2,0.85157
+`
+)
+
+func TestSeriesClient_FetchSeries(t *testing.T) {
+	tt := map[string]struct {
+		currency  string
+		frequency Frequency
+		response  string
+		wantPath  string
+		want      []string
+	}{
+		"daily": {
+			currency:  "USD",
+			frequency: Daily,
+			response:  dailySeriesResponse,
+			wantPath:  "/D.USD.EUR.SP00.A",
+			want:      []string{"2024-01-02 1.0956", "2024-01-03 1.0919", "2024-01-05 1.0921"},
+		},
+		"monthly": {
+			currency:  "JPY",
+			frequency: Monthly,
+			response:  monthlySeriesResponse,
+			wantPath:  "/M.JPY.EUR.SP00.A",
+			want:      []string{"2024-01-01 159.454782609", "2024-02-01 161.3795"},
+		},
+		"quarterly": {
+			currency:  "GBP",
+			frequency: Quarterly,
+			response:  quarterlySeriesResponse,
+			wantPath:  "/Q.GBP.EUR.SP00.A",
+			want:      []string{"2024-01-01 0.85602", "2024-04-01 0.85157"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tc.wantPath {
+					t.Errorf("expected path %s, got %s", tc.wantPath, r.URL.Path)
+				}
+
+				query := r.URL.Query()
+				if query.Get("startPeriod") != "2024-01-01" || query.Get("endPeriod") != "2024-06-30" || query.Get("format") != "csvdata" {
+					t.Errorf("unexpected query %s", r.URL.RawQuery)
+				}
+
+				fmt.Fprint(w, tc.response)
+			}))
+			defer ts.Close()
+
+			series := SeriesClient{client: ts.Client(), baseURL: ts.URL}
+
+			got, err := series.FetchSeries(context.Background(), mustParseCurrency(t, tc.currency), tc.frequency,
+				time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("unexpected error, %v", err)
+			}
+
+			if got.Currency != mustParseCurrency(t, tc.currency) || got.Base != mustParseCurrency(t, "EUR") || got.Frequency != tc.frequency {
+				t.Errorf("unexpected series description %v %v %v", got.Base, got.Currency, got.Frequency)
+			}
+
+			if len(got.Observations) != len(tc.want) {
+				t.Fatalf("expected %d observations, got %d", len(tc.want), len(got.Observations))
+			}
+
+			for i, observation := range got.Observations {
+				if s := observation.Period.Format(time.DateOnly) + " " + observation.Rate.String(); s != tc.want[i] {
+					t.Errorf("expected observation %q, got %q", tc.want[i], s)
+				}
+			}
+		})
+	}
+}
+
+func TestSeriesClient_FetchSeries_Errors(t *testing.T) {
+	tt := map[string]struct {
+		status    int
+		response  string
+		frequency Frequency
+		err       error
+	}{
+		"no observations": {status: http.StatusNotFound, frequency: Daily, err: ErrChangeRateNotFound},
+		"server error":    {status: http.StatusInternalServerError, frequency: Daily, err: ErrServerSide},
+		"bad request":     {status: http.StatusBadRequest, frequency: Daily, err: ErrClientSide},
+		"missing columns": {status: http.StatusOK, response: "KEY,FREQ\nEXR,D\n", frequency: Daily, err: ErrUnexpectedFormat},
+		"invalid period":  {status: http.StatusOK, response: "TIME_PERIOD,OBS_VALUE\n2024-13-01,1.2\n", frequency: Daily, err: ErrUnexpectedFormat},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.response)
+			}))
+			defer ts.Close()
+
+			series := SeriesClient{client: ts.Client(), baseURL: ts.URL}
+
+			_, err := series.FetchSeries(context.Background(), mustParseCurrency(t, "USD"), tc.frequency, time.Now(), time.Now())
+			if !errors.Is(err, tc.err) {
+				t.Errorf("unexpected error: %v, expected: %v", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package expr
+
+import "fmt"
+
+// exprError defines a sentinel error.
+type exprError string
+
+// exprError implements the error interface.
+func (e exprError) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrInvalidOperation is returned when operands cannot be combined, such as two amounts multiplied together.
+	ErrInvalidOperation = exprError("invalid operation")
+	// ErrNoAmount is returned when evaluating an expression without any amount, such as 2 * 3.
+	ErrNoAmount = exprError("the expression holds no amount")
+)
+
+// SyntaxError reports where an expression cannot be parsed.
+type SyntaxError struct {
+	// Column is the position of the offending character, in characters, starting at 1.
+	Column int
+	// Msg describes what was expected or found.
+	Msg string
+	// Err is the underlying error, if any, such as money.ErrInvalidCurrencyCode for an unknown currency.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("syntax error at column %d: %s: %s", e.Column, e.Msg, e.Err)
+	}
+	return fmt.Sprintf("syntax error at column %d: %s", e.Column, e.Msg)
+}
+
+// Unwrap returns the underlying error.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
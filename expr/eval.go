@@ -0,0 +1,208 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+	"moneyconverter/money"
+)
+
+// kind is the kind of the value of a node.
+type kind int
+
+const (
+	kindNumber kind = iota
+	// kindPercent values hold the fraction, 0.15 for 15%.
+	kindPercent
+	// kindAmount values are expressed in the target currency.
+	kindAmount
+)
+
+// String implements Stringer.
+func (k kind) String() string {
+	switch k {
+	case kindPercent:
+		return "a percentage"
+	case kindAmount:
+		return "an amount"
+	default:
+		return "a number"
+	}
+}
+
+// value is the exact result of a node.
+type value struct {
+	value *big.Rat
+	kind  kind
+}
+
+// evaluator holds the state of an evaluation.
+type evaluator struct {
+	rates  money.RatesFetcher
+	target money.Currency
+	// fetched holds the rates from each source currency to the target one, fetched once.
+	fetched map[money.Currency]money.ExchangeRate
+}
+
+// rate returns the exchange rate from the currency to the target one.
+func (ev *evaluator) rate(source money.Currency) (money.ExchangeRate, error) {
+	if rate, ok := ev.fetched[source]; ok {
+		return rate, nil
+	}
+
+	rate, err := ev.rates.FetchExchangeRate(source, ev.target)
+	if err != nil {
+		return money.ExchangeRate{}, err
+	}
+
+	ev.fetched[source] = rate
+	return rate, nil
+}
+
+// node is a part of the tree of an expression.
+type node interface {
+	eval(ev *evaluator) (value, error)
+}
+
+// numberNode is a number or a percentage.
+type numberNode struct {
+	value *big.Rat
+	kind  kind
+}
+
+// eval implements node.
+func (n numberNode) eval(*evaluator) (value, error) {
+	return value{value: n.value, kind: n.kind}, nil
+}
+
+// amountNode is an amount of money, converted to the target currency when evaluated.
+type amountNode struct {
+	amount money.Amount
+	column int
+}
+
+// eval implements node.
+func (n amountNode) eval(ev *evaluator) (value, error) {
+	quantity := n.amount.Quantity()
+	if n.amount.Currency() == ev.target {
+		return value{value: quantity.Rat(), kind: kindAmount}, nil
+	}
+
+	rate, err := ev.rate(n.amount.Currency())
+	if err != nil {
+		return value{}, fmt.Errorf("column %d: unable to convert %s to %s: %w", n.column, n.amount, ev.target, err)
+	}
+
+	converted := new(big.Rat).Mul(quantity.Rat(), money.Decimal(rate).Rat())
+	return value{value: converted, kind: kindAmount}, nil
+}
+
+// negateNode is the opposite of its operand.
+type negateNode struct {
+	operand node
+}
+
+// eval implements node.
+func (n negateNode) eval(ev *evaluator) (value, error) {
+	v, err := n.operand.eval(ev)
+	if err != nil {
+		return value{}, err
+	}
+	return value{value: new(big.Rat).Neg(v.value), kind: v.kind}, nil
+}
+
+// binaryNode is an operation between two operands.
+type binaryNode struct {
+	operator    string
+	left, right node
+	column      int
+}
+
+// eval implements node.
+func (n binaryNode) eval(ev *evaluator) (value, error) {
+	left, err := n.left.eval(ev)
+	if err != nil {
+		return value{}, err
+	}
+
+	right, err := n.right.eval(ev)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch n.operator {
+	case "+", "-":
+		return n.sum(left, right)
+	case "*":
+		return n.product(left, right)
+	default:
+		return n.quotient(left, right)
+	}
+}
+
+// sum adds or subtracts the operands. A percentage on the right is applied to the left operand.
+func (n binaryNode) sum(left, right value) (value, error) {
+	switch {
+	case left.kind == right.kind:
+		if n.operator == "-" {
+			return value{value: new(big.Rat).Sub(left.value, right.value), kind: left.kind}, nil
+		}
+		return value{value: new(big.Rat).Add(left.value, right.value), kind: left.kind}, nil
+
+	case right.kind == kindPercent:
+		factor := new(big.Rat).SetInt64(1)
+		if n.operator == "-" {
+			factor.Sub(factor, right.value)
+		} else {
+			factor.Add(factor, right.value)
+		}
+		return value{value: factor.Mul(factor, left.value), kind: left.kind}, nil
+
+	default:
+		verb := "add"
+		if n.operator == "-" {
+			verb = "subtract"
+		}
+		return value{}, n.invalid(fmt.Sprintf("cannot %s %s and %s", verb, left.kind, right.kind))
+	}
+}
+
+// product multiplies the operands, one of which at most being an amount.
+func (n binaryNode) product(left, right value) (value, error) {
+	result := value{value: new(big.Rat).Mul(left.value, right.value), kind: kindNumber}
+
+	switch {
+	case left.kind == kindAmount && right.kind == kindAmount:
+		return value{}, n.invalid("cannot multiply two amounts")
+	case left.kind == kindAmount || right.kind == kindAmount:
+		result.kind = kindAmount
+	case (left.kind == kindPercent) != (right.kind == kindPercent):
+		result.kind = kindPercent
+	}
+
+	return result, nil
+}
+
+// quotient divides the left operand by the right one. Dividing two amounts gives their ratio.
+func (n binaryNode) quotient(left, right value) (value, error) {
+	if right.value.Sign() == 0 {
+		return value{}, fmt.Errorf("column %d: %w", n.column, money.ErrDivisionByZero)
+	}
+
+	result := value{value: new(big.Rat).Quo(left.value, right.value), kind: kindNumber}
+
+	switch {
+	case right.kind == kindAmount && left.kind != kindAmount:
+		return value{}, n.invalid(fmt.Sprintf("cannot divide %s by an amount", left.kind))
+	case left.kind == kindAmount && right.kind != kindAmount:
+		result.kind = kindAmount
+	case left.kind == kindPercent && right.kind == kindNumber:
+		result.kind = kindPercent
+	}
+
+	return result, nil
+}
+
+// invalid returns an ErrInvalidOperation located at the operator.
+func (n binaryNode) invalid(msg string) error {
+	return fmt.Errorf("column %d: %w: %s", n.column, ErrInvalidOperation, msg)
+}
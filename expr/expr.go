@@ -0,0 +1,154 @@
+// Package expr evaluates arithmetic expressions over amounts of several currencies,
+// such as 120 USD + 80 GBP - 15% in EUR or (3 * 19.99 CHF) / 2 to JPY.
+//
+// Every amount is converted to the currency of the result before being combined, and the computation is exact:
+// the result is only rounded once, to the precision of its currency.
+//
+// The grammar, from the lowest to the highest precedence:
+//
+//	expression = sum [ ( "to" | "in" | "into" | "as" | "→" | "->" | "=>" ) currency ]
+//	sum        = product { ( "+" | "-" ) product }
+//	product    = unary { ( "*" | "/" | "×" | "÷" ) unary }
+//	unary      = { "+" | "-" } primary
+//	primary    = number [ magnitude ] [ "%" | currency ] | currency number [ magnitude ] | "(" sum ")"
+//
+// Adding or subtracting a percentage applies it to the left operand: 100 EUR - 15% is 85 EUR.
+package expr
+
+import (
+	"fmt"
+	"moneyconverter/money"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed arithmetic expression over amounts.
+type Expression struct {
+	root   node
+	target money.Currency
+	// hasTarget is false for an expression holding no amount nor target currency.
+	hasTarget bool
+}
+
+// Option configures the parsing of an expression.
+type Option func(*parser)
+
+// WithPreferred sets the currencies symbols shared by several of them, such as $, resolve to: the first one they may stand for.
+func WithPreferred(currencies ...money.Currency) Option {
+	return func(p *parser) {
+		p.preferred = currencies
+	}
+}
+
+// WithVariables sets the amounts the expression may refer to by name, such as total in total * 2.
+// Names are made of letters and underscores, and may not be currency codes or symbols.
+func WithVariables(variables map[string]money.Amount) Option {
+	return func(p *parser) {
+		p.variables = variables
+	}
+}
+
+// WithDefaultTarget sets the currency of the result of an expression that doesn't write it,
+// instead of the currency of its first amount.
+func WithDefaultTarget(currency money.Currency) Option {
+	return func(p *parser) {
+		p.defaultTarget = &currency
+	}
+}
+
+// IsVariableName tells whether a name may be given to a variable: it is made of letters and underscores,
+// and is neither a currency code or symbol, nor a keyword or magnitude of the grammar.
+// Three-letter names, which read as currency codes, are never valid.
+func IsVariableName(name string) bool {
+	if name == "" || strings.IndexFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && r != '_' }) >= 0 {
+		return false
+	}
+
+	if _, ok := Magnitude(name); ok || IsTargetKeyword(name) {
+		return false
+	}
+
+	if money.CurrenciesOfSymbol(name) != nil {
+		return false
+	}
+	_, err := money.ParseCurrency(name)
+	return err != nil
+}
+
+// Parse parses an expression. Errors locating the offending part of the input are *SyntaxError.
+func Parse(input string, opts ...Option) (Expression, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return Expression{}, err
+	}
+
+	p := &parser{tokens: tokens}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	root, err := p.expression()
+	if err != nil {
+		return Expression{}, err
+	}
+
+	e := Expression{root: root}
+	switch {
+	case p.defaultTarget != nil:
+		e.target, e.hasTarget = *p.defaultTarget, true
+	case p.first != nil:
+		e.target, e.hasTarget = *p.first, true
+	}
+
+	if t := p.consume(); t.kind == tokenWord && IsTargetKeyword(t.text) {
+		word := p.consume()
+		if word.kind != tokenWord {
+			return Expression{}, unexpected(word, "the currency of the result")
+		}
+		e.target, err = p.currency(word)
+		if err != nil {
+			return Expression{}, err
+		}
+		e.hasTarget = true
+	} else if t.kind != tokenEOF {
+		return Expression{}, unexpected(t, "an operator")
+	}
+
+	if t := p.consume(); t.kind != tokenEOF {
+		return Expression{}, unexpected(t, "the end of the expression")
+	}
+
+	return e, nil
+}
+
+// Target returns the currency of the result: the one written after the expression,
+// or else the default target or the currency of its first amount. It returns false when there is neither.
+func (e Expression) Target() (money.Currency, bool) {
+	return e.target, e.hasTarget
+}
+
+// Evaluate computes the expression, converting every amount to the target currency with the rates,
+// and rounds the result to the precision of the target currency.
+// It may return ErrNoAmount, ErrInvalidOperation, money.ErrDivisionByZero, money.ErrTooLarge or the errors of the rates.
+func (e Expression) Evaluate(rates money.RatesFetcher, rounding money.RoundingMode) (money.Amount, error) {
+	if !e.hasTarget {
+		return money.Amount{}, ErrNoAmount
+	}
+
+	ev := &evaluator{rates: rates, target: e.target, fetched: make(map[money.Currency]money.ExchangeRate)}
+	result, err := e.root.eval(ev)
+	if err != nil {
+		return money.Amount{}, err
+	}
+
+	if result.kind != kindAmount {
+		return money.Amount{}, ErrNoAmount
+	}
+
+	quantity, err := rounding.Round(result.value, e.target.Precision())
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("unable to round the result: %w", err)
+	}
+
+	return money.NewAmount(quantity, e.target)
+}
@@ -0,0 +1,267 @@
+package expr_test
+
+import (
+	"errors"
+	"moneyconverter/expr"
+	"moneyconverter/money"
+	"testing"
+)
+
+// pairRates holds exchange rates by "SOURCE/TARGET" pair.
+type pairRates map[string]string
+
+// FetchExchangeRate implements the rates fetcher interface.
+func (r pairRates) FetchExchangeRate(source, target money.Currency) (money.ExchangeRate, error) {
+	rate, ok := r[source.ISOCode()+"/"+target.ISOCode()]
+	if !ok {
+		return money.ExchangeRate{}, money.ErrNoPath
+	}
+
+	decimal, err := money.ParseDecimal(rate)
+	return money.ExchangeRate(decimal), err
+}
+
+var rates = pairRates{
+	"USD/EUR": "0.9",
+	"GBP/EUR": "1.2",
+	"CHF/JPY": "170.5",
+	"EUR/USD": "1.1",
+	"JPY/EUR": "0.006",
+}
+
+func mustParseCurrency(t *testing.T, code string) money.Currency {
+	t.Helper()
+
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		t.Fatalf("unable to parse currency %q: %s", code, err)
+	}
+	return currency
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	tt := map[string]struct {
+		input     string
+		rounding  money.RoundingMode
+		preferred []string
+		expected  string
+	}{
+		"sum less a percentage": {
+			input:    "120 USD + 80 GBP - 15% in EUR",
+			expected: "173.40 EUR",
+		},
+		"product and quotient": {
+			input:    "(3 * 19.99 CHF) / 2 to JPY",
+			expected: "5112.44 JPY",
+		},
+		"exact until the result": {
+			input:    "100 EUR / 3 * 3",
+			expected: "100.00 EUR",
+		},
+		"rounding of the result": {
+			input:    "10 EUR / 3",
+			rounding: money.RoundUp,
+			expected: "3.34 EUR",
+		},
+		"target defaults to the first amount": {
+			input:    "10 EUR + 10 USD",
+			expected: "19.00 EUR",
+		},
+		"symbols and magnitude": {
+			input:     "$1.5k - 1_000 usd → €",
+			preferred: []string{"USD"},
+			expected:  "450.00 EUR",
+		},
+		"glued magnitude": {
+			input:    "1.5kJPY as EUR",
+			expected: "9.00 EUR",
+		},
+		"precedence and signs": {
+			input:    "-2 * 5 EUR + 30 EUR",
+			expected: "20.00 EUR",
+		},
+		"ratio of amounts": {
+			input:    "100 EUR * (10 USD / 20 EUR)",
+			expected: "45.00 EUR",
+		},
+		"percentage of an amount": {
+			input:    "20% × 50 €",
+			expected: "10.00 EUR",
+		},
+		"thousands separators": {
+			input:    "1,234.50 EUR ÷ 2",
+			expected: "617.25 EUR",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			var preferred []money.Currency
+			for _, code := range tc.preferred {
+				preferred = append(preferred, mustParseCurrency(t, code))
+			}
+
+			e, err := expr.Parse(tc.input, expr.WithPreferred(preferred...))
+			if err != nil {
+				t.Fatalf("unable to parse %q: %s", tc.input, err)
+			}
+
+			got, err := e.Evaluate(rates, tc.rounding)
+			if err != nil {
+				t.Fatalf("unable to evaluate %q: %s", tc.input, err)
+			}
+
+			if got.String() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	tt := map[string]struct {
+		input  string
+		column int
+		err    error
+	}{
+		"missing operand":      {input: "120 USD + * 3", column: 11},
+		"unclosed parenthesis": {input: "(3 * 19.99 CHF", column: 15},
+		"unknown currency":     {input: "10 EUR + 3 euros", column: 12, err: money.ErrInvalidCurrencyCode},
+		"ambiguous symbol":     {input: "$10 to EUR", column: 1, err: money.ErrAmbiguousCurrency},
+		"too precise amount":   {input: "10.001 EUR", column: 1, err: money.ErrTooPrecise},
+		"invalid number":       {input: "1.2.3 EUR", column: 1},
+		"unexpected character": {input: "10 EUR # 2", column: 8},
+		"missing target":       {input: "10 EUR in", column: 10},
+		"trailing tokens":      {input: "10 EUR in USD 3", column: 15},
+		"missing operator":     {input: "10 EUR 3", column: 8},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := expr.Parse(tc.input)
+
+			var syntaxErr *expr.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected a syntax error, got %v", err)
+			}
+			if syntaxErr.Column != tc.column {
+				t.Errorf("expected column %d, got %d: %s", tc.column, syntaxErr.Column, err)
+			}
+			if tc.err != nil && !errors.Is(err, tc.err) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestExpression_Evaluate_Errors(t *testing.T) {
+	tt := map[string]struct {
+		input string
+		err   error
+	}{
+		"two amounts multiplied":  {input: "2 EUR * 3 EUR", err: expr.ErrInvalidOperation},
+		"number added to amount":  {input: "2 EUR + 3", err: expr.ErrInvalidOperation},
+		"number over amount":      {input: "2 / 3 EUR", err: expr.ErrInvalidOperation},
+		"division by zero":        {input: "2 EUR / (1 - 1)", err: money.ErrDivisionByZero},
+		"no amount":               {input: "2 * 3", err: expr.ErrNoAmount},
+		"no amount in the result": {input: "2 EUR / 1 EUR", err: expr.ErrNoAmount},
+		"unknown rate":            {input: "2 EUR + 1 CHF", err: money.ErrNoPath},
+		"too large result":        {input: "999999999 EUR * 1000", err: money.ErrTooLarge},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			e, err := expr.Parse(tc.input)
+			if err != nil {
+				t.Fatalf("unable to parse %q: %s", tc.input, err)
+			}
+
+			_, err = e.Evaluate(rates, money.RoundDown)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestParse_Variables(t *testing.T) {
+	total, err := money.NewAmount(mustParseDecimal(t, "100"), mustParseCurrency(t, "USD"))
+	if err != nil {
+		t.Fatalf("unable to build amount: %s", err)
+	}
+
+	e, err := expr.Parse("total_due * 2 - 20 EUR",
+		expr.WithVariables(map[string]money.Amount{"total_due": total}),
+		expr.WithDefaultTarget(mustParseCurrency(t, "EUR")))
+	if err != nil {
+		t.Fatalf("unable to parse: %s", err)
+	}
+
+	got, err := e.Evaluate(rates, money.RoundDown)
+	if err != nil {
+		t.Fatalf("unable to evaluate: %s", err)
+	}
+
+	if got.String() != "160.00 EUR" {
+		t.Errorf("expected 160.00 EUR, got %s", got)
+	}
+}
+
+func TestIsVariableName(t *testing.T) {
+	tt := map[string]bool{
+		"total":     true,
+		"total_due": true,
+		"a":         true,
+		"usd":       false,
+		"tot":       false,
+		"kr":        false,
+		"k":         false,
+		"in":        false,
+		"total2":    false,
+		"":          false,
+	}
+
+	for name, expected := range tt {
+		if got := expr.IsVariableName(name); got != expected {
+			t.Errorf("expected %t for %q, got %t", expected, name, got)
+		}
+	}
+}
+
+func TestSplitMagnitude(t *testing.T) {
+	tt := map[string]struct {
+		zeros    int
+		currency string
+		ok       bool
+	}{
+		"kJPY": {zeros: 3, currency: "JPY", ok: true},
+		"BN€":  {zeros: 9, currency: "€", ok: true},
+		"JPY":  {},
+		"k":    {},
+		"km":   {},
+	}
+
+	for word, expected := range tt {
+		zeros, currency, ok := expr.SplitMagnitude(word)
+		if zeros != expected.zeros || currency != expected.currency || ok != expected.ok {
+			t.Errorf("expected %d, %q, %t for %q, got %d, %q, %t", expected.zeros, expected.currency, expected.ok, word, zeros, currency, ok)
+		}
+	}
+
+	if zeros, ok := expr.Magnitude("Mn"); zeros != 6 || !ok {
+		t.Errorf("expected 6 zeros for Mn, got %d, %t", zeros, ok)
+	}
+	if !expr.IsTargetKeyword("INTO") || expr.IsTargetKeyword("of") {
+		t.Errorf("expected INTO to be a target keyword, and of not")
+	}
+}
+
+func mustParseDecimal(t *testing.T, value string) money.Decimal {
+	t.Helper()
+
+	decimal, err := money.ParseDecimal(value)
+	if err != nil {
+		t.Fatalf("unable to parse decimal %q: %s", value, err)
+	}
+	return decimal
+}
@@ -0,0 +1,124 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of a token of an expression.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	// tokenNumber is a decimal number, such as 12,500.50.
+	tokenNumber
+	// tokenWord is a currency code or symbol, a magnitude such as k, a keyword such as to, or the name of a variable.
+	tokenWord
+	// tokenOperator is one of + - * / × ÷.
+	tokenOperator
+	tokenPercent
+	tokenLeftParen
+	tokenRightParen
+)
+
+// token is a lexical unit of an expression.
+type token struct {
+	kind tokenKind
+	text string
+	// number is the value of a tokenNumber.
+	number *big.Rat
+	// column is the position of the first character of the token, starting at 1.
+	column int
+	// glued tells whether the token directly follows the previous one, without spaces.
+	glued bool
+}
+
+// tokenize splits an expression into tokens, the last one being tokenEOF.
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	glued := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			glued = false
+			continue
+
+		case isDigit(r) || (r == '.' && i+1 < len(runes) && isDigit(runes[i+1])):
+			for i < len(runes) && (isDigit(runes[i]) || runes[i] == '.' || runes[i] == ',' || runes[i] == '_') {
+				i++
+			}
+			text := string(runes[start:i])
+			number, ok := parseNumber(text)
+			if !ok {
+				return nil, &SyntaxError{Column: start + 1, Msg: fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, number: number, column: start + 1, glued: glued})
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '>', r == '=' && i+1 < len(runes) && runes[i+1] == '>':
+			i += 2
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), column: start + 1, glued: glued})
+
+		case r == '→':
+			i++
+			tokens = append(tokens, token{kind: tokenWord, text: "→", column: start + 1, glued: glued})
+
+		case strings.ContainsRune("+-*/×÷", r):
+			i++
+			tokens = append(tokens, token{kind: tokenOperator, text: string(r), column: start + 1, glued: glued})
+
+		case r == '%':
+			i++
+			tokens = append(tokens, token{kind: tokenPercent, text: "%", column: start + 1, glued: glued})
+
+		case r == '(':
+			i++
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", column: start + 1, glued: glued})
+
+		case r == ')':
+			i++
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", column: start + 1, glued: glued})
+
+		case isWordRune(r):
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), column: start + 1, glued: glued})
+
+		default:
+			return nil, &SyntaxError{Column: start + 1, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+
+		glued = true
+	}
+
+	return append(tokens, token{kind: tokenEOF, column: len(runes) + 1}), nil
+}
+
+// isDigit tells whether the rune is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isWordRune tells whether the rune may belong to a currency code or symbol, such as USD, US$, € or zł,
+// or to the name of a variable.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.Is(unicode.Sc, r) || r == '_'
+}
+
+// parseNumber parses a decimal number whose thousands may be separated by commas or underscores.
+func parseNumber(text string) (*big.Rat, bool) {
+	cleaned := strings.NewReplacer(",", "", "_", "").Replace(text)
+	if strings.Count(cleaned, ".") > 1 || strings.HasSuffix(cleaned, ".") {
+		return nil, false
+	}
+
+	return new(big.Rat).SetString(cleaned)
+}
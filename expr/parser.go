@@ -0,0 +1,266 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+	"moneyconverter/money"
+	"strings"
+)
+
+// targetKeywords separate an expression from the currency of its result.
+var targetKeywords = []string{"to", "in", "into", "as", "→", "->", "=>"}
+
+// magnitudes are the suffixes multiplying a number, as a number of zeros.
+var magnitudes = map[string]int{"k": 3, "m": 6, "mn": 6, "bn": 9}
+
+// Magnitude returns the number of zeros a suffix such as k or bn multiplies a number by, whatever its case.
+func Magnitude(suffix string) (int, bool) {
+	zeros, ok := magnitudes[strings.ToLower(suffix)]
+	return zeros, ok
+}
+
+// parser builds the tree of an expression from its tokens, by recursive descent.
+type parser struct {
+	tokens    []token
+	next      int
+	preferred []money.Currency
+	variables map[string]money.Amount
+	// defaultTarget is the currency of the result when the expression doesn't write it, if set.
+	defaultTarget *money.Currency
+	// first is the currency of the first amount of the expression, its default target.
+	first *money.Currency
+}
+
+// peek returns the next token without consuming it.
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+// consume returns the next token and moves past it.
+func (p *parser) consume() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+// expression parses terms added or subtracted together.
+func (p *parser) expression() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t.kind == tokenOperator && (t.text == "+" || t.text == "-"); t = p.peek() {
+		p.consume()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{operator: t.text, left: left, right: right, column: t.column}
+	}
+
+	return left, nil
+}
+
+// term parses factors multiplied or divided together.
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t.kind == tokenOperator && strings.Contains("*/×÷", t.text); t = p.peek() {
+		p.consume()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		operator := t.text
+		switch operator {
+		case "×":
+			operator = "*"
+		case "÷":
+			operator = "/"
+		}
+		left = binaryNode{operator: operator, left: left, right: right, column: t.column}
+	}
+
+	return left, nil
+}
+
+// unary parses a factor preceded by any number of signs.
+func (p *parser) unary() (node, error) {
+	t := p.peek()
+	if t.kind == tokenOperator && (t.text == "-" || t.text == "+") {
+		p.consume()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if t.text == "+" {
+			return operand, nil
+		}
+		return negateNode{operand: operand}, nil
+	}
+
+	return p.primary()
+}
+
+// primary parses a number, a percentage, an amount or a parenthesised expression.
+func (p *parser) primary() (node, error) {
+	t := p.consume()
+
+	switch t.kind {
+	case tokenLeftParen:
+		inner, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.consume(); closing.kind != tokenRightParen {
+			return nil, unexpected(closing, "a closing parenthesis")
+		}
+		return inner, nil
+
+	case tokenWord:
+		if IsTargetKeyword(t.text) {
+			return nil, unexpected(t, "a number or an amount")
+		}
+		if amount, ok := p.variables[t.text]; ok {
+			if p.first == nil {
+				currency := amount.Currency()
+				p.first = &currency
+			}
+			return amountNode{amount: amount, column: t.column}, nil
+		}
+		// a currency written before the number, as in $100 or USD 100.
+		currency, err := p.currency(t)
+		if err != nil {
+			return nil, err
+		}
+		number := p.consume()
+		if number.kind != tokenNumber {
+			return nil, unexpected(number, fmt.Sprintf("a number after %q", t.text))
+		}
+		quantity := p.magnitude(number.number)
+		return p.amount(quantity, currency, t.column)
+
+	case tokenNumber:
+		quantity := p.magnitude(t.number)
+
+		next := p.peek()
+		switch {
+		case next.kind == tokenPercent:
+			p.consume()
+			return numberNode{value: new(big.Rat).Quo(quantity, big.NewRat(100, 1)), kind: kindPercent}, nil
+		case next.kind == tokenWord && !IsTargetKeyword(next.text):
+			p.consume()
+			word := next.text
+			if _, err := money.ParseCurrencySymbol(word, p.preferred...); err != nil {
+				// the magnitude may be glued to the currency, as in 1.5kJPY.
+				if zeros, rest, ok := SplitMagnitude(word); ok && next.glued {
+					quantity = shift(quantity, zeros)
+					word = rest
+					next.column += len([]rune(next.text)) - len([]rune(rest))
+				}
+			}
+			next.text = word
+			currency, err := p.currency(next)
+			if err != nil {
+				return nil, err
+			}
+			return p.amount(quantity, currency, t.column)
+		default:
+			return numberNode{value: quantity, kind: kindNumber}, nil
+		}
+
+	default:
+		return nil, unexpected(t, "a number or an amount")
+	}
+}
+
+// magnitude consumes the magnitude following a number, such as the k of 1.5k, and returns the scaled number.
+func (p *parser) magnitude(number *big.Rat) *big.Rat {
+	t := p.peek()
+	if t.kind != tokenWord {
+		return number
+	}
+
+	zeros, ok := Magnitude(t.text)
+	if !ok {
+		return number
+	}
+
+	p.consume()
+	return shift(number, zeros)
+}
+
+// currency resolves the currency code or symbol of a token.
+func (p *parser) currency(t token) (money.Currency, error) {
+	currency, err := money.ParseCurrencySymbol(t.text, p.preferred...)
+	if err != nil {
+		return money.Currency{}, &SyntaxError{Column: t.column, Msg: fmt.Sprintf("unknown currency %q", t.text), Err: err}
+	}
+	return currency, nil
+}
+
+// amount returns the node of an amount, checking it is valid in its currency.
+func (p *parser) amount(quantity *big.Rat, currency money.Currency, column int) (node, error) {
+	decimal, err := money.RoundDown.Round(quantity, currency.Precision())
+	if err == nil && decimal.Rat().Cmp(quantity) != 0 {
+		err = money.ErrTooPrecise
+	}
+
+	var amount money.Amount
+	if err == nil {
+		amount, err = money.NewAmount(decimal, currency)
+	}
+	if err != nil {
+		return nil, &SyntaxError{Column: column, Msg: fmt.Sprintf("invalid amount of %s", currency), Err: err}
+	}
+
+	if p.first == nil {
+		p.first = &currency
+	}
+
+	return amountNode{amount: amount, column: column}, nil
+}
+
+// unexpected returns the error of a token found instead of the expected one.
+func unexpected(t token, expected string) error {
+	if t.kind == tokenEOF {
+		return &SyntaxError{Column: t.column, Msg: fmt.Sprintf("expected %s, found the end of the expression", expected)}
+	}
+	return &SyntaxError{Column: t.column, Msg: fmt.Sprintf("expected %s, found %q", expected, t.text)}
+}
+
+// IsTargetKeyword tells whether a word introduces the currency of the result, such as to, in or →.
+func IsTargetKeyword(word string) bool {
+	for _, keyword := range targetKeywords {
+		if strings.EqualFold(word, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitMagnitude splits a magnitude glued to a currency, such as kJPY, into its number of zeros and the currency.
+// It reports false when the word doesn't start with a magnitude followed by a currency code or symbol.
+func SplitMagnitude(word string) (int, string, bool) {
+	for suffix, zeros := range magnitudes {
+		if len(word) > len(suffix) && strings.EqualFold(word[:len(suffix)], suffix) {
+			if _, err := money.ParseCurrencySymbol(word[len(suffix):]); err == nil {
+				return zeros, word[len(suffix):], true
+			}
+		}
+	}
+	return 0, "", false
+}
+
+// shift returns the number multiplied by 10^zeros.
+func shift(number *big.Rat, zeros int) *big.Rat {
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(zeros)), nil)
+	return new(big.Rat).Mul(number, new(big.Rat).SetInt(factor))
+}
@@ -0,0 +1,263 @@
+// Package metrics measures counters, gauges and histograms, and exposes them in the text format of Prometheus.
+//
+// Only what the money converter needs is implemented, so as not to depend on the Prometheus client library.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentType is the media type of the text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metric is a family of samples sharing a name, written in the exposition format.
+type metric interface {
+	write(w io.Writer) error
+}
+
+// Registry holds metrics and exposes them, in the order they were registered.
+// It is safe for concurrent use, as are the metrics it returns.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// register adds a metric to the registry.
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteTo writes every metric of the registry in the text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		if err := m.write(&buf); err != nil {
+			return 0, err
+		}
+	}
+
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP implements http.Handler, answering with every metric of the registry.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	_, _ = r.WriteTo(w)
+}
+
+// Counter is a value that only goes up.
+type Counter struct {
+	name, help string
+	mu         sync.Mutex
+	value      float64
+}
+
+// Counter registers and returns a counter.
+func (r *Registry) Counter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	r.register(c)
+	return c
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds a positive delta to the counter, ignoring negative ones.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value += delta
+}
+
+func (c *Counter) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+	_, err := fmt.Fprintf(w, "%s %s\n", c.name, formatValue(c.value))
+	return err
+}
+
+// CounterVec is a family of counters told apart by the values of their labels.
+type CounterVec struct {
+	name, help string
+	labels     []string
+	mu         sync.Mutex
+	values     map[string]float64
+}
+
+// CounterVec registers and returns a family of counters with the given label names.
+func (r *Registry) CounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+	r.register(c)
+	return c
+}
+
+// Inc adds one to the counter with the given label values, in the order of the label names.
+// Missing values are left empty, extra ones are ignored.
+func (c *CounterVec) Inc(values ...string) {
+	key := formatLabels(c.labels, values)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key]++
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, key, formatValue(c.values[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Histogram counts observations in buckets of increasing upper bounds.
+type Histogram struct {
+	name, help string
+	bounds     []float64
+	mu         sync.Mutex
+	// counts holds the number of observations of each bucket, not cumulated, the last one being +Inf.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// DefaultDurationBuckets are bounds, in seconds, suited to the duration of calls to a remote server.
+var DefaultDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram registers and returns a histogram with the given upper bounds of its buckets, sorted and deduplicated.
+func (r *Registry) Histogram(name, help string, bounds []float64) *Histogram {
+	bounds = slices.Clone(bounds)
+	slices.Sort(bounds)
+	bounds = slices.Compact(bounds)
+
+	h := &Histogram{name: name, help: help, bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+	r.register(h)
+	return h
+}
+
+// Observe records a value.
+func (h *Histogram) Observe(value float64) {
+	i, _ := slices.BinarySearch(h.bounds, value)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.sum += value
+	h.count++
+}
+
+// ObserveDuration records a duration, in seconds.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+	var cumulated uint64
+	for i, bound := range h.bounds {
+		cumulated += h.counts[i]
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", h.name, formatValue(bound), cumulated); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %s\n%s_count %d\n",
+		h.name, h.count, h.name, formatValue(h.sum), h.name, h.count)
+	return err
+}
+
+// gaugeFunc is a gauge whose value is computed when exposed.
+type gaugeFunc struct {
+	name, help string
+	value      func() float64
+}
+
+// GaugeFunc registers a gauge whose value is computed by calling value each time the metrics are exposed.
+func (r *Registry) GaugeFunc(name, help string, value func() float64) {
+	r.register(&gaugeFunc{name: name, help: help, value: value})
+}
+
+func (g *gaugeFunc) write(w io.Writer) error {
+	writeHeader(w, g.name, g.help, "gauge")
+	_, err := fmt.Fprintf(w, "%s %s\n", g.name, formatValue(g.value()))
+	return err
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric.
+func writeHeader(w io.Writer, name, help, kind string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// formatLabels writes label pairs such as {from="USD",to="EUR"}.
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	escape := strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = name + `="` + escape.Replace(value) + `"`
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatValue writes a sample value as the exposition format expects it.
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,88 @@
+package metrics_test
+
+import (
+	"io"
+	"moneyconverter/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	hits := registry.Counter("cache_hits_total", "Feeds read from the cache.")
+	conversions := registry.CounterVec("conversions_total", "Conversions by pair.", "from", "to")
+	latency := registry.Histogram("fetch_duration_seconds", "Latency of the fetches.", []float64{1, 0.5, 1})
+	registry.GaugeFunc("age_seconds", "Age of the rates.", func() float64 { return 90 })
+
+	hits.Inc()
+	hits.Add(2)
+	hits.Add(-5)
+	conversions.Inc("USD", "EUR")
+	conversions.Inc("USD", "EUR")
+	conversions.Inc("EUR", "RON")
+	conversions.Inc(`a"b`)
+	latency.Observe(0.2)
+	latency.Observe(0.5)
+	latency.ObserveDuration(3 * time.Second)
+
+	var got strings.Builder
+	if _, err := registry.WriteTo(&got); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	want := `# HELP cache_hits_total Feeds read from the cache.
+# TYPE cache_hits_total counter
+cache_hits_total 3
+# HELP conversions_total Conversions by pair.
+# TYPE conversions_total counter
+conversions_total{from="EUR",to="RON"} 1
+conversions_total{from="USD",to="EUR"} 2
+conversions_total{from="a\"b",to=""} 1
+# HELP fetch_duration_seconds Latency of the fetches.
+# TYPE fetch_duration_seconds histogram
+fetch_duration_seconds_bucket{le="0.5"} 2
+fetch_duration_seconds_bucket{le="1"} 2
+fetch_duration_seconds_bucket{le="+Inf"} 3
+fetch_duration_seconds_sum 3.7
+fetch_duration_seconds_count 3
+# HELP age_seconds Age of the rates.
+# TYPE age_seconds gauge
+age_seconds 90
+`
+	if got.String() != want {
+		t.Errorf("WriteTo wrote\n%s\nwant\n%s", got.String(), want)
+	}
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	registry := metrics.NewRegistry()
+	counter := registry.Counter("requests_total", "Requests.")
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counter.Inc()
+		}()
+	}
+	wg.Wait()
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	resp := recorder.Result()
+	if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type is %q", got)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "requests_total 100\n") {
+		t.Errorf("body is\n%s", body)
+	}
+}
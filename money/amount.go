@@ -37,6 +37,16 @@ func (a Amount) validate() error {
 	return nil
 }
 
+// Quantity returns the quantity of money of the Amount.
+func (a Amount) Quantity() Decimal {
+	return a.quantity
+}
+
+// Currency returns the currency of the Amount.
+func (a Amount) Currency() Currency {
+	return a.currency
+}
+
 // String implements stringer.
 func (a Amount) String() string {
 	return a.quantity.String() + " " + a.currency.code
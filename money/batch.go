@@ -0,0 +1,175 @@
+package money
+
+import (
+	"fmt"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+// streamChunkSize is the number of amounts ConvertStream reads before converting them in parallel.
+const streamChunkSize = 1024
+
+// BatchResult is the outcome of the conversion of one amount of a batch.
+type BatchResult struct {
+	// Index is the position of the amount within the batch.
+	Index int
+	// Source is the amount to convert.
+	Source Amount
+	// Amount is the converted amount, unset if Err isn't nil.
+	Amount Amount
+	// Rate is the exchange rate applied, along with where it comes from.
+	Rate RateInfo
+	Err  error
+}
+
+// BatchOption configures a batch conversion.
+type BatchOption func(*batchConfig)
+
+// batchConfig holds the settings of a batch conversion.
+type batchConfig struct {
+	workers  int
+	rounding RoundingMode
+}
+
+// WithWorkers sets how many conversions, or fetches of exchange rates, run at the same time.
+// It defaults to the number of CPUs usable by the program.
+func WithWorkers(workers int) BatchOption {
+	return func(c *batchConfig) {
+		c.workers = workers
+	}
+}
+
+// WithRounding sets how the converted amounts are rounded. They are truncated by default, as by Convert.
+func WithRounding(rounding RoundingMode) BatchOption {
+	return func(c *batchConfig) {
+		c.rounding = rounding
+	}
+}
+
+// newBatchConfig applies the options over the defaults.
+func newBatchConfig(opts []BatchOption) batchConfig {
+	config := batchConfig{workers: runtime.GOMAXPROCS(0), rounding: RoundDown}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	config.workers = max(config.workers, 1)
+	return config
+}
+
+// ConvertBatch converts every amount to the target currency, and returns one result per amount, in the same order.
+// The exchange rate of each distinct source currency is fetched once, and the conversions run in parallel:
+// rates must be safe for concurrent use. A failing amount doesn't fail the others, its result holds the error.
+func ConvertBatch(amounts []Amount, to Currency, rates RatesFetcher, opts ...BatchOption) []BatchResult {
+	config := newBatchConfig(opts)
+	known := make(map[Currency]pairRate)
+
+	return convertChunk(amounts, 0, to, rates, known, config)
+}
+
+// ConvertStream converts the amounts of a sequence as they come, yielding their results in order.
+// It reads the sequence by chunks, converting each chunk like ConvertBatch: the exchange rate of each
+// distinct source currency is fetched once for the whole sequence.
+func ConvertStream(amounts iter.Seq[Amount], to Currency, rates RatesFetcher, opts ...BatchOption) iter.Seq[BatchResult] {
+	return func(yield func(BatchResult) bool) {
+		config := newBatchConfig(opts)
+		known := make(map[Currency]pairRate)
+
+		chunk := make([]Amount, 0, streamChunkSize)
+		offset := 0
+
+		// flush converts the chunk and yields its results, it returns false when the consumer stops.
+		flush := func() bool {
+			for _, result := range convertChunk(chunk, offset, to, rates, known, config) {
+				if !yield(result) {
+					return false
+				}
+			}
+			offset += len(chunk)
+			chunk = chunk[:0]
+			return true
+		}
+
+		for amount := range amounts {
+			chunk = append(chunk, amount)
+			if len(chunk) == streamChunkSize && !flush() {
+				return
+			}
+		}
+
+		if len(chunk) > 0 {
+			flush()
+		}
+	}
+}
+
+// pairRate is the exchange rate from a source currency to the target of a batch, or why it couldn't be fetched.
+type pairRate struct {
+	info RateInfo
+	err  error
+}
+
+// convertChunk converts the amounts, whose indices start at offset.
+// The rates missing from known are fetched and added to it.
+func convertChunk(amounts []Amount, offset int, to Currency, rates RatesFetcher, known map[Currency]pairRate, config batchConfig) []BatchResult {
+	var missing []Currency
+	for _, amount := range amounts {
+		if _, ok := known[amount.currency]; !ok {
+			known[amount.currency] = pairRate{}
+			missing = append(missing, amount.currency)
+		}
+	}
+
+	fetched := make([]pairRate, len(missing))
+	parallel(len(missing), config.workers, func(i int) {
+		info, err := FetchRateInfo(rates, missing[i], to)
+		if err != nil {
+			err = fmt.Errorf("cannot get exchange rate: %w", err)
+		}
+		fetched[i] = pairRate{info: info, err: err}
+	})
+
+	for i, currency := range missing {
+		known[currency] = fetched[i]
+	}
+
+	results := make([]BatchResult, len(amounts))
+	parallel(len(amounts), config.workers, func(i int) {
+		results[i] = BatchResult{Index: offset + i, Source: amounts[i]}
+
+		pair := known[amounts[i].currency]
+		if pair.err != nil {
+			results[i].Err = pair.err
+			return
+		}
+
+		results[i].Rate = pair.info
+		results[i].Amount, _, results[i].Err = roundExchange(amounts[i], to, pair.info.Rate, config.rounding)
+	})
+
+	return results
+}
+
+// parallel calls fn with every index from 0 to n excluded, on up to workers goroutines, and waits for them.
+func parallel(n, workers int, fn func(i int)) {
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(n, workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := range n {
+		indices <- i
+	}
+	close(indices)
+
+	wg.Wait()
+}
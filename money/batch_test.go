@@ -0,0 +1,122 @@
+package money_test
+
+import (
+	"errors"
+	"moneyconverter/money"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// countingRates is a stub counting how many times each pair is fetched, safe for concurrent use.
+type countingRates struct {
+	rates pairRates
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// FetchExchangeRate implements the interface RatesFetcher.
+func (c *countingRates) FetchExchangeRate(source, target money.Currency) (money.ExchangeRate, error) {
+	c.mu.Lock()
+	c.calls[source.ISOCode()+"/"+target.ISOCode()]++
+	c.mu.Unlock()
+
+	return c.rates.FetchExchangeRate(source, target)
+}
+
+func TestConvertBatch(t *testing.T) {
+	rates := &countingRates{rates: pairRates{"USD/EUR": "0.9", "GBP/EUR": "1.2"}, calls: make(map[string]int)}
+	amounts := []money.Amount{
+		mustParseAmount(t, "10", "USD"),
+		mustParseAmount(t, "10", "GBP"),
+		mustParseAmount(t, "10", "CHF"),
+		mustParseAmount(t, "20", "USD"),
+		mustParseAmount(t, "5", "EUR"),
+		mustParseAmount(t, "1.11", "USD"),
+	}
+
+	got := money.ConvertBatch(amounts, mustParseCurrency(t, "EUR"), rates, money.WithWorkers(3))
+
+	want := []string{"9.00 EUR", "12.00 EUR", "", "18.00 EUR", "5.00 EUR", "0.99 EUR"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(got))
+	}
+
+	for i, result := range got {
+		if result.Index != i || result.Source != amounts[i] {
+			t.Errorf("result %d: expected index %d of %s, got index %d of %s", i, i, amounts[i], result.Index, result.Source)
+		}
+
+		if want[i] == "" {
+			if result.Err == nil {
+				t.Errorf("result %d: expected an error, got %s", i, result.Amount)
+			}
+			continue
+		}
+
+		if result.Err != nil {
+			t.Errorf("result %d: expected no error, got %s", i, result.Err.Error())
+		} else if result.Amount.String() != want[i] {
+			t.Errorf("result %d: expected %s, got %s", i, want[i], result.Amount)
+		}
+	}
+
+	for pair, calls := range rates.calls {
+		if calls != 1 {
+			t.Errorf("expected %s to be fetched once, got %d", pair, calls)
+		}
+	}
+}
+
+func TestConvertBatch_Rounding(t *testing.T) {
+	amounts := []money.Amount{mustParseAmount(t, "1.11", "USD")}
+
+	got := money.ConvertBatch(amounts, mustParseCurrency(t, "EUR"), stubRate{rate: "0.9"}, money.WithRounding(money.RoundHalfUp))
+
+	if got[0].Err != nil || got[0].Amount.String() != "1.00 EUR" {
+		t.Errorf("expected 1.00 EUR, got %s (%v)", got[0].Amount, got[0].Err)
+	}
+}
+
+func TestConvertStream(t *testing.T) {
+	rates := &countingRates{rates: pairRates{"USD/EUR": "0.5"}, calls: make(map[string]int)}
+
+	// more amounts than a chunk, so that the rate is reused across chunks.
+	amounts := make([]money.Amount, 2500)
+	for i := range amounts {
+		amounts[i] = mustParseAmount(t, "2", "USD")
+	}
+
+	count := 0
+	for result := range money.ConvertStream(slices.Values(amounts), mustParseCurrency(t, "EUR"), rates) {
+		if result.Index != count {
+			t.Fatalf("expected result %d, got %d", count, result.Index)
+		}
+		if result.Err != nil || result.Amount.String() != "1.00 EUR" {
+			t.Fatalf("result %d: expected 1.00 EUR, got %s (%v)", count, result.Amount, result.Err)
+		}
+		count++
+	}
+
+	if count != len(amounts) {
+		t.Errorf("expected %d results, got %d", len(amounts), count)
+	}
+
+	if calls := rates.calls["USD/EUR"]; calls != 1 {
+		t.Errorf("expected USD/EUR to be fetched once, got %d", calls)
+	}
+}
+
+func TestConvertStream_Stop(t *testing.T) {
+	amounts := []money.Amount{mustParseAmount(t, "1", "USD"), mustParseAmount(t, "2", "USD")}
+
+	var got []money.BatchResult
+	for result := range money.ConvertStream(slices.Values(amounts), mustParseCurrency(t, "EUR"), stubRate{err: errors.New("unreachable")}) {
+		got = append(got, result)
+		break
+	}
+
+	if len(got) != 1 || got[0].Err == nil {
+		t.Errorf("expected a single failed result, got %v", got)
+	}
+}
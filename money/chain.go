@@ -0,0 +1,158 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// ErrNoProvider is returned when none of the providers of a Chain could fetch an exchange rate.
+	ErrNoProvider = Error("no provider could fetch the exchange rate")
+	// ErrProviderTimeout is reported when a provider took longer than its timeout to answer.
+	ErrProviderTimeout = Error("provider timed out")
+)
+
+// Provider is a named source of exchange rates within a Chain.
+type Provider struct {
+	// Name identifies the provider in reports.
+	Name string
+	// Rates fetches the exchange rates.
+	Rates RatesFetcher
+	// Timeout is how long to wait for an answer before trying the next provider, 0 waits indefinitely.
+	Timeout time.Duration
+}
+
+// ProviderError is the failure of one provider of a Chain.
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+// Error implements the error interface.
+func (e ProviderError) Error() string {
+	return e.Provider + ": " + e.Err.Error()
+}
+
+// MarshalJSON writes the failure as an object holding the name of the provider and the message of its error.
+func (e ProviderError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Provider string `json:"provider"`
+		Error    string `json:"error"`
+	}{Provider: e.Provider, Error: e.Err.Error()})
+}
+
+// UnmarshalJSON reads a failure written by MarshalJSON, the error keeping only its message.
+func (e *ProviderError) UnmarshalJSON(data []byte) error {
+	var failure struct {
+		Provider string `json:"provider"`
+		Error    string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &failure); err != nil {
+		return err
+	}
+
+	*e = ProviderError{Provider: failure.Provider, Err: errors.New(failure.Error)}
+	return nil
+}
+
+// Unwrap returns the error of the provider.
+func (e ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Resolution reports which provider of a Chain answered, and why the ones before it failed.
+type Resolution struct {
+	Rate     ExchangeRate
+	Provider string
+	Failures []ProviderError
+	// Info is what the provider told about the rate, named after the provider within the chain.
+	Info RateInfo
+}
+
+// Chain fetches exchange rates from several providers in priority order.
+// When a provider returns an error, including when it doesn't know the currency pair, the next one is tried.
+type Chain struct {
+	providers []Provider
+}
+
+// NewChain returns a Chain trying the providers in the given order.
+func NewChain(providers ...Provider) Chain {
+	return Chain{providers: providers}
+}
+
+// FetchExchangeRate returns the ExchangeRate of the first provider able to fetch it.
+func (c Chain) FetchExchangeRate(source, target Currency) (ExchangeRate, error) {
+	resolution, err := c.Resolve(source, target)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+
+	return resolution.Rate, nil
+}
+
+// FetchRateInfo returns the ExchangeRate of the first provider able to fetch it, along with where it comes from.
+func (c Chain) FetchRateInfo(source, target Currency) (RateInfo, error) {
+	resolution, err := c.Resolve(source, target)
+	if err != nil {
+		return RateInfo{}, err
+	}
+
+	return resolution.Info, nil
+}
+
+// Resolve returns the ExchangeRate of the first provider able to fetch it, along with the name of that provider.
+// If every provider fails, the returned error wraps ErrNoProvider and each of their errors.
+func (c Chain) Resolve(source, target Currency) (Resolution, error) {
+	var failures []ProviderError
+
+	for _, p := range c.providers {
+		info, err := p.fetch(source, target)
+		if err != nil {
+			failures = append(failures, ProviderError{Provider: p.Name, Err: err})
+			continue
+		}
+
+		info.Provider = p.Name
+		info.Failures = failures
+		return Resolution{Rate: info.Rate, Provider: p.Name, Failures: failures, Info: info}, nil
+	}
+
+	errs := make([]error, len(failures))
+	for i, failure := range failures {
+		errs[i] = failure
+	}
+
+	return Resolution{Failures: failures}, fmt.Errorf("%w from %s to %s: %w", ErrNoProvider, source, target, errors.Join(errs...))
+}
+
+// fetch calls the provider, giving up after its timeout.
+// A provider that times out keeps running in the background until it returns.
+func (p Provider) fetch(source, target Currency) (RateInfo, error) {
+	if p.Timeout <= 0 {
+		return FetchRateInfo(p.Rates, source, target)
+	}
+
+	type answer struct {
+		info RateInfo
+		err  error
+	}
+
+	// buffered so that a late answer doesn't block the provider forever.
+	answers := make(chan answer, 1)
+	go func() {
+		info, err := FetchRateInfo(p.Rates, source, target)
+		answers <- answer{info: info, err: err}
+	}()
+
+	timer := time.NewTimer(p.Timeout)
+	defer timer.Stop()
+
+	select {
+	case a := <-answers:
+		return a.info, a.err
+	case <-timer.C:
+		return RateInfo{}, fmt.Errorf("%w after %s", ErrProviderTimeout, p.Timeout)
+	}
+}
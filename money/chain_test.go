@@ -0,0 +1,117 @@
+package money_test
+
+import (
+	"errors"
+	"moneyconverter/money"
+	"testing"
+	"time"
+)
+
+// slowRate is a stub taking a while to answer.
+type slowRate struct {
+	delay time.Duration
+}
+
+// FetchExchangeRate implements the interface RatesFetcher, answering a rate of 1 after the delay.
+func (s slowRate) FetchExchangeRate(_, _ money.Currency) (money.ExchangeRate, error) {
+	time.Sleep(s.delay)
+	rate, _ := money.ParseDecimal("1")
+	return money.ExchangeRate(rate), nil
+}
+
+func TestChain_Resolve(t *testing.T) {
+	errNotFound := errors.New("couldn't find the exchange rate")
+
+	tt := map[string]struct {
+		providers    []money.Provider
+		wantRate     string
+		wantProvider string
+		wantFailures int
+		err          error
+	}{
+		"first provider answers": {
+			providers: []money.Provider{
+				{Name: "first", Rates: stubRate{rate: "2"}},
+				{Name: "second", Rates: stubRate{rate: "3"}},
+			},
+			wantRate:     "2",
+			wantProvider: "first",
+		},
+		"falls back on error": {
+			providers: []money.Provider{
+				{Name: "first", Rates: stubRate{err: errNotFound}},
+				{Name: "second", Rates: stubRate{rate: "3"}},
+			},
+			wantRate:     "3",
+			wantProvider: "second",
+			wantFailures: 1,
+		},
+		"falls back on timeout": {
+			providers: []money.Provider{
+				{Name: "slow", Rates: slowRate{delay: time.Second}, Timeout: 10 * time.Millisecond},
+				{Name: "second", Rates: stubRate{rate: "3"}},
+			},
+			wantRate:     "3",
+			wantProvider: "second",
+			wantFailures: 1,
+		},
+		"every provider fails": {
+			providers: []money.Provider{
+				{Name: "first", Rates: stubRate{err: errNotFound}},
+				{Name: "slow", Rates: slowRate{delay: time.Second}, Timeout: 10 * time.Millisecond},
+			},
+			wantFailures: 2,
+			err:          money.ErrNoProvider,
+		},
+		"no provider": {
+			err: money.ErrNoProvider,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			chain := money.NewChain(tc.providers...)
+
+			got, err := chain.Resolve(mustParseCurrency(t, "USD"), mustParseCurrency(t, "EUR"))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if len(got.Failures) != tc.wantFailures {
+				t.Errorf("expected %d failures, got %v", tc.wantFailures, got.Failures)
+			}
+
+			if tc.err != nil {
+				return
+			}
+
+			if got.Provider != tc.wantProvider {
+				t.Errorf("expected provider %s, got %s", tc.wantProvider, got.Provider)
+			}
+
+			if got.Info.Provider != tc.wantProvider || len(got.Info.Failures) != tc.wantFailures {
+				t.Errorf("expected the rate info to report provider %s after %d failures, got %+v", tc.wantProvider, tc.wantFailures, got.Info)
+			}
+
+			if got.Rate.String() != tc.wantRate {
+				t.Errorf("expected rate %s, got %s", tc.wantRate, got.Rate)
+			}
+		})
+	}
+}
+
+func TestChain_Resolve_WrapsProviderErrors(t *testing.T) {
+	errNotFound := errors.New("couldn't find the exchange rate")
+	chain := money.NewChain(money.Provider{Name: "first", Rates: stubRate{err: errNotFound}})
+
+	_, err := chain.FetchExchangeRate(mustParseCurrency(t, "USD"), mustParseCurrency(t, "EUR"))
+
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("expected error %v to wrap %v", err, errNotFound)
+	}
+
+	var providerErr money.ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Provider != "first" {
+		t.Errorf("expected error %v to name the failing provider", err)
+	}
+}
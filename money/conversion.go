@@ -0,0 +1,132 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// RateInfo is an exchange rate along with where it comes from.
+type RateInfo struct {
+	Rate ExchangeRate
+	// Provider names the source of the rate, empty if unknown.
+	Provider string
+	// PublishedAt is when the provider published the rate, zero if unknown.
+	PublishedAt time.Time
+	// FetchedAt is when the rate was obtained from the provider, zero if unknown.
+	FetchedAt time.Time
+	// Stale reports whether the rate is outdated.
+	Stale bool
+	// Path is the chain of rates the rate was computed from, zero if the provider doesn't tell.
+	Path Path
+	// Failures holds the errors of the providers tried before the one that answered, if the rate comes from a Chain.
+	Failures []ProviderError
+}
+
+// rateInfoFetcher is implemented by the providers able to tell where their rates come from.
+type rateInfoFetcher interface {
+	FetchRateInfo(source, target Currency) (RateInfo, error)
+}
+
+// FetchRateInfo returns the exchange rate from source to target, along with where it comes from
+// when the provider can tell. Otherwise, only the Rate is set.
+func FetchRateInfo(rates RatesFetcher, source, target Currency) (RateInfo, error) {
+	if described, ok := rates.(rateInfoFetcher); ok {
+		return described.FetchRateInfo(source, target)
+	}
+
+	rate, err := rates.FetchExchangeRate(source, target)
+	if err != nil {
+		return RateInfo{}, err
+	}
+
+	return RateInfo{Rate: rate}, nil
+}
+
+// Conversion is the audit trail of a conversion: what was converted, at which rate, and what was lost to rounding.
+type Conversion struct {
+	Input  Amount `json:"input"`
+	Output Amount `json:"output"`
+	// Rate converts one unit of the input currency into the output currency.
+	Rate ExchangeRate `json:"rate"`
+	// InverseRate converts one unit of the output currency back into the input currency.
+	InverseRate ExchangeRate `json:"inverse_rate"`
+	Provider    string       `json:"provider,omitempty"`
+	PublishedAt time.Time    `json:"published_at,omitzero"`
+	FetchedAt   time.Time    `json:"fetched_at,omitzero"`
+	Stale       bool         `json:"stale,omitempty"`
+	Rounding    RoundingMode `json:"rounding"`
+	// Path is the chain of rates the rate was computed from, such as BTC→USD→EUR→JPY, so that it can be verified.
+	Path Path `json:"path,omitzero"`
+	// Failures holds the errors of the providers tried before the one that answered.
+	Failures []ProviderError `json:"failures,omitempty"`
+	// Remainder is the exact product of the input and the rate minus the output, in the output currency.
+	// It is positive when rounding dropped some money, and negative when it added some.
+	Remainder Decimal `json:"remainder"`
+}
+
+// Explain converts an amount like Convert, rounding the result with the given mode, and reports how it was done.
+func Explain(amount Amount, to Currency, rates RatesFetcher, rounding RoundingMode) (Conversion, error) {
+	info, err := FetchRateInfo(rates, amount.currency, to)
+	if err != nil {
+		return Conversion{}, fmt.Errorf("cannot get exchange rate: %w", err)
+	}
+
+	output, remainder, err := roundExchange(amount, to, info.Rate, rounding)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	inverse, err := info.Rate.Inverse()
+	if err != nil {
+		return Conversion{}, fmt.Errorf("cannot invert exchange rate: %w", err)
+	}
+
+	return Conversion{
+		Input:       amount,
+		Output:      output,
+		Rate:        info.Rate,
+		InverseRate: inverse,
+		Provider:    info.Provider,
+		PublishedAt: info.PublishedAt,
+		FetchedAt:   info.FetchedAt,
+		Stale:       info.Stale,
+		Rounding:    rounding,
+		Path:        info.Path,
+		Failures:    info.Failures,
+		Remainder:   remainder,
+	}, nil
+}
+
+// roundExchange returns the amount multiplied by the rate, rounded to the precision of the target currency,
+// and the difference between the exact product and the rounded one.
+func roundExchange(a Amount, target Currency, rate ExchangeRate, rounding RoundingMode) (Amount, Decimal, error) {
+	exact := new(big.Int).Mul(big.NewInt(a.quantity.subunits), big.NewInt(rate.subunits))
+	exactPrecision := a.quantity.precision + rate.precision
+
+	var subunits *big.Int
+	if exactPrecision > target.precision {
+		subunits = rounding.quo(exact, bigPow10(int(exactPrecision-target.precision)))
+	} else {
+		subunits = new(big.Int).Mul(exact, bigPow10(int(target.precision-exactPrecision)))
+		exactPrecision = target.precision
+		exact = subunits
+	}
+
+	if subunits.CmpAbs(big.NewInt(maxDecimal)) > 0 {
+		return Amount{}, Decimal{}, ErrTooLarge
+	}
+
+	output := Amount{
+		quantity: Decimal{subunits: subunits.Int64(), precision: target.precision},
+		currency: target,
+	}
+
+	rounded := new(big.Int).Mul(subunits, bigPow10(int(exactPrecision-target.precision)))
+	remainder, err := fitDecimal(rounded.Sub(exact, rounded), exactPrecision)
+	if err != nil {
+		return Amount{}, Decimal{}, err
+	}
+
+	return output, remainder, nil
+}
@@ -0,0 +1,190 @@
+package money_test
+
+import (
+	"encoding/json"
+	"moneyconverter/money"
+	"strings"
+	"testing"
+	"time"
+)
+
+// describedRate is a stub returning the same rate for every pair, along with where it comes from.
+type describedRate struct {
+	rate      string
+	published time.Time
+}
+
+// FetchExchangeRate implements the interface RatesFetcher.
+func (d describedRate) FetchExchangeRate(_, _ money.Currency) (money.ExchangeRate, error) {
+	rate, err := money.ParseDecimal(d.rate)
+	return money.ExchangeRate(rate), err
+}
+
+// FetchRateInfo implements the interface rateInfoFetcher.
+func (d describedRate) FetchRateInfo(source, target money.Currency) (money.RateInfo, error) {
+	rate, err := d.FetchExchangeRate(source, target)
+	return money.RateInfo{Rate: rate, Provider: "stub", PublishedAt: d.published}, err
+}
+
+func TestExplain(t *testing.T) {
+	tt := map[string]struct {
+		amount        money.Amount
+		rate          string
+		rounding      money.RoundingMode
+		wantOutput    string
+		wantRemainder string
+	}{
+		"truncated like Convert": {
+			amount:        mustParseAmount(t, "10.00", "USD"),
+			rate:          "0.91376",
+			rounding:      money.RoundDown,
+			wantOutput:    "9.13 EUR",
+			wantRemainder: "0.0076",
+		},
+		"rounded half up": {
+			amount:        mustParseAmount(t, "10.00", "USD"),
+			rate:          "0.91376",
+			rounding:      money.RoundHalfUp,
+			wantOutput:    "9.14 EUR",
+			wantRemainder: "-0.0024",
+		},
+		"half rounded to even": {
+			amount:        mustParseAmount(t, "1.00", "USD"),
+			rate:          "0.125",
+			rounding:      money.RoundHalfEven,
+			wantOutput:    "0.12 EUR",
+			wantRemainder: "0.005",
+		},
+		"rounded up": {
+			amount:        mustParseAmount(t, "1.00", "USD"),
+			rate:          "0.1201",
+			rounding:      money.RoundUp,
+			wantOutput:    "0.13 EUR",
+			wantRemainder: "-0.0099",
+		},
+		"exact conversion": {
+			amount:        mustParseAmount(t, "2.50", "USD"),
+			rate:          "2",
+			rounding:      money.RoundHalfUp,
+			wantOutput:    "5.00 EUR",
+			wantRemainder: "0",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := money.Explain(tc.amount, mustParseCurrency(t, "EUR"), stubRate{rate: tc.rate}, tc.rounding)
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			if got.Output.String() != tc.wantOutput {
+				t.Errorf("expected output %s, got %s", tc.wantOutput, got.Output)
+			}
+			if got.Remainder.String() != tc.wantRemainder {
+				t.Errorf("expected remainder %s, got %s", tc.wantRemainder, got.Remainder.String())
+			}
+			if got.Rounding != tc.rounding {
+				t.Errorf("expected rounding %s, got %s", tc.rounding, got.Rounding)
+			}
+		})
+	}
+}
+
+func TestExplain_RateInfo(t *testing.T) {
+	published := mustParseDate(t, "2025-04-08")
+	rates := describedRate{rate: "0.8", published: published}
+
+	got, err := money.Explain(mustParseAmount(t, "100", "USD"), mustParseCurrency(t, "EUR"), rates, money.RoundDown)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if got.Provider != "stub" || !got.PublishedAt.Equal(published) {
+		t.Errorf("expected rate of stub published on 2025-04-08, got %q on %s", got.Provider, got.PublishedAt)
+	}
+	if got.InverseRate.String() != "1.25" {
+		t.Errorf("expected inverse rate 1.25, got %s", got.InverseRate)
+	}
+}
+
+func TestConversion_JSON(t *testing.T) {
+	rates := describedRate{rate: "0.8", published: mustParseDate(t, "2025-04-08")}
+
+	conversion, err := money.Explain(mustParseAmount(t, "10.01", "USD"), mustParseCurrency(t, "EUR"), rates, money.RoundHalfEven)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	data, err := json.Marshal(conversion)
+	if err != nil {
+		t.Fatalf("cannot marshal conversion: %v", err)
+	}
+
+	want := `{"input":{"quantity":"10.01","currency":"USD"},"output":{"quantity":"8.01","currency":"EUR"},` +
+		`"rate":"0.8","inverse_rate":"1.25","provider":"stub","published_at":"2025-04-08T00:00:00Z",` +
+		`"rounding":"half-even","remainder":"-0.002"}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+
+	var decoded money.Conversion
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("cannot unmarshal conversion: %v", err)
+	}
+	if decoded.Output != conversion.Output || decoded.Rounding != conversion.Rounding {
+		t.Errorf("expected %v, got %v", conversion, decoded)
+	}
+}
+
+func TestConversion_JSON_PathAndFailures(t *testing.T) {
+	var graph money.RateGraph
+	addRate(t, &graph, "BTC", "USD", "60000", "crypto")
+	addRate(t, &graph, "EUR", "USD", "1.25", "ecb")
+
+	chain := money.NewChain(
+		money.Provider{Name: "first", Rates: stubRate{err: money.ErrNoPath}},
+		money.Provider{Name: "graph", Rates: &graph},
+	)
+
+	conversion, err := money.Explain(mustParseAmount(t, "1", "BTC"), mustParseCurrency(t, "EUR"), chain, money.RoundDown)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if conversion.Path.String() != "BTC→USD→EUR" || conversion.Output.String() != "48000.00 EUR" {
+		t.Errorf("expected 48000.00 EUR through BTC→USD→EUR, got %s through %s", conversion.Output, conversion.Path)
+	}
+
+	data, err := json.Marshal(conversion)
+	if err != nil {
+		t.Fatalf("cannot marshal conversion: %v", err)
+	}
+
+	for _, want := range []string{`"path":"BTC→USD→EUR"`, `"failures":[{"provider":"first","error":"no exchange path between the currencies"}]`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s to contain %s", data, want)
+		}
+	}
+
+	var decoded money.Conversion
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("cannot unmarshal conversion: %v", err)
+	}
+	if decoded.Path.String() != "BTC→USD→EUR" || len(decoded.Failures) != 1 || decoded.Failures[0].Error() != "first: no exchange path between the currencies" {
+		t.Errorf("expected the path and the failure back, got %s and %v", decoded.Path, decoded.Failures)
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for _, mode := range []money.RoundingMode{money.RoundDown, money.RoundHalfUp, money.RoundHalfEven, money.RoundUp} {
+		got, err := money.ParseRoundingMode(mode.String())
+		if err != nil || got != mode {
+			t.Errorf("expected %s, got %s (%v)", mode, got, err)
+		}
+	}
+
+	if _, err := money.ParseRoundingMode("bankers"); err != money.ErrInvalidRoundingMode {
+		t.Errorf("expected ErrInvalidRoundingMode, got %v", err)
+	}
+}
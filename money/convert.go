@@ -6,7 +6,7 @@ import (
 )
 
 // Convert applies the change rate to convert an amount to a target currency.
-func Convert(amount Amount, to Currency, rates ratesFetcher) (Amount, error) {
+func Convert(amount Amount, to Currency, rates RatesFetcher) (Amount, error) {
 	// fetch the exchange rate for the day
 
 	r, err := rates.FetchExchangeRate(amount.currency, to)
@@ -14,8 +14,12 @@ func Convert(amount Amount, to Currency, rates ratesFetcher) (Amount, error) {
 		return Amount{}, fmt.Errorf("cannot get exchange rate: %w", err)
 	}
 
-	// convert to the target rate currency applying the fetched change rate.
-	convertedValue := applyExchangeRate(amount, to, r)
+	// convert to the target rate currency applying the fetched change rate, truncating the extra digits.
+	// The product is computed on big integers, as it may not fit in 64 bits even when the result does.
+	convertedValue, _, err := roundExchange(amount, to, r, RoundDown)
+	if err != nil {
+		return Amount{}, err
+	}
 
 	// validate the converted amount is within bounds
 	if err := convertedValue.validate(); err != nil {
@@ -25,7 +29,8 @@ func Convert(amount Amount, to Currency, rates ratesFetcher) (Amount, error) {
 	return convertedValue, nil
 }
 
-type ratesFetcher interface {
+// RatesFetcher fetches the exchange rate between two currencies, such as a Chain or the rates of a provider.
+type RatesFetcher interface {
 	// FetchExchangeRate fetches the ExchangeRate for the day and returns it.
 	FetchExchangeRate(source, target Currency) (ExchangeRate, error)
 }
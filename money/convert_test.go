@@ -12,7 +12,7 @@ type stubRate struct {
 	err  error
 }
 
-// FetchExchangeRate implements the interface ratesFetcher with the same signature but fields are unused for tests purposes.
+// FetchExchangeRate implements the interface RatesFetcher with the same signature but fields are unused for tests purposes.
 func (m stubRate) FetchExchangeRate(_, _ money.Currency) (money.ExchangeRate, error) {
 	rate, _ := money.ParseDecimal(m.rate)
 	return money.ExchangeRate(rate), m.err
@@ -50,3 +50,18 @@ func TestConvert(t *testing.T) {
 		})
 	}
 }
+
+func TestConvert_ProductBeyond64Bits(t *testing.T) {
+	// 10000000.00 USD at 163.452173913 multiplies 1e9 subunits by a rate of 163452173913 units of 1e-9.
+	got, err := money.Convert(mustParseAmount(t, "10000000", "USD"), mustParseCurrency(t, "JPY"), stubRate{rate: "163.452173913"})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+	if got.String() != "1634521739.13 JPY" {
+		t.Errorf("expected 1634521739.13 JPY, got %s", got)
+	}
+
+	if _, err := money.Convert(mustParseAmount(t, "100000000", "USD"), mustParseCurrency(t, "JPY"), stubRate{rate: "163.452173913"}); err != money.ErrTooLarge {
+		t.Errorf("expected error %v, got %v", money.ErrTooLarge, err)
+	}
+}
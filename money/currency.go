@@ -46,3 +46,8 @@ func (c Currency) String() string {
 func (c Currency) ISOCode() string {
 	return c.code
 }
+
+// Precision returns the number of digits of the currency after the decimal separator.
+func (c Currency) Precision() byte {
+	return c.precision
+}
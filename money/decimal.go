@@ -2,6 +2,8 @@ package money
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -22,6 +24,8 @@ const (
 	ErrInvalidDecimal = Error("unable to convert the decimal")
 	// ErrTooLarge is returned if the quantity is too large - this would cause floating point precision errors
 	ErrTooLarge = Error("quantity over 10^12 is too large")
+	// ErrDivisionByZero is returned when dividing by a zero Decimal.
+	ErrDivisionByZero = Error("division by zero")
 )
 
 // String implements Stringer and returns the decimal formatted as digits and optionally a decimal point followed by digits
@@ -31,12 +35,17 @@ func (d *Decimal) String() string {
 		return fmt.Sprintf("%d", d.subunits)
 	}
 
+	sign, subunits := "", d.subunits
+	if subunits < 0 {
+		sign, subunits = "-", -subunits
+	}
+
 	centsPerUnit := pow10(d.precision)
-	frac := d.subunits % centsPerUnit
-	integer := d.subunits / centsPerUnit
+	frac := subunits % centsPerUnit
+	integer := subunits / centsPerUnit
 
-	decimalFormat := "%d.%0" + strconv.Itoa(int(d.precision)) + "d"
-	return fmt.Sprintf(decimalFormat, integer, frac)
+	decimalFormat := "%s%d.%0" + strconv.Itoa(int(d.precision)) + "d"
+	return fmt.Sprintf(decimalFormat, sign, integer, frac)
 }
 
 // ParseDecimal convert a string into its decimal representation.
@@ -70,3 +79,137 @@ func (d *Decimal) simplify() {
 		d.precision--
 	}
 }
+
+// divide returns the quotient of two Decimals with up to precision digits after the decimal separator.
+// The last digit is rounded half away from zero, and digits are dropped from the right until the result fits within maxDecimal.
+func divide(a, b Decimal, precision byte) (Decimal, error) {
+	if b.subunits == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+
+	// a/b = (a.subunits / b.subunits) * 10^(b.precision - a.precision)
+	num := big.NewInt(a.subunits)
+	den := big.NewInt(b.subunits)
+
+	exp := int(precision) - int(a.precision) + int(b.precision)
+	if exp >= 0 {
+		num.Mul(num, bigPow10(exp))
+	} else {
+		den.Mul(den, bigPow10(-exp))
+	}
+
+	return fitDecimal(roundedQuo(num, den), precision)
+}
+
+// fitDecimal returns the Decimal of the given subunits and precision.
+// Digits are dropped from the right, rounding half away from zero, until the subunits fit within maxDecimal.
+func fitDecimal(subunits *big.Int, precision byte) (Decimal, error) {
+	limit := big.NewInt(maxDecimal)
+	ten := big.NewInt(10)
+	for subunits.CmpAbs(limit) > 0 {
+		if precision == 0 {
+			return Decimal{}, ErrTooLarge
+		}
+		subunits = roundedQuo(subunits, ten)
+		precision--
+	}
+
+	result := Decimal{subunits: subunits.Int64(), precision: precision}
+	result.simplify()
+
+	return result, nil
+}
+
+// scaled returns the subunits of the Decimal expressed with a precision at least as high as its own.
+func (d Decimal) scaled(precision byte) *big.Int {
+	return new(big.Int).Mul(big.NewInt(d.subunits), bigPow10(int(precision)-int(d.precision)))
+}
+
+// float returns an approximation of the Decimal as a float64, for computations without an exact decimal result.
+func (d Decimal) float() float64 {
+	return float64(d.subunits) / math.Pow10(int(d.precision))
+}
+
+// Rat returns the exact value of the Decimal as a fraction, for arithmetic without intermediate rounding.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(d.subunits), bigPow10(int(d.precision)))
+}
+
+// Cmp returns -1, 0 or +1 depending on whether d is lower than, equal to, or greater than other.
+func (d Decimal) Cmp(other Decimal) int {
+	return compare(d, other)
+}
+
+// compare returns -1, 0 or +1 depending on whether a is lower than, equal to, or greater than b.
+func compare(a, b Decimal) int {
+	precision := max(a.precision, b.precision)
+	return a.scaled(precision).Cmp(b.scaled(precision))
+}
+
+// add returns the sum of two Decimals.
+func add(a, b Decimal) (Decimal, error) {
+	precision := max(a.precision, b.precision)
+	return fitDecimal(new(big.Int).Add(a.scaled(precision), b.scaled(precision)), precision)
+}
+
+// subtract returns the difference of two Decimals.
+func subtract(a, b Decimal) (Decimal, error) {
+	precision := max(a.precision, b.precision)
+	return fitDecimal(new(big.Int).Sub(a.scaled(precision), b.scaled(precision)), precision)
+}
+
+// product returns the product of two Decimals, rounded to at most precision digits after the decimal separator.
+func product(a, b Decimal, precision byte) (Decimal, error) {
+	subunits := new(big.Int).Mul(big.NewInt(a.subunits), big.NewInt(b.subunits))
+	productPrecision := a.precision + b.precision
+
+	if productPrecision > precision {
+		subunits = roundedQuo(subunits, bigPow10(int(productPrecision-precision)))
+		productPrecision = precision
+	}
+
+	return fitDecimal(subunits, productPrecision)
+}
+
+// mean returns the arithmetic mean of the values, with up to precision digits after the decimal separator.
+func mean(values []Decimal, precision byte) (Decimal, error) {
+	if len(values) == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+
+	sumPrecision := precision
+	for _, v := range values {
+		sumPrecision = max(sumPrecision, v.precision)
+	}
+
+	sum := new(big.Int)
+	for _, v := range values {
+		sum.Add(sum, v.scaled(sumPrecision))
+	}
+
+	// bring the sum back to the requested precision while dividing it.
+	den := new(big.Int).Mul(big.NewInt(int64(len(values))), bigPow10(int(sumPrecision-precision)))
+
+	return fitDecimal(roundedQuo(sum, den), precision)
+}
+
+// roundedQuo returns num/den rounded half away from zero.
+func roundedQuo(num, den *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	// the remainder is at least half of the denominator: round away from zero.
+	if new(big.Int).Mul(remainder, big.NewInt(2)).CmpAbs(den) >= 0 {
+		if num.Sign()*den.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient
+}
+
+// bigPow10 returns 10 raised to the given power as a big.Int.
+func bigPow10(power int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(power)), nil)
+}
@@ -97,6 +97,14 @@ func TestDecimalString(t *testing.T) {
 			decimal:  Decimal{subunits: 15200, precision: 2},
 			expected: "152.00",
 		},
+		"-8.05": {
+			decimal:  Decimal{subunits: -805, precision: 2},
+			expected: "-8.05",
+		},
+		"-0.5": {
+			decimal:  Decimal{subunits: -5, precision: 1},
+			expected: "-0.5",
+		},
 	}
 
 	for name, tc := range tt {
@@ -109,3 +117,22 @@ func TestDecimalString(t *testing.T) {
 		})
 	}
 }
+
+func TestDecimal_Cmp(t *testing.T) {
+	tt := map[string]struct {
+		a, b     Decimal
+		expected int
+	}{
+		"lower":                 {a: Decimal{subunits: 152, precision: 2}, b: Decimal{subunits: 2, precision: 0}, expected: -1},
+		"equal with precisions": {a: Decimal{subunits: 1500, precision: 3}, b: Decimal{subunits: 15, precision: 1}, expected: 0},
+		"greater":               {a: Decimal{subunits: 1, precision: 0}, b: Decimal{subunits: -5, precision: 1}, expected: 1},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.a.Cmp(tc.b); got != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package money
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrNoPath is returned when no chain of rates links two currencies.
+const ErrNoPath = Error("no exchange path between the currencies")
+
+// RateGraph finds the exchange rate between two currencies by chaining the rates of several providers,
+// whatever the base currency each of them quotes against.
+// The zero value is an empty graph ready to use.
+type RateGraph struct {
+	edges map[Currency][]PathStep
+}
+
+// PathStep is one rate of a Path.
+type PathStep struct {
+	From     Currency
+	To       Currency
+	Rate     ExchangeRate
+	Provider string
+	// Inverted reports whether the provider quoted the rate in the opposite direction.
+	Inverted bool
+}
+
+// Path is a chain of rates linking two currencies, along with the resulting rate.
+type Path struct {
+	Steps []PathStep
+	Rate  ExchangeRate
+}
+
+// String returns the currencies of the path, such as BTC→USD→EUR→JPY.
+func (p Path) String() string {
+	if len(p.Steps) == 0 {
+		return ""
+	}
+
+	codes := []string{p.Steps[0].From.ISOCode()}
+	for _, step := range p.Steps {
+		codes = append(codes, step.To.ISOCode())
+	}
+
+	return strings.Join(codes, "→")
+}
+
+// IsZero reports whether the path is unknown, which a rate quoted by a single provider without a chain also is.
+func (p Path) IsZero() bool {
+	return len(p.Steps) == 0
+}
+
+// MarshalText writes the currencies of the path, as String does.
+func (p Path) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText reads the currencies of a path written by MarshalText. The rates of the steps are left unknown.
+func (p *Path) UnmarshalText(text []byte) error {
+	*p = Path{}
+	if len(text) == 0 {
+		return nil
+	}
+
+	codes := strings.Split(string(text), "→")
+	if len(codes) < 2 {
+		return fmt.Errorf("%w: invalid path %q", ErrInvalidCurrencyCode, text)
+	}
+
+	currencies := make([]Currency, len(codes))
+	for i, code := range codes {
+		currency, err := ParseCurrency(code)
+		if err != nil {
+			return err
+		}
+		currencies[i] = currency
+	}
+
+	for i := 1; i < len(currencies); i++ {
+		p.Steps = append(p.Steps, PathStep{From: currencies[i-1], To: currencies[i]})
+	}
+	return nil
+}
+
+// AddRate records that one unit of from is worth rate units of to, according to the provider.
+// The opposite rate is recorded as well, and only used when no direct quote is available.
+func (g *RateGraph) AddRate(from, to Currency, rate ExchangeRate, provider string) error {
+	inverse, err := rate.Inverse()
+	if err != nil {
+		return fmt.Errorf("unable to invert exchange rate from %s to %s: %w", from, to, err)
+	}
+
+	if g.edges == nil {
+		g.edges = make(map[Currency][]PathStep)
+	}
+
+	g.edges[from] = append(g.edges[from], PathStep{From: from, To: to, Rate: rate, Provider: provider})
+	g.edges[to] = append(g.edges[to], PathStep{From: to, To: from, Rate: inverse, Provider: provider, Inverted: true})
+
+	return nil
+}
+
+// FetchExchangeRate returns the rate of the best path from source to target.
+func (g *RateGraph) FetchExchangeRate(source, target Currency) (ExchangeRate, error) {
+	path, err := g.FindPath(source, target)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+
+	return path.Rate, nil
+}
+
+// FetchRateInfo returns the rate of the best path from source to target, along with the path
+// and the providers of its rates, in the order they're used.
+func (g *RateGraph) FetchRateInfo(source, target Currency) (RateInfo, error) {
+	path, err := g.FindPath(source, target)
+	if err != nil {
+		return RateInfo{}, err
+	}
+
+	var providers []string
+	for _, step := range path.Steps {
+		if !slices.Contains(providers, step.Provider) {
+			providers = append(providers, step.Provider)
+		}
+	}
+
+	return RateInfo{Rate: path.Rate, Provider: strings.Join(providers, "+"), Path: path}, nil
+}
+
+// pathCost ranks paths: the fewest steps first, then the fewest inverted rates as each inversion loses precision.
+type pathCost struct {
+	steps      int
+	inversions int
+}
+
+// less returns whether c is a better cost than other.
+func (c pathCost) less(other pathCost) bool {
+	if c.steps != other.steps {
+		return c.steps < other.steps
+	}
+	return c.inversions < other.inversions
+}
+
+// FindPath returns the path from source to target with the fewest steps, preferring rates quoted directly
+// over inverted ones among paths of the same length. It may return ErrNoPath.
+func (g *RateGraph) FindPath(source, target Currency) (Path, error) {
+	one := ExchangeRate{subunits: 1, precision: 0}
+	if source == target {
+		return Path{Rate: one}, nil
+	}
+
+	// Dijkstra's algorithm, the graphs are small enough to look for the next currency linearly.
+	costs := map[Currency]pathCost{source: {}}
+	previous := make(map[Currency]PathStep)
+	visited := make(map[Currency]bool)
+
+	for {
+		var current Currency
+		found := false
+		for currency, cost := range costs {
+			if visited[currency] {
+				continue
+			}
+			if !found || cost.less(costs[current]) || (!costs[current].less(cost) && currency.code < current.code) {
+				current, found = currency, true
+			}
+		}
+
+		if !found {
+			return Path{}, fmt.Errorf("%w from %s to %s", ErrNoPath, source, target)
+		}
+		if current == target {
+			break
+		}
+		visited[current] = true
+
+		for _, step := range g.edges[current] {
+			cost := pathCost{steps: costs[current].steps + 1, inversions: costs[current].inversions}
+			if step.Inverted {
+				cost.inversions++
+			}
+
+			if known, ok := costs[step.To]; !ok || cost.less(known) {
+				costs[step.To] = cost
+				previous[step.To] = step
+			}
+		}
+	}
+
+	var steps []PathStep
+	for currency := target; currency != source; currency = previous[currency].From {
+		steps = append([]PathStep{previous[currency]}, steps...)
+	}
+
+	rate := one
+	for _, step := range steps {
+		chained, err := product(Decimal(rate), Decimal(step.Rate), maxRatePrecision)
+		if err != nil {
+			return Path{}, fmt.Errorf("unable to chain exchange rates from %s to %s: %w", source, target, err)
+		}
+		rate = ExchangeRate(chained)
+	}
+
+	return Path{Steps: steps, Rate: rate}, nil
+}
@@ -0,0 +1,110 @@
+package money_test
+
+import (
+	"errors"
+	"moneyconverter/money"
+	"testing"
+)
+
+func TestRateGraph_FindPath(t *testing.T) {
+	var graph money.RateGraph
+	addRate(t, &graph, "BTC", "USD", "60000", "crypto")
+	addRate(t, &graph, "EUR", "USD", "1.25", "ecb")
+	addRate(t, &graph, "EUR", "JPY", "160", "ecb")
+	addRate(t, &graph, "USD", "EUR", "0.7", "other")
+
+	tt := map[string]struct {
+		source    string
+		target    string
+		wantPath  string
+		wantRate  string
+		providers []string
+		err       error
+	}{
+		"across bases and providers": {
+			source:    "BTC",
+			target:    "JPY",
+			wantPath:  "BTC→USD→EUR→JPY",
+			wantRate:  "6720000",
+			providers: []string{"crypto", "other", "ecb"},
+		},
+		"direct quote is preferred over an inverted one": {
+			source:    "EUR",
+			target:    "USD",
+			wantPath:  "EUR→USD",
+			wantRate:  "1.25",
+			providers: []string{"ecb"},
+		},
+		"inverted quote": {
+			source:    "JPY",
+			target:    "EUR",
+			wantPath:  "JPY→EUR",
+			wantRate:  "0.00625",
+			providers: []string{"ecb"},
+		},
+		"same currency": {
+			source:   "EUR",
+			target:   "EUR",
+			wantRate: "1",
+		},
+		"no path": {
+			source: "EUR",
+			target: "CHF",
+			err:    money.ErrNoPath,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := graph.FindPath(mustParseCurrency(t, tc.source), mustParseCurrency(t, tc.target))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if tc.err != nil {
+				return
+			}
+
+			if got.String() != tc.wantPath {
+				t.Errorf("expected path %q, got %q", tc.wantPath, got.String())
+			}
+
+			if got.Rate.String() != tc.wantRate {
+				t.Errorf("expected rate %s, got %s", tc.wantRate, got.Rate)
+			}
+
+			if len(got.Steps) != len(tc.providers) {
+				t.Fatalf("expected %d steps, got %d", len(tc.providers), len(got.Steps))
+			}
+
+			for i, step := range got.Steps {
+				if step.Provider != tc.providers[i] {
+					t.Errorf("expected step %d from %s, got %s", i, tc.providers[i], step.Provider)
+				}
+			}
+		})
+	}
+}
+
+func TestRateGraph_FetchExchangeRate(t *testing.T) {
+	var graph money.RateGraph
+	addRate(t, &graph, "EUR", "USD", "2", "ecb")
+
+	got, err := money.Convert(mustParseAmount(t, "10", "USD"), mustParseCurrency(t, "EUR"), &graph)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	if got.String() != "5.00 EUR" {
+		t.Errorf("expected 5.00 EUR, got %s", got)
+	}
+}
+
+func addRate(t *testing.T, graph *money.RateGraph, from, to, rate, provider string) {
+	t.Helper()
+
+	err := graph.AddRate(mustParseCurrency(t, from), mustParseCurrency(t, to), money.ExchangeRate(mustParseDecimal(t, rate)), provider)
+	if err != nil {
+		t.Fatalf("cannot add rate: %v", err)
+	}
+}
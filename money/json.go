@@ -0,0 +1,72 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalText implements encoding.TextMarshaler, a Currency is written as its ISO code.
+func (c Currency) MarshalText() ([]byte, error) {
+	return []byte(c.code), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, and may return ErrInvalidCurrencyCode.
+func (c *Currency) UnmarshalText(text []byte) error {
+	currency, err := ParseCurrency(string(text))
+	if err != nil {
+		return err
+	}
+	*c = currency
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, a Decimal is written as its digits to keep all of them.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	decimal, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = decimal
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r ExchangeRate) MarshalText() ([]byte, error) {
+	return Decimal(r).MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *ExchangeRate) UnmarshalText(text []byte) error {
+	return (*Decimal)(r).UnmarshalText(text)
+}
+
+// jsonAmount is the JSON representation of an Amount.
+type jsonAmount struct {
+	Quantity Decimal  `json:"quantity"`
+	Currency Currency `json:"currency"`
+}
+
+// MarshalJSON implements json.Marshaler, an Amount is written as {"quantity": "12.50", "currency": "EUR"}.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAmount{Quantity: a.quantity, Currency: a.currency})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and may return ErrTooPrecise.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var j jsonAmount
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	amount, err := NewAmount(j.Quantity, j.Currency)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s %s: %w", j.Quantity.String(), j.Currency, err)
+	}
+	*a = amount
+	return nil
+}
@@ -0,0 +1,243 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const (
+	// ErrInvalidMargin is returned when a margin isn't within [0%, 100%).
+	ErrInvalidMargin = Error("margin must be at least 0% and less than 100%")
+	// ErrFeeExceedsAmount is returned when the fee is larger than the converted amount.
+	ErrFeeExceedsAmount = Error("fee exceeds the converted amount")
+)
+
+// Margin is the share of the mid-market rate kept by whoever converts the money.
+type Margin struct {
+	// fraction of the mid rate, such as 0.015 for 1.5%.
+	fraction Decimal
+}
+
+// SpreadPercent returns a Margin of the given percentage of the mid rate, such as 1.5 for 1.5%.
+func SpreadPercent(percent Decimal) (Margin, error) {
+	fraction, err := divide(percent, Decimal{subunits: 100}, maxRatePrecision)
+	if err != nil {
+		return Margin{}, err
+	}
+
+	return newMargin(fraction)
+}
+
+// MarkupBasisPoints returns a Margin of the given number of basis points of the mid rate, 100 basis points being 1%.
+func MarkupBasisPoints(bps int64) (Margin, error) {
+	return newMargin(Decimal{subunits: bps, precision: 4})
+}
+
+// newMargin validates the fraction of a Margin.
+func newMargin(fraction Decimal) (Margin, error) {
+	if fraction.subunits < 0 || compare(fraction, Decimal{subunits: 1}) >= 0 {
+		return Margin{}, ErrInvalidMargin
+	}
+
+	fraction.simplify()
+	return Margin{fraction: fraction}, nil
+}
+
+// apply returns the rate offered to the customer out of the mid rate.
+func (m Margin) apply(mid ExchangeRate) (ExchangeRate, error) {
+	kept, err := subtract(Decimal{subunits: 1}, m.fraction)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+
+	applied, err := product(Decimal(mid), kept, maxRatePrecision)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+
+	return ExchangeRate(applied), nil
+}
+
+// Fee is charged on each conversion, and deducted from the converted amount.
+// The zero value charges nothing.
+type Fee struct {
+	// Currency of the fee, the source currency of the conversion if unset.
+	Currency Currency
+	// Fixed is charged on every conversion.
+	Fixed Decimal
+	// Percent of the source amount is charged on top of the fixed fee, such as 0.5 for 0.5%.
+	Percent Decimal
+	// Minimum is the lowest fee charged.
+	Minimum Decimal
+}
+
+// Pricing turns mid-market rates into customer rates, applying a margin and a fee.
+// The zero value converts at the mid rate without fee.
+type Pricing struct {
+	// Margin applies to the pairs without a margin of their own.
+	Margin Margin
+	// Fee is charged on every conversion.
+	Fee Fee
+	// Rounding applies to the converted amount, truncating it by default.
+	Rounding RoundingMode
+	// pairMargins holds the margins of specific pairs.
+	pairMargins map[[2]Currency]Margin
+}
+
+// SetPairMargin sets the margin of conversions from a currency to another, overriding the default Margin.
+func (p *Pricing) SetPairMargin(from, to Currency, margin Margin) {
+	if p.pairMargins == nil {
+		p.pairMargins = make(map[[2]Currency]Margin)
+	}
+	p.pairMargins[[2]Currency{from, to}] = margin
+}
+
+// margin returns the margin of a pair.
+func (p Pricing) margin(from, to Currency) Margin {
+	if m, ok := p.pairMargins[[2]Currency{from, to}]; ok {
+		return m
+	}
+	return p.Margin
+}
+
+// PricedConversion details how an amount was converted for a customer.
+type PricedConversion struct {
+	// Source is the amount to convert.
+	Source Amount
+	// MidRate is the mid-market rate of the pair.
+	MidRate ExchangeRate
+	// AppliedRate is the mid rate minus the margin.
+	AppliedRate ExchangeRate
+	// Gross is the source converted at the applied rate.
+	Gross Amount
+	// Fee is the fee charged, in the currency of the fee.
+	Fee Amount
+	// FeeInTarget is the fee converted to the target currency at the mid rate.
+	FeeInTarget Amount
+	// Net is the amount received: Gross minus FeeInTarget.
+	Net Amount
+}
+
+// Convert converts an amount for a customer: at the mid rate minus the margin of the pair, minus the fee.
+func (p Pricing) Convert(amount Amount, to Currency, rates RatesFetcher) (PricedConversion, error) {
+	mid, err := rates.FetchExchangeRate(amount.currency, to)
+	if err != nil {
+		return PricedConversion{}, fmt.Errorf("cannot get exchange rate: %w", err)
+	}
+
+	applied, err := p.margin(amount.currency, to).apply(mid)
+	if err != nil {
+		return PricedConversion{}, fmt.Errorf("cannot apply margin: %w", err)
+	}
+
+	gross, _, err := roundExchange(amount, to, applied, p.Rounding)
+	if err != nil {
+		return PricedConversion{}, err
+	}
+
+	fee, err := p.Fee.charge(amount, rates, p.Rounding)
+	if err != nil {
+		return PricedConversion{}, fmt.Errorf("cannot compute fee: %w", err)
+	}
+
+	feeInTarget := fee
+	if fee.currency != to {
+		feeRate, err := rates.FetchExchangeRate(fee.currency, to)
+		if err != nil {
+			return PricedConversion{}, fmt.Errorf("cannot get exchange rate of the fee: %w", err)
+		}
+		feeInTarget, _, err = roundExchange(fee, to, feeRate, p.Rounding)
+		if err != nil {
+			return PricedConversion{}, fmt.Errorf("cannot convert fee: %w", err)
+		}
+	}
+
+	net, err := subtractAmounts(gross, feeInTarget)
+	if err != nil {
+		return PricedConversion{}, err
+	}
+	if net.quantity.subunits < 0 {
+		return PricedConversion{}, fmt.Errorf("%w: %s fee on %s", ErrFeeExceedsAmount, feeInTarget, gross)
+	}
+
+	return PricedConversion{
+		Source:      amount,
+		MidRate:     mid,
+		AppliedRate: applied,
+		Gross:       gross,
+		Fee:         fee,
+		FeeInTarget: feeInTarget,
+		Net:         net,
+	}, nil
+}
+
+// charge returns the fee charged on converting the amount, rounded half up to the precision of the fee currency.
+// The amount is expressed in the fee currency with the rounding of the conversion.
+func (f Fee) charge(amount Amount, rates RatesFetcher, rounding RoundingMode) (Amount, error) {
+	currency := f.Currency
+	if currency == (Currency{}) {
+		currency = amount.currency
+	}
+
+	// the percentage applies to the source amount expressed in the fee currency.
+	base := amount
+	if currency != amount.currency {
+		rate, err := rates.FetchExchangeRate(amount.currency, currency)
+		if err != nil {
+			return Amount{}, err
+		}
+		base, _, err = roundExchange(amount, currency, rate, rounding)
+		if err != nil {
+			return Amount{}, err
+		}
+	}
+
+	proportional, err := product(base.quantity, f.Percent, maxRatePrecision+2)
+	if err != nil {
+		return Amount{}, err
+	}
+	proportional, err = divide(proportional, Decimal{subunits: 100}, maxRatePrecision)
+	if err != nil {
+		return Amount{}, err
+	}
+
+	total, err := add(f.Fixed, proportional)
+	if err != nil {
+		return Amount{}, err
+	}
+
+	if compare(total, f.Minimum) < 0 {
+		total = f.Minimum
+	}
+
+	return Amount{quantity: roundHalfUp(total, currency.precision), currency: currency}, nil
+}
+
+// subtractAmounts returns a - b, both amounts being of the same currency.
+func subtractAmounts(a, b Amount) (Amount, error) {
+	if a.currency != b.currency {
+		return Amount{}, fmt.Errorf("cannot subtract %s from %s", b.currency, a.currency)
+	}
+
+	difference := new(big.Int).Sub(a.quantity.scaled(a.currency.precision), b.quantity.scaled(a.currency.precision))
+	if difference.CmpAbs(big.NewInt(maxDecimal)) > 0 {
+		return Amount{}, ErrTooLarge
+	}
+
+	return Amount{
+		quantity: Decimal{subunits: difference.Int64(), precision: a.currency.precision},
+		currency: a.currency,
+	}, nil
+}
+
+// roundHalfUp returns the Decimal with exactly the given precision, rounding half away from zero.
+func roundHalfUp(d Decimal, precision byte) Decimal {
+	if d.precision <= precision {
+		return Decimal{subunits: d.scaled(precision).Int64(), precision: precision}
+	}
+
+	return Decimal{
+		subunits:  roundedQuo(big.NewInt(d.subunits), bigPow10(int(d.precision-precision))).Int64(),
+		precision: precision,
+	}
+}
@@ -0,0 +1,163 @@
+package money_test
+
+import (
+	"errors"
+	"moneyconverter/money"
+	"testing"
+)
+
+// pairRates is a stub returning the rate of each pair, written as "USD/EUR".
+type pairRates map[string]string
+
+// FetchExchangeRate implements the interface RatesFetcher.
+func (p pairRates) FetchExchangeRate(source, target money.Currency) (money.ExchangeRate, error) {
+	value := "1"
+	if source != target {
+		var ok bool
+		if value, ok = p[source.ISOCode()+"/"+target.ISOCode()]; !ok {
+			return money.ExchangeRate{}, errors.New("couldn't find the exchange rate")
+		}
+	}
+
+	rate, err := money.ParseDecimal(value)
+	return money.ExchangeRate(rate), err
+}
+
+func TestPricing_Convert(t *testing.T) {
+	rates := pairRates{"USD/EUR": "0.9", "EUR/USD": "1.1"}
+	onePercent, err := money.SpreadPercent(mustParseDecimal(t, "1"))
+	if err != nil {
+		t.Fatalf("cannot build margin: %v", err)
+	}
+
+	fiftyBps, err := money.MarkupBasisPoints(50)
+	if err != nil {
+		t.Fatalf("cannot build margin: %v", err)
+	}
+
+	pairPricing := money.Pricing{Margin: onePercent}
+	pairPricing.SetPairMargin(mustParseCurrency(t, "USD"), mustParseCurrency(t, "EUR"), fiftyBps)
+
+	tt := map[string]struct {
+		pricing     money.Pricing
+		amount      money.Amount
+		wantApplied string
+		wantGross   string
+		wantFee     string
+		wantNet     string
+	}{
+		"mid rate without fee": {
+			pricing:     money.Pricing{},
+			amount:      mustParseAmount(t, "100", "USD"),
+			wantApplied: "0.9",
+			wantGross:   "90.00 EUR",
+			wantFee:     "0.00 USD",
+			wantNet:     "90.00 EUR",
+		},
+		"percent spread and fixed fee in target currency": {
+			pricing: money.Pricing{
+				Margin: onePercent,
+				Fee:    money.Fee{Currency: mustParseCurrency(t, "EUR"), Fixed: mustParseDecimal(t, "2")},
+			},
+			amount:      mustParseAmount(t, "100", "USD"),
+			wantApplied: "0.891",
+			wantGross:   "89.10 EUR",
+			wantFee:     "2.00 EUR",
+			wantNet:     "87.10 EUR",
+		},
+		"pair margin in basis points": {
+			pricing:     pairPricing,
+			amount:      mustParseAmount(t, "100", "USD"),
+			wantApplied: "0.8955",
+			wantGross:   "89.55 EUR",
+			wantFee:     "0.00 USD",
+			wantNet:     "89.55 EUR",
+		},
+		"minimum fee in source currency": {
+			pricing: money.Pricing{
+				Fee: money.Fee{Percent: mustParseDecimal(t, "1"), Minimum: mustParseDecimal(t, "3")},
+			},
+			amount:      mustParseAmount(t, "100", "USD"),
+			wantApplied: "0.9",
+			wantGross:   "90.00 EUR",
+			wantFee:     "3.00 USD",
+			wantNet:     "87.30 EUR",
+		},
+		"percent fee above minimum": {
+			pricing: money.Pricing{
+				Fee: money.Fee{Percent: mustParseDecimal(t, "1.5"), Minimum: mustParseDecimal(t, "1")},
+			},
+			amount:      mustParseAmount(t, "1000", "USD"),
+			wantApplied: "0.9",
+			wantGross:   "900.00 EUR",
+			wantFee:     "15.00 USD",
+			wantNet:     "886.50 EUR",
+		},
+		"fixed fee converted with the rounding": {
+			pricing: money.Pricing{
+				Fee:      money.Fee{Fixed: mustParseDecimal(t, "1.05")},
+				Rounding: money.RoundHalfUp,
+			},
+			amount:      mustParseAmount(t, "100", "USD"),
+			wantApplied: "0.9",
+			wantGross:   "90.00 EUR",
+			wantFee:     "1.05 USD",
+			wantNet:     "89.05 EUR",
+		},
+		"percent fee of the amount converted with the rounding": {
+			pricing: money.Pricing{
+				Fee:      money.Fee{Currency: mustParseCurrency(t, "EUR"), Percent: mustParseDecimal(t, "10")},
+				Rounding: money.RoundHalfUp,
+			},
+			amount:      mustParseAmount(t, "100.05", "USD"),
+			wantApplied: "0.9",
+			wantGross:   "90.05 EUR",
+			wantFee:     "9.01 EUR",
+			wantNet:     "81.04 EUR",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.pricing.Convert(tc.amount, mustParseCurrency(t, "EUR"), rates)
+			if err != nil {
+				t.Fatalf("unexpected error, %v", err)
+			}
+
+			if got.MidRate.String() != "0.9" {
+				t.Errorf("expected mid rate 0.9, got %s", got.MidRate)
+			}
+			if got.AppliedRate.String() != tc.wantApplied {
+				t.Errorf("expected applied rate %s, got %s", tc.wantApplied, got.AppliedRate)
+			}
+			if got.Gross.String() != tc.wantGross {
+				t.Errorf("expected gross %s, got %s", tc.wantGross, got.Gross)
+			}
+			if got.Fee.String() != tc.wantFee {
+				t.Errorf("expected fee %s, got %s", tc.wantFee, got.Fee)
+			}
+			if got.Net.String() != tc.wantNet {
+				t.Errorf("expected net %s, got %s", tc.wantNet, got.Net)
+			}
+		})
+	}
+}
+
+func TestPricing_Convert_FeeExceedsAmount(t *testing.T) {
+	pricing := money.Pricing{Fee: money.Fee{Currency: mustParseCurrency(t, "EUR"), Fixed: mustParseDecimal(t, "5")}}
+
+	_, err := pricing.Convert(mustParseAmount(t, "1", "USD"), mustParseCurrency(t, "EUR"), pairRates{"USD/EUR": "0.9"})
+	if !errors.Is(err, money.ErrFeeExceedsAmount) {
+		t.Errorf("expected error %v, got %v", money.ErrFeeExceedsAmount, err)
+	}
+}
+
+func TestMargin_Invalid(t *testing.T) {
+	if _, err := money.SpreadPercent(mustParseDecimal(t, "100")); !errors.Is(err, money.ErrInvalidMargin) {
+		t.Errorf("expected error %v, got %v", money.ErrInvalidMargin, err)
+	}
+
+	if _, err := money.MarkupBasisPoints(-5); !errors.Is(err, money.ErrInvalidMargin) {
+		t.Errorf("expected error %v, got %v", money.ErrInvalidMargin, err)
+	}
+}
@@ -0,0 +1,62 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// maxRatePrecision is the number of digits kept after the decimal separator when computing a rate.
+// This precision should be enough as most currencies only use 5 digits.
+const maxRatePrecision = 10
+
+// CrossRate returns the rate to convert from a source to a target currency,
+// given the rates of both currencies quoted against a common base currency.
+func CrossRate(baseToSource, baseToTarget ExchangeRate) (ExchangeRate, error) {
+	rate, err := divide(Decimal(baseToTarget), Decimal(baseToSource), maxRatePrecision)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+
+	return ExchangeRate(rate), nil
+}
+
+// ParseRate parses an exchange rate written as a decimal, such as 1.0956.
+// Digits after the decimal separator are rounded half away from zero when there are more than a rate keeps,
+// or than fit within the range of a Decimal.
+func ParseRate(value string) (ExchangeRate, error) {
+	rate, err := ParseDecimal(value)
+	if err == nil && rate.precision <= maxRatePrecision {
+		return ExchangeRate(rate), nil
+	}
+
+	integer, fraction, _ := strings.Cut(value, ".")
+	subunits, ok := new(big.Int).SetString(integer+fraction, 10)
+	if !ok {
+		return ExchangeRate{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, value)
+	}
+
+	precision := len(fraction)
+	if precision > maxRatePrecision {
+		subunits = roundedQuo(subunits, bigPow10(precision-maxRatePrecision))
+		precision = maxRatePrecision
+	}
+
+	rounded, err := fitDecimal(subunits, byte(precision))
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+
+	return ExchangeRate(rounded), nil
+}
+
+// String implements Stringer.
+func (r ExchangeRate) String() string {
+	d := Decimal(r)
+	return d.String()
+}
+
+// Inverse returns the rate to convert in the opposite direction.
+func (r ExchangeRate) Inverse() (ExchangeRate, error) {
+	return CrossRate(r, ExchangeRate{subunits: 1, precision: 0})
+}
@@ -0,0 +1,126 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCrossRate(t *testing.T) {
+	tt := map[string]struct {
+		baseToSource ExchangeRate
+		baseToTarget ExchangeRate
+		want         ExchangeRate
+		err          error
+	}{
+		"same rate": {
+			baseToSource: ExchangeRate{subunits: 15, precision: 1},
+			baseToTarget: ExchangeRate{subunits: 15, precision: 1},
+			want:         ExchangeRate{subunits: 1, precision: 0},
+		},
+		"exact quotient": {
+			baseToSource: ExchangeRate{subunits: 2, precision: 0},
+			baseToTarget: ExchangeRate{subunits: 6, precision: 0},
+			want:         ExchangeRate{subunits: 3, precision: 0},
+		},
+		"rounded quotient": {
+			baseToSource: ExchangeRate{subunits: 15, precision: 1},
+			baseToTarget: ExchangeRate{subunits: 1, precision: 0},
+			want:         ExchangeRate{subunits: 6666666667, precision: 10},
+		},
+		"large quotient keeps significant digits": {
+			baseToSource: ExchangeRate{subunits: 3, precision: 0},
+			baseToTarget: ExchangeRate{subunits: 17000, precision: 0},
+			want:         ExchangeRate{subunits: 566666666667, precision: 8},
+		},
+		"zero source rate": {
+			baseToSource: ExchangeRate{subunits: 0, precision: 0},
+			baseToTarget: ExchangeRate{subunits: 2, precision: 0},
+			err:          ErrDivisionByZero,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := CrossRate(tc.baseToSource, tc.baseToTarget)
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestExchangeRate_Inverse(t *testing.T) {
+	tt := map[string]struct {
+		rate ExchangeRate
+		want ExchangeRate
+		err  error
+	}{
+		"0.5":  {rate: ExchangeRate{5, 1}, want: ExchangeRate{2, 0}},
+		"3":    {rate: ExchangeRate{3, 0}, want: ExchangeRate{3333333333, 10}},
+		"zero": {rate: ExchangeRate{0, 0}, err: ErrDivisionByZero},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.rate.Inverse()
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tt := map[string]struct {
+		value string
+		want  ExchangeRate
+		err   error
+	}{
+		"exact": {
+			value: "1.0956",
+			want:  ExchangeRate{subunits: 10956, precision: 4},
+		},
+		"trailing zeros": {
+			value: "161.3795000000",
+			want:  ExchangeRate{subunits: 1613795, precision: 4},
+		},
+		"beyond the range of a decimal": {
+			value: "159.4547826087",
+			want:  ExchangeRate{subunits: 159454782609, precision: 9},
+		},
+		"beyond the precision of a rate": {
+			value: "0.123456789049",
+			want:  ExchangeRate{subunits: 123456789, precision: 9},
+		},
+		"too large": {
+			value: "10000000000000",
+			err:   ErrTooLarge,
+		},
+		"not a number": {
+			value: "1.2.3",
+			err:   ErrInvalidDecimal,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseRate(tc.value)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
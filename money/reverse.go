@@ -0,0 +1,130 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// RequiredSource returns the smallest amount of the from currency that Explain converts,
+// with the given rounding, to at least the target amount.
+func RequiredSource(target Amount, from Currency, rates RatesFetcher, rounding RoundingMode) (Amount, error) {
+	memo := newMemoRates(rates)
+
+	rate, err := memo.FetchExchangeRate(from, target.currency)
+	if err != nil {
+		return Amount{}, fmt.Errorf("cannot get exchange rate: %w", err)
+	}
+
+	return requiredSource(target, from, rate, func(source Amount) (Amount, error) {
+		output, _, err := roundExchange(source, target.currency, rate, rounding)
+		return output, err
+	})
+}
+
+// RequiredSource returns the smallest amount of the from currency that p.Convert converts
+// to a Net of at least the target amount, once the margin and the fee are taken.
+func (p Pricing) RequiredSource(target Amount, from Currency, rates RatesFetcher) (Amount, error) {
+	memo := newMemoRates(rates)
+
+	mid, err := memo.FetchExchangeRate(from, target.currency)
+	if err != nil {
+		return Amount{}, fmt.Errorf("cannot get exchange rate: %w", err)
+	}
+
+	return requiredSource(target, from, mid, func(source Amount) (Amount, error) {
+		conversion, err := p.Convert(source, target.currency, memo)
+		if errors.Is(err, ErrFeeExceedsAmount) {
+			// too little to pay the fee: nothing is received.
+			return Amount{currency: target.currency}, nil
+		}
+		return conversion.Net, err
+	})
+}
+
+// requiredSource returns the smallest amount of the from currency that convert turns into at least the target,
+// convert being non-decreasing. The rate gives a first estimate of the amount.
+func requiredSource(target Amount, from Currency, rate ExchangeRate, convert func(Amount) (Amount, error)) (Amount, error) {
+	if target.quantity.subunits <= 0 {
+		return Amount{quantity: Decimal{precision: from.precision}, currency: from}, nil
+	}
+
+	if rate.subunits <= 0 {
+		return Amount{}, fmt.Errorf("cannot convert from %s to %s: %w", from, target.currency, ErrDivisionByZero)
+	}
+
+	reaches := func(subunits int64) (bool, error) {
+		output, err := convert(Amount{quantity: Decimal{subunits: subunits, precision: from.precision}, currency: from})
+		if err != nil {
+			return false, err
+		}
+		return compare(output.quantity, target.quantity) >= 0, nil
+	}
+
+	// start from the target divided by the rate, rounded up to the precision of the source currency.
+	num := target.quantity.scaled(target.quantity.precision + from.precision + rate.precision)
+	den := new(big.Int).Mul(big.NewInt(rate.subunits), bigPow10(int(target.quantity.precision)))
+	estimate := RoundUp.quo(num, den)
+	if estimate.CmpAbs(big.NewInt(maxDecimal)) > 0 {
+		return Amount{}, ErrTooLarge
+	}
+
+	// find an upper bound reaching the target, then search down to the smallest one.
+	high := max(estimate.Int64(), 1)
+	for {
+		ok, err := reaches(high)
+		if err != nil {
+			return Amount{}, err
+		}
+		if ok {
+			break
+		}
+		if high > maxDecimal/2 {
+			return Amount{}, ErrTooLarge
+		}
+		high *= 2
+	}
+
+	low := int64(0)
+	for low < high {
+		middle := low + (high-low)/2
+		ok, err := reaches(middle)
+		if err != nil {
+			return Amount{}, err
+		}
+		if ok {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+
+	return Amount{quantity: Decimal{subunits: high, precision: from.precision}, currency: from}, nil
+}
+
+// memoRates remembers the exchange rates fetched, so that each pair is only fetched once.
+type memoRates struct {
+	rates RatesFetcher
+	known map[[2]Currency]ExchangeRate
+}
+
+// newMemoRates returns a memoRates fetching the pairs it doesn't know yet from rates.
+func newMemoRates(rates RatesFetcher) memoRates {
+	return memoRates{rates: rates, known: make(map[[2]Currency]ExchangeRate)}
+}
+
+// FetchExchangeRate implements the interface RatesFetcher.
+func (m memoRates) FetchExchangeRate(source, target Currency) (ExchangeRate, error) {
+	key := [2]Currency{source, target}
+	if rate, ok := m.known[key]; ok {
+		return rate, nil
+	}
+
+	rate, err := m.rates.FetchExchangeRate(source, target)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+
+	m.known[key] = rate
+	return rate, nil
+}
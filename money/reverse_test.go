@@ -0,0 +1,111 @@
+package money_test
+
+import (
+	"moneyconverter/money"
+	"testing"
+)
+
+func TestRequiredSource(t *testing.T) {
+	tt := map[string]struct {
+		target   money.Amount
+		rate     string
+		rounding money.RoundingMode
+		want     string
+	}{
+		"truncated conversion": {
+			target:   mustParseAmount(t, "500", "EUR"),
+			rate:     "0.9",
+			rounding: money.RoundDown,
+			want:     "555.56 USD",
+		},
+		"rounded conversion": {
+			target:   mustParseAmount(t, "500", "EUR"),
+			rate:     "0.9",
+			rounding: money.RoundHalfUp,
+			want:     "555.55 USD",
+		},
+		"exact conversion": {
+			target:   mustParseAmount(t, "500", "EUR"),
+			rate:     "2",
+			rounding: money.RoundDown,
+			want:     "250.00 USD",
+		},
+		"nothing wanted": {
+			target:   mustParseAmount(t, "0", "EUR"),
+			rate:     "0.9",
+			rounding: money.RoundDown,
+			want:     "0.00 USD",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			rates := stubRate{rate: tc.rate}
+			got, err := money.RequiredSource(tc.target, mustParseCurrency(t, "USD"), rates, tc.rounding)
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			if got.String() != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+
+			conversion, err := money.Explain(got, tc.target.Currency(), rates, tc.rounding)
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+			if conversion.Output.String() != tc.target.String() {
+				t.Errorf("expected %s to convert to %s, got %s", got, tc.target, conversion.Output)
+			}
+		})
+	}
+}
+
+func TestPricing_RequiredSource(t *testing.T) {
+	rates := pairRates{"USD/EUR": "0.9"}
+	onePercent, err := money.SpreadPercent(mustParseDecimal(t, "1"))
+	if err != nil {
+		t.Fatalf("cannot build margin: %v", err)
+	}
+
+	tt := map[string]struct {
+		pricing money.Pricing
+		target  money.Amount
+		want    string
+	}{
+		"margin and fixed fee": {
+			pricing: money.Pricing{
+				Margin: onePercent,
+				Fee:    money.Fee{Currency: mustParseCurrency(t, "EUR"), Fixed: mustParseDecimal(t, "2")},
+			},
+			target: mustParseAmount(t, "100", "EUR"),
+			want:   "114.48 USD",
+		},
+		"minimum fee in source currency": {
+			pricing: money.Pricing{Fee: money.Fee{Minimum: mustParseDecimal(t, "5")}},
+			target:  mustParseAmount(t, "10", "EUR"),
+			want:    "16.12 USD",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.pricing.RequiredSource(tc.target, mustParseCurrency(t, "USD"), rates)
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			if got.String() != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+
+			conversion, err := tc.pricing.Convert(got, tc.target.Currency(), rates)
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+			if conversion.Net.String() != tc.target.String() {
+				t.Errorf("expected %s to convert to %s, got %s", got, tc.target, conversion.Net)
+			}
+		})
+	}
+}
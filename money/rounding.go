@@ -0,0 +1,102 @@
+package money
+
+import (
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidRoundingMode is returned when parsing an unknown rounding mode.
+const ErrInvalidRoundingMode = Error("invalid rounding mode")
+
+// RoundingMode tells how to drop the digits of a converted amount beyond the precision of its currency.
+type RoundingMode int
+
+const (
+	// RoundDown truncates the extra digits, rounding towards zero.
+	RoundDown RoundingMode = iota
+	// RoundHalfUp rounds to the nearest value, and halves away from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest value, and halves to the even neighbour.
+	RoundHalfEven
+	// RoundUp rounds away from zero as soon as an extra digit isn't 0.
+	RoundUp
+)
+
+// roundingModeNames holds the names of the rounding modes, in the order of their values.
+var roundingModeNames = []string{"down", "half-up", "half-even", "up"}
+
+// ParseRoundingMode returns the rounding mode of a name: down, half-up, half-even or up.
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	for i, n := range roundingModeNames {
+		if strings.EqualFold(name, n) {
+			return RoundingMode(i), nil
+		}
+	}
+
+	return RoundDown, ErrInvalidRoundingMode
+}
+
+// String implements Stringer.
+func (m RoundingMode) String() string {
+	if m < 0 || int(m) >= len(roundingModeNames) {
+		return "unknown"
+	}
+	return roundingModeNames[m]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m RoundingMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *RoundingMode) UnmarshalText(text []byte) error {
+	mode, err := ParseRoundingMode(string(text))
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// Round returns the value rounded according to the mode to the given number of digits after the decimal separator.
+// It may return ErrTooLarge.
+func (m RoundingMode) Round(value *big.Rat, precision byte) (Decimal, error) {
+	num := new(big.Int).Mul(value.Num(), bigPow10(int(precision)))
+	subunits := m.quo(num, value.Denom())
+
+	if subunits.CmpAbs(big.NewInt(maxDecimal)) > 0 {
+		return Decimal{}, ErrTooLarge
+	}
+
+	return Decimal{subunits: subunits.Int64(), precision: precision}, nil
+}
+
+// quo returns num/den rounded according to the mode.
+func (m RoundingMode) quo(num, den *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	// awayFromZero is -1 or +1, the direction of the next value after the truncated quotient.
+	awayFromZero := int64(num.Sign() * den.Sign())
+	doubled := new(big.Int).Mul(remainder, big.NewInt(2))
+
+	var roundAway bool
+	switch m {
+	case RoundHalfUp:
+		roundAway = doubled.CmpAbs(den) >= 0
+	case RoundHalfEven:
+		cmp := doubled.CmpAbs(den)
+		roundAway = cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1)
+	case RoundUp:
+		roundAway = true
+	}
+
+	if roundAway {
+		quotient.Add(quotient, big.NewInt(awayFromZero))
+	}
+
+	return quotient
+}
@@ -0,0 +1,65 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestRoundingMode_quo(t *testing.T) {
+	tt := map[string]struct {
+		num, den int64
+		want     map[RoundingMode]int64
+	}{
+		"below half":   {num: 12, den: 10, want: map[RoundingMode]int64{RoundDown: 1, RoundHalfUp: 1, RoundHalfEven: 1, RoundUp: 2}},
+		"half to odd":  {num: 15, den: 10, want: map[RoundingMode]int64{RoundDown: 1, RoundHalfUp: 2, RoundHalfEven: 2, RoundUp: 2}},
+		"half to even": {num: 25, den: 10, want: map[RoundingMode]int64{RoundDown: 2, RoundHalfUp: 3, RoundHalfEven: 2, RoundUp: 3}},
+		"negative":     {num: -25, den: 10, want: map[RoundingMode]int64{RoundDown: -2, RoundHalfUp: -3, RoundHalfEven: -2, RoundUp: -3}},
+		"exact":        {num: 30, den: 10, want: map[RoundingMode]int64{RoundDown: 3, RoundHalfUp: 3, RoundHalfEven: 3, RoundUp: 3}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			for mode, want := range tc.want {
+				got := mode.quo(big.NewInt(tc.num), big.NewInt(tc.den))
+				if got.Int64() != want {
+					t.Errorf("%s: expected %d, got %d", mode, want, got.Int64())
+				}
+			}
+		})
+	}
+}
+
+func TestRoundingMode_Round(t *testing.T) {
+	tt := map[string]struct {
+		value    *big.Rat
+		mode     RoundingMode
+		expected Decimal
+		err      error
+	}{
+		"exact":              {value: big.NewRat(3, 2), mode: RoundDown, expected: Decimal{subunits: 150, precision: 2}},
+		"third down":         {value: big.NewRat(100, 3), mode: RoundDown, expected: Decimal{subunits: 3333, precision: 2}},
+		"two thirds half up": {value: big.NewRat(-2, 3), mode: RoundHalfUp, expected: Decimal{subunits: -67, precision: 2}},
+		"half even":          {value: big.NewRat(25, 1000), mode: RoundHalfEven, expected: Decimal{subunits: 2, precision: 2}},
+		"too large":          {value: big.NewRat(1e13, 1), mode: RoundDown, err: ErrTooLarge},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.mode.Round(tc.value, 2)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDecimal_Rat(t *testing.T) {
+	got := Decimal{subunits: -1525, precision: 3}.Rat()
+	if got.Cmp(big.NewRat(-61, 40)) != 0 {
+		t.Errorf("expected -61/40, got %s", got)
+	}
+}
@@ -0,0 +1,314 @@
+package money
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// ErrNotEnoughRates is returned when a series holds too few rates for a statistic.
+	ErrNotEnoughRates = Error("not enough rates in the series")
+	// ErrDuplicateDate is returned when a series holds two rates for the same date.
+	ErrDuplicateDate = Error("duplicate date in the series")
+	// ErrDateNotFound is returned when a series holds no rate for a date.
+	ErrDateNotFound = Error("no rate for the date in the series")
+)
+
+// RatePoint is the exchange rate of a given date.
+type RatePoint struct {
+	Date time.Time
+	Rate ExchangeRate
+}
+
+// RateSeries holds exchange rates of a currency pair, ordered by date, oldest first.
+type RateSeries struct {
+	points []RatePoint
+}
+
+// Interval is the length of the periods a RateSeries is resampled to.
+type Interval int
+
+const (
+	// Weekly periods start on Mondays.
+	Weekly Interval = iota + 1
+	// Monthly periods start on the first day of the month.
+	Monthly
+)
+
+// NewRateSeries returns a RateSeries of the given points, in any order, and may return ErrDuplicateDate.
+func NewRateSeries(points []RatePoint) (RateSeries, error) {
+	sorted := make([]RatePoint, len(points))
+	copy(sorted, points)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Date.Equal(sorted[i-1].Date) {
+			return RateSeries{}, fmt.Errorf("%w: %s", ErrDuplicateDate, sorted[i].Date.Format(time.DateOnly))
+		}
+	}
+
+	return RateSeries{points: sorted}, nil
+}
+
+// Len returns the number of rates of the series.
+func (s RateSeries) Len() int {
+	return len(s.points)
+}
+
+// Points returns the rates of the series, oldest first.
+func (s RateSeries) Points() []RatePoint {
+	points := make([]RatePoint, len(s.points))
+	copy(points, s.points)
+	return points
+}
+
+// At returns the rate of the given date, and may return ErrDateNotFound.
+func (s RateSeries) At(date time.Time) (ExchangeRate, error) {
+	i := sort.Search(len(s.points), func(i int) bool {
+		return !s.points[i].Date.Before(date)
+	})
+
+	if i == len(s.points) || !s.points[i].Date.Equal(date) {
+		return ExchangeRate{}, fmt.Errorf("%w: %s", ErrDateNotFound, date.Format(time.DateOnly))
+	}
+
+	return s.points[i].Rate, nil
+}
+
+// Min returns the lowest rate of the series, the earliest one in case of a tie.
+func (s RateSeries) Min() (RatePoint, error) {
+	return s.extreme(-1)
+}
+
+// Max returns the highest rate of the series, the earliest one in case of a tie.
+func (s RateSeries) Max() (RatePoint, error) {
+	return s.extreme(+1)
+}
+
+// extreme returns the point whose rate compares to all the others with the given sign.
+func (s RateSeries) extreme(sign int) (RatePoint, error) {
+	if len(s.points) == 0 {
+		return RatePoint{}, ErrNotEnoughRates
+	}
+
+	best := s.points[0]
+	for _, p := range s.points[1:] {
+		if compare(Decimal(p.Rate), Decimal(best.Rate)) == sign {
+			best = p
+		}
+	}
+
+	return best, nil
+}
+
+// Mean returns the arithmetic mean of the rates of the series.
+func (s RateSeries) Mean() (ExchangeRate, error) {
+	if len(s.points) == 0 {
+		return ExchangeRate{}, ErrNotEnoughRates
+	}
+
+	m, err := mean(s.values(), maxRatePrecision)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+
+	return ExchangeRate(m), nil
+}
+
+// Median returns the middle rate of the series, or the mean of the two middle rates for an even number of rates.
+func (s RateSeries) Median() (ExchangeRate, error) {
+	if len(s.points) == 0 {
+		return ExchangeRate{}, ErrNotEnoughRates
+	}
+
+	values := s.values()
+	sort.Slice(values, func(i, j int) bool {
+		return compare(values[i], values[j]) < 0
+	})
+
+	middle := len(values) / 2
+	if len(values)%2 == 1 {
+		return ExchangeRate(values[middle]), nil
+	}
+
+	m, err := mean(values[middle-1:middle+1], maxRatePrecision)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+
+	return ExchangeRate(m), nil
+}
+
+// PercentChange returns the change of the rate between two dates of the series, in percent of the rate at from.
+func (s RateSeries) PercentChange(from, to time.Time) (Decimal, error) {
+	start, err := s.At(from)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	end, err := s.At(to)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	change, err := subtract(Decimal(end), Decimal(start))
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	// a percentage is the ratio multiplied by 10^2.
+	hundredfold, err := fitDecimal(change.scaled(change.precision+2), change.precision)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	return divide(hundredfold, Decimal(start), maxRatePrecision)
+}
+
+// MovingAverage returns the simple moving average of the rates over window consecutive rates.
+// Each average is dated as the last rate of its window.
+func (s RateSeries) MovingAverage(window int) (RateSeries, error) {
+	if window <= 0 || window > len(s.points) {
+		return RateSeries{}, fmt.Errorf("%w: window of %d rates over %d rates", ErrNotEnoughRates, window, len(s.points))
+	}
+
+	values := s.values()
+	averages := make([]RatePoint, 0, len(s.points)-window+1)
+
+	for end := window; end <= len(values); end++ {
+		m, err := mean(values[end-window:end], maxRatePrecision)
+		if err != nil {
+			return RateSeries{}, err
+		}
+		averages = append(averages, RatePoint{Date: s.points[end-1].Date, Rate: ExchangeRate(m)})
+	}
+
+	return RateSeries{points: averages}, nil
+}
+
+// Volatility returns the sample standard deviation of the logarithmic returns between consecutive rates.
+// Logarithms have no exact decimal representation: the computation uses floats and the result is rounded to 10 digits.
+func (s RateSeries) Volatility() (Decimal, error) {
+	// a standard deviation needs at least 2 returns.
+	if len(s.points) < 3 {
+		return Decimal{}, ErrNotEnoughRates
+	}
+
+	returns := make([]float64, 0, len(s.points)-1)
+	for i := 1; i < len(s.points); i++ {
+		previous, current := Decimal(s.points[i-1].Rate).float(), Decimal(s.points[i].Rate).float()
+		if previous <= 0 || current <= 0 {
+			return Decimal{}, fmt.Errorf("%w: rates must be positive", ErrInvalidDecimal)
+		}
+		returns = append(returns, math.Log(current/previous))
+	}
+
+	var sum float64
+	for _, r := range returns {
+		sum += r
+	}
+	average := sum / float64(len(returns))
+
+	var squares float64
+	for _, r := range returns {
+		squares += (r - average) * (r - average)
+	}
+
+	stdev := math.Sqrt(squares / float64(len(returns)-1))
+
+	return ParseDecimal(strconv.FormatFloat(stdev, 'f', maxRatePrecision, 64))
+}
+
+// FillGaps returns a series with a rate for every calendar day between its first and last dates.
+// Days without a publication, such as week-ends, take the rate of the previous publication.
+func (s RateSeries) FillGaps() RateSeries {
+	if len(s.points) == 0 {
+		return s
+	}
+
+	filled := make([]RatePoint, 0, len(s.points))
+	for i, p := range s.points {
+		if i > 0 {
+			previous := s.points[i-1]
+			for day := previous.Date.AddDate(0, 0, 1); day.Before(p.Date); day = day.AddDate(0, 0, 1) {
+				filled = append(filled, RatePoint{Date: day, Rate: previous.Rate})
+			}
+		}
+		filled = append(filled, p)
+	}
+
+	return RateSeries{points: filled}
+}
+
+// Resample returns a series holding the mean of the rates of each period of the interval.
+// Each mean is dated as the first day of its period.
+func (s RateSeries) Resample(interval Interval) (RateSeries, error) {
+	var resampled []RatePoint
+	var period []Decimal
+	var periodStart time.Time
+
+	flush := func() error {
+		if len(period) == 0 {
+			return nil
+		}
+		m, err := mean(period, maxRatePrecision)
+		if err != nil {
+			return err
+		}
+		resampled = append(resampled, RatePoint{Date: periodStart, Rate: ExchangeRate(m)})
+		period = period[:0]
+		return nil
+	}
+
+	for _, p := range s.points {
+		start, err := interval.periodStart(p.Date)
+		if err != nil {
+			return RateSeries{}, err
+		}
+
+		if !start.Equal(periodStart) {
+			if err := flush(); err != nil {
+				return RateSeries{}, err
+			}
+			periodStart = start
+		}
+		period = append(period, Decimal(p.Rate))
+	}
+
+	if err := flush(); err != nil {
+		return RateSeries{}, err
+	}
+
+	return RateSeries{points: resampled}, nil
+}
+
+// periodStart returns the first day of the period of the interval containing the date.
+func (i Interval) periodStart(date time.Time) (time.Time, error) {
+	year, month, day := date.Date()
+
+	switch i {
+	case Weekly:
+		// time.Sunday is 0, count Sundays as the 7th day of the week.
+		sinceMonday := (int(date.Weekday()) + 6) % 7
+		return time.Date(year, month, day-sinceMonday, 0, 0, 0, 0, date.Location()), nil
+	case Monthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, date.Location()), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported interval %d", int(i))
+	}
+}
+
+// values returns the rates of the series as Decimals.
+func (s RateSeries) values() []Decimal {
+	values := make([]Decimal, len(s.points))
+	for i, p := range s.points {
+		values[i] = Decimal(p.Rate)
+	}
+	return values
+}
@@ -0,0 +1,218 @@
+package money_test
+
+import (
+	"errors"
+	"moneyconverter/money"
+	"testing"
+	"time"
+)
+
+func TestRateSeries_Statistics(t *testing.T) {
+	series := mustRateSeries(t, map[string]string{
+		"2024-01-08": "1.4",
+		"2024-01-01": "1.0",
+		"2024-01-02": "1.2",
+		"2024-01-03": "1.1",
+		"2024-01-05": "1.3",
+	})
+
+	minimum, err := series.Min()
+	if err != nil || minimum.Rate.String() != "1" || !minimum.Date.Equal(mustParseDate(t, "2024-01-01")) {
+		t.Errorf("expected min 1 on 2024-01-01, got %v, %v", minimum, err)
+	}
+
+	maximum, err := series.Max()
+	if err != nil || maximum.Rate.String() != "1.4" || !maximum.Date.Equal(mustParseDate(t, "2024-01-08")) {
+		t.Errorf("expected max 1.4 on 2024-01-08, got %v, %v", maximum, err)
+	}
+
+	if mean, err := series.Mean(); err != nil || mean.String() != "1.2" {
+		t.Errorf("expected mean 1.2, got %v, %v", mean, err)
+	}
+
+	if median, err := series.Median(); err != nil || median.String() != "1.2" {
+		t.Errorf("expected median 1.2, got %v, %v", median, err)
+	}
+
+	if volatility, err := series.Volatility(); err != nil || volatility.String() != "0.1237036742" {
+		t.Errorf("expected volatility 0.1237036742, got %v, %v", volatility, err)
+	}
+}
+
+func TestRateSeries_Median_EvenCount(t *testing.T) {
+	series := mustRateSeries(t, map[string]string{
+		"2024-01-01": "1.0",
+		"2024-01-02": "1.3",
+		"2024-01-03": "1.1",
+		"2024-01-04": "1.2",
+	})
+
+	if median, err := series.Median(); err != nil || median.String() != "1.15" {
+		t.Errorf("expected median 1.15, got %v, %v", median, err)
+	}
+}
+
+func TestRateSeries_PercentChange(t *testing.T) {
+	series := mustRateSeries(t, map[string]string{
+		"2024-01-01": "1.0",
+		"2024-01-02": "1.2",
+		"2024-01-03": "1.1",
+	})
+
+	tt := map[string]struct {
+		from string
+		to   string
+		want string
+		err  error
+	}{
+		"increase":     {from: "2024-01-01", to: "2024-01-02", want: "20"},
+		"decrease":     {from: "2024-01-02", to: "2024-01-03", want: "-8.3333333333"},
+		"no change":    {from: "2024-01-03", to: "2024-01-03", want: "0"},
+		"missing date": {from: "2024-01-01", to: "2024-01-04", err: money.ErrDateNotFound},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := series.PercentChange(mustParseDate(t, tc.from), mustParseDate(t, tc.to))
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if tc.err == nil && got.String() != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got.String())
+			}
+		})
+	}
+}
+
+func TestRateSeries_MovingAverage(t *testing.T) {
+	series := mustRateSeries(t, map[string]string{
+		"2024-01-01": "1.0",
+		"2024-01-02": "1.2",
+		"2024-01-03": "1.1",
+		"2024-01-05": "1.3",
+	})
+
+	got, err := series.MovingAverage(2)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	assertSeries(t, got, []string{"2024-01-02 1.1", "2024-01-03 1.15", "2024-01-05 1.2"})
+
+	if _, err := series.MovingAverage(5); !errors.Is(err, money.ErrNotEnoughRates) {
+		t.Errorf("expected error %v, got %v", money.ErrNotEnoughRates, err)
+	}
+}
+
+func TestRateSeries_FillGaps(t *testing.T) {
+	series := mustRateSeries(t, map[string]string{
+		"2024-01-05": "1.3",
+		"2024-01-08": "1.4",
+	})
+
+	assertSeries(t, series.FillGaps(), []string{"2024-01-05 1.3", "2024-01-06 1.3", "2024-01-07 1.3", "2024-01-08 1.4"})
+}
+
+func TestRateSeries_Resample(t *testing.T) {
+	series := mustRateSeries(t, map[string]string{
+		"2024-01-01": "1.0",
+		"2024-01-02": "1.2",
+		"2024-01-03": "1.1",
+		"2024-01-05": "1.3",
+		"2024-01-08": "1.4",
+		"2024-02-01": "1.6",
+	})
+
+	weekly, err := series.Resample(money.Weekly)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	assertSeries(t, weekly, []string{"2024-01-01 1.15", "2024-01-08 1.4", "2024-01-29 1.6"})
+
+	monthly, err := series.Resample(money.Monthly)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	assertSeries(t, monthly, []string{"2024-01-01 1.2", "2024-02-01 1.6"})
+}
+
+func TestNewRateSeries_DuplicateDate(t *testing.T) {
+	rate := money.ExchangeRate(mustParseDecimal(t, "1"))
+	_, err := money.NewRateSeries([]money.RatePoint{
+		{Date: mustParseDate(t, "2024-01-01"), Rate: rate},
+		{Date: mustParseDate(t, "2024-01-01"), Rate: rate},
+	})
+
+	if !errors.Is(err, money.ErrDuplicateDate) {
+		t.Errorf("expected error %v, got %v", money.ErrDuplicateDate, err)
+	}
+}
+
+func TestRateSeries_Empty(t *testing.T) {
+	var series money.RateSeries
+
+	if _, err := series.Mean(); !errors.Is(err, money.ErrNotEnoughRates) {
+		t.Errorf("expected error %v, got %v", money.ErrNotEnoughRates, err)
+	}
+
+	if _, err := series.Volatility(); !errors.Is(err, money.ErrNotEnoughRates) {
+		t.Errorf("expected error %v, got %v", money.ErrNotEnoughRates, err)
+	}
+}
+
+// mustRateSeries builds a series out of rates indexed by their date.
+func mustRateSeries(t *testing.T, rates map[string]string) money.RateSeries {
+	t.Helper()
+
+	points := make([]money.RatePoint, 0, len(rates))
+	for date, rate := range rates {
+		points = append(points, money.RatePoint{Date: mustParseDate(t, date), Rate: money.ExchangeRate(mustParseDecimal(t, rate))})
+	}
+
+	series, err := money.NewRateSeries(points)
+	if err != nil {
+		t.Fatalf("cannot build series: %v", err)
+	}
+
+	return series
+}
+
+// assertSeries checks the points of a series, formatted as "date rate".
+func assertSeries(t *testing.T, series money.RateSeries, want []string) {
+	t.Helper()
+
+	points := series.Points()
+	if len(points) != len(want) {
+		t.Fatalf("expected %d points, got %v", len(want), points)
+	}
+
+	for i, p := range points {
+		if got := p.Date.Format(time.DateOnly) + " " + p.Rate.String(); got != want[i] {
+			t.Errorf("expected point %q, got %q", want[i], got)
+		}
+	}
+}
+
+func mustParseDate(t *testing.T, date string) time.Time {
+	t.Helper()
+
+	d, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		t.Fatalf("invalid date: %s", date)
+	}
+
+	return d
+}
+
+func mustParseDecimal(t *testing.T, value string) money.Decimal {
+	t.Helper()
+
+	d, err := money.ParseDecimal(value)
+	if err != nil {
+		t.Fatalf("invalid number: %s", value)
+	}
+
+	return d
+}
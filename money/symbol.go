@@ -0,0 +1,89 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrAmbiguousCurrency is returned when a symbol is shared by several currencies and none of them is preferred.
+const ErrAmbiguousCurrency = Error("ambiguous currency symbol")
+
+// currencySymbols holds the ISO codes of the currencies written with each symbol, the most traded first.
+// Symbols made of letters are lowercase, as they are looked up regardless of their case.
+var currencySymbols = map[string][]string{
+	"$":   {"USD", "CAD", "AUD", "NZD", "HKD", "SGD", "MXN"},
+	"us$": {"USD"},
+	"c$":  {"CAD"},
+	"ca$": {"CAD"},
+	"a$":  {"AUD"},
+	"au$": {"AUD"},
+	"nz$": {"NZD"},
+	"hk$": {"HKD"},
+	"s$":  {"SGD"},
+	"mx$": {"MXN"},
+	"r$":  {"BRL"},
+	"€":   {"EUR"},
+	"£":   {"GBP"},
+	"¥":   {"JPY", "CNY"},
+	"円":   {"JPY"},
+	"元":   {"CNY"},
+	"₹":   {"INR"},
+	"₩":   {"KRW"},
+	"₽":   {"RUB"},
+	"₺":   {"TRY"},
+	"₪":   {"ILS"},
+	"₫":   {"VND"},
+	"₱":   {"PHP"},
+	"₴":   {"UAH"},
+	"₦":   {"NGN"},
+	"฿":   {"THB"},
+	"₡":   {"CRC"},
+	"zł":  {"PLN"},
+	"kč":  {"CZK"},
+	"ft":  {"HUF"},
+	"lei": {"RON"},
+	"fr":  {"CHF"},
+	"kr":  {"SEK", "NOK", "DKK", "ISK"},
+}
+
+// ParseCurrencySymbol returns the currency of a symbol such as € or of an ISO code such as EUR.
+// A symbol shared by several currencies, such as $, resolves to the first of the preferred currencies it may stand for,
+// and returns ErrAmbiguousCurrency when there is none. Anything else may return ErrInvalidCurrencyCode.
+func ParseCurrencySymbol(symbol string, preferred ...Currency) (Currency, error) {
+	codes, ok := currencySymbols[strings.ToLower(symbol)]
+	if !ok {
+		return ParseCurrency(symbol)
+	}
+
+	if len(codes) == 1 {
+		return ParseCurrency(codes[0])
+	}
+
+	for _, p := range preferred {
+		for _, code := range codes {
+			if p.code == code {
+				return p, nil
+			}
+		}
+	}
+
+	return Currency{}, fmt.Errorf("%w: %s could be %s", ErrAmbiguousCurrency, symbol, strings.Join(codes, ", "))
+}
+
+// CurrenciesOfSymbol returns the currencies written with a symbol, the most traded first, or nil for an unknown symbol.
+func CurrenciesOfSymbol(symbol string) []Currency {
+	codes := currencySymbols[strings.ToLower(symbol)]
+	if codes == nil {
+		return nil
+	}
+
+	currencies := make([]Currency, 0, len(codes))
+	for _, code := range codes {
+		currency, err := ParseCurrency(code)
+		if err == nil {
+			currencies = append(currencies, currency)
+		}
+	}
+
+	return currencies
+}
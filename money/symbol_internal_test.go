@@ -0,0 +1,56 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCurrencySymbol(t *testing.T) {
+	usd := Currency{code: "USD", precision: 2}
+	cad := Currency{code: "CAD", precision: 2}
+
+	tt := map[string]struct {
+		symbol    string
+		preferred []Currency
+		expected  Currency
+		err       error
+	}{
+		"unique symbol":           {symbol: "€", expected: Currency{code: "EUR", precision: 2}},
+		"letters symbol any case": {symbol: "ZŁ", expected: Currency{code: "PLN", precision: 2}},
+		"prefixed dollar":         {symbol: "C$", expected: cad},
+		"iso code":                {symbol: "gbp", expected: Currency{code: "GBP", precision: 2}},
+		"preferred dollar":        {symbol: "$", preferred: []Currency{cad}, expected: cad},
+		"first matching preferred": {
+			symbol:    "$",
+			preferred: []Currency{{code: "EUR", precision: 2}, usd, cad},
+			expected:  usd,
+		},
+		"ambiguous dollar":       {symbol: "$", err: ErrAmbiguousCurrency},
+		"unrelated preference":   {symbol: "kr", preferred: []Currency{usd}, err: ErrAmbiguousCurrency},
+		"unknown symbol":         {symbol: "¤", err: ErrInvalidCurrencyCode},
+		"invalid code or symbol": {symbol: "EURO", err: ErrInvalidCurrencyCode},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseCurrencySymbol(tc.symbol, tc.preferred...)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCurrenciesOfSymbol(t *testing.T) {
+	got := CurrenciesOfSymbol("¥")
+	if len(got) != 2 || got[0].code != "JPY" || got[1].code != "CNY" {
+		t.Errorf("expected JPY and CNY, got %v", got)
+	}
+
+	if got := CurrenciesOfSymbol("EUR"); got != nil {
+		t.Errorf("expected no currencies for a code, got %v", got)
+	}
+}
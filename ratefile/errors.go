@@ -0,0 +1,9 @@
+package ratefile
+
+// rateFileError defines a sentinel error.
+type rateFileError string
+
+// rateFileError implements the error interface.
+func (e rateFileError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,119 @@
+package ratefile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"moneyconverter/ecbank"
+	"moneyconverter/money"
+	"strings"
+	"time"
+)
+
+// dateLayout is the format of the dates in rates files.
+const dateLayout = "2006-01-02"
+
+// csvHeader is the optional first line of a CSV rates file.
+var csvHeader = []string{"base", "quote", "rate", "date"}
+
+// ReadCSV reads quotes formatted as base,quote,rate,date records, with an optional header line.
+// The separator is usually ',' or '\t'.
+func ReadCSV(r io.Reader, separator rune) (Provider, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = separator
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+
+	var quotes []Quote
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Provider{}, fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+		}
+
+		if line == 1 && strings.EqualFold(strings.Join(record, ","), strings.Join(csvHeader, ",")) {
+			continue
+		}
+
+		q, err := parseQuote(record[0], record[1], record[2], record[3])
+		if err != nil {
+			return Provider{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		quotes = append(quotes, q)
+	}
+
+	return NewProvider(quotes)
+}
+
+// jsonQuote is a quote as written in a JSON rates file.
+type jsonQuote struct {
+	Base  string      `json:"base"`
+	Quote string      `json:"quote"`
+	Rate  json.Number `json:"rate"`
+	Date  string      `json:"date"`
+}
+
+// ReadJSON reads quotes formatted as a JSON array of {"base", "quote", "rate", "date"} objects.
+// Rates may be written as numbers or strings, the latter avoiding any loss of precision.
+func ReadJSON(r io.Reader) (Provider, error) {
+	var records []jsonQuote
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return Provider{}, fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+	}
+
+	quotes := make([]Quote, 0, len(records))
+	for i, record := range records {
+		q, err := parseQuote(record.Base, record.Quote, record.Rate.String(), record.Date)
+		if err != nil {
+			return Provider{}, fmt.Errorf("record %d: %w", i, err)
+		}
+		quotes = append(quotes, q)
+	}
+
+	return NewProvider(quotes)
+}
+
+// ReadECB reads the latest rates of a feed of the European Central Bank, such as eurofxref-daily.xml.
+func ReadECB(r io.Reader) (Provider, error) {
+	table, err := ecbank.ReadRateTable(r)
+	if err != nil {
+		return Provider{}, fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+	}
+
+	quotes := make([]Quote, 0, len(table.Rates))
+	for currency, rate := range table.Rates {
+		quotes = append(quotes, Quote{Base: table.Base, Quote: currency, Rate: rate, Date: table.Date})
+	}
+
+	return NewProvider(quotes)
+}
+
+// parseQuote builds a Quote out of its textual fields.
+func parseQuote(base, quote, rate, date string) (Quote, error) {
+	baseCurrency, err := money.ParseCurrency(strings.TrimSpace(base))
+	if err != nil {
+		return Quote{}, fmt.Errorf("%w: base currency %q: %s", ErrInvalidRecord, base, err)
+	}
+
+	quoteCurrency, err := money.ParseCurrency(strings.TrimSpace(quote))
+	if err != nil {
+		return Quote{}, fmt.Errorf("%w: quote currency %q: %s", ErrInvalidRecord, quote, err)
+	}
+
+	value, err := money.ParseDecimal(strings.TrimSpace(rate))
+	if err != nil {
+		return Quote{}, fmt.Errorf("%w: rate %q: %s", ErrInvalidRecord, rate, err)
+	}
+
+	day, err := time.Parse(dateLayout, strings.TrimSpace(date))
+	if err != nil {
+		return Quote{}, fmt.Errorf("%w: date %q: %s", ErrInvalidRecord, date, err)
+	}
+
+	return Quote{Base: baseCurrency, Quote: quoteCurrency, Rate: money.ExchangeRate(value), Date: day}, nil
+}
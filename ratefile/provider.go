@@ -0,0 +1,188 @@
+package ratefile
+
+import (
+	"errors"
+	"fmt"
+	"moneyconverter/money"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ErrUnknownFormat      = rateFileError("unknown rates file format")
+	ErrInvalidRecord      = rateFileError("invalid rate record")
+	ErrChangeRateNotFound = rateFileError("couldn't find the exchange rate")
+)
+
+// Quote is the rate of a currency pair published on a given day: one unit of Base is worth Rate units of Quote.
+type Quote struct {
+	Base  money.Currency
+	Quote money.Currency
+	Rate  money.ExchangeRate
+	Date  time.Time
+}
+
+// providerName names the provider in the paths it finds.
+const providerName = "ratefile"
+
+// pair identifies a currency pair.
+type pair struct {
+	base, quote money.Currency
+}
+
+// Provider serves exchange rates read from a local file, without any call over the network.
+type Provider struct {
+	// quotes holds the latest quote of each pair.
+	quotes map[pair]Quote
+	// graph chains the quotes to link any two currencies.
+	graph *money.RateGraph
+}
+
+// NewProvider returns a Provider serving the given quotes.
+// When a pair is quoted several times, the most recent quote is used.
+func NewProvider(quotes []Quote) (Provider, error) {
+	p := Provider{
+		quotes: make(map[pair]Quote, len(quotes)),
+		graph:  &money.RateGraph{},
+	}
+
+	for _, q := range quotes {
+		key := pair{base: q.Base, quote: q.Quote}
+		if existing, ok := p.quotes[key]; ok && existing.Date.After(q.Date) {
+			continue
+		}
+		p.quotes[key] = q
+	}
+
+	for _, q := range p.Quotes() {
+		if err := p.graph.AddRate(q.Base, q.Quote, q.Rate, providerName); err != nil {
+			return Provider{}, fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+		}
+	}
+
+	return p, nil
+}
+
+// Load reads a rates file, whose format is chosen by its extension: .csv, .tsv, .json or .xml for a feed of the ECB.
+func Load(path string) (Provider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Provider{}, fmt.Errorf("couldn't open rates file: %w", err)
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return ReadCSV(f, ',')
+	case ".tsv":
+		return ReadCSV(f, '\t')
+	case ".json":
+		return ReadJSON(f)
+	case ".xml":
+		return ReadECB(f)
+	default:
+		return Provider{}, fmt.Errorf("%w: %q", ErrUnknownFormat, ext)
+	}
+}
+
+// Quotes returns the latest quote of every pair of the provider, sorted by pair.
+func (p Provider) Quotes() []Quote {
+	quotes := make([]Quote, 0, len(p.quotes))
+	for _, q := range p.quotes {
+		quotes = append(quotes, q)
+	}
+
+	sort.Slice(quotes, func(i, j int) bool {
+		if quotes[i].Base != quotes[j].Base {
+			return quotes[i].Base.ISOCode() < quotes[j].Base.ISOCode()
+		}
+		return quotes[i].Quote.ISOCode() < quotes[j].Quote.ISOCode()
+	})
+
+	return quotes
+}
+
+// SupportedCurrencies returns the currencies of the quotes, sorted by code.
+func (p Provider) SupportedCurrencies() []money.Currency {
+	seen := make(map[money.Currency]bool)
+	var currencies []money.Currency
+	for key := range p.quotes {
+		for _, currency := range []money.Currency{key.base, key.quote} {
+			if !seen[currency] {
+				seen[currency] = true
+				currencies = append(currencies, currency)
+			}
+		}
+	}
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].ISOCode() < currencies[j].ISOCode()
+	})
+
+	return currencies
+}
+
+// FetchExchangeRate returns the ExchangeRate from source to target.
+// The pair may be quoted directly, in the opposite direction, or through any chain of quotes.
+func (p Provider) FetchExchangeRate(source, target money.Currency) (money.ExchangeRate, error) {
+	path, err := p.FindPath(source, target)
+	if err != nil {
+		return money.ExchangeRate{}, err
+	}
+
+	return path.Rate, nil
+}
+
+// FetchRateInfo returns the ExchangeRate from source to target, along with the chain of quotes
+// and the date of the oldest quote it was computed from.
+func (p Provider) FetchRateInfo(source, target money.Currency) (money.RateInfo, error) {
+	path, err := p.FindPath(source, target)
+	if err != nil {
+		return money.RateInfo{}, err
+	}
+
+	var published time.Time
+	for _, step := range path.Steps {
+		key := pair{base: step.From, quote: step.To}
+		if step.Inverted {
+			key = pair{base: step.To, quote: step.From}
+		}
+
+		if date := p.quotes[key].Date; published.IsZero() || date.Before(published) {
+			published = date
+		}
+	}
+
+	return money.RateInfo{Rate: path.Rate, Provider: providerName, PublishedAt: published, Path: path}, nil
+}
+
+// AddTo records every quote of the provider into the graph, so that they can be chained with the rates of other providers.
+func (p Provider) AddTo(graph *money.RateGraph, provider string) error {
+	for _, q := range p.Quotes() {
+		if err := graph.AddRate(q.Base, q.Quote, q.Rate, provider); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindPath returns the chain of quotes used to convert from source to target.
+func (p Provider) FindPath(source, target money.Currency) (money.Path, error) {
+	if p.graph == nil {
+		return money.Path{}, fmt.Errorf("%w from %s to %s", ErrChangeRateNotFound, source, target)
+	}
+
+	path, err := p.graph.FindPath(source, target)
+	if errors.Is(err, money.ErrNoPath) {
+		return money.Path{}, fmt.Errorf("%w: %w", ErrChangeRateNotFound, err)
+	}
+	if err != nil {
+		return money.Path{}, err
+	}
+
+	return path, nil
+}
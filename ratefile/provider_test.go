@@ -0,0 +1,188 @@
+package ratefile_test
+
+import (
+	"errors"
+	"moneyconverter/money"
+	"moneyconverter/ratefile"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const csvRates = `base,quote,rate,date
+EUR,USD,2,2025-04-07
+EUR,USD,2.5,2025-04-08
+EUR,RON,5,2025-04-08
+`
+
+const jsonRates = `[
+	{"base": "EUR", "quote": "USD", "rate": "2.5", "date": "2025-04-08"},
+	{"base": "EUR", "quote": "RON", "rate": 5, "date": "2025-04-08"}
+]`
+
+const ecbRates = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time='2025-04-08'>
+			<Cube currency='USD' rate='2.5'/>
+			<Cube currency='RON' rate='5'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestLoad(t *testing.T) {
+	tt := map[string]struct {
+		filename string
+		content  string
+	}{
+		"csv":  {filename: "rates.csv", content: csvRates},
+		"tsv":  {filename: "rates.tsv", content: strings.ReplaceAll(csvRates, ",", "\t")},
+		"json": {filename: "rates.json", content: jsonRates},
+		"ecb":  {filename: "eurofxref-daily.xml", content: ecbRates},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.filename)
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("cannot write rates file: %v", err)
+			}
+
+			provider, err := ratefile.Load(path)
+			if err != nil {
+				t.Fatalf("unexpected error, %v", err)
+			}
+
+			got, err := provider.FetchExchangeRate(mustParseCurrency(t, "EUR"), mustParseCurrency(t, "USD"))
+			if err != nil {
+				t.Fatalf("unexpected error, %v", err)
+			}
+
+			if got.String() != "2.5" {
+				t.Errorf("expected rate 2.5, got %s", got)
+			}
+		})
+	}
+}
+
+func TestLoad_UnknownFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	if err := os.WriteFile(path, []byte(csvRates), 0o644); err != nil {
+		t.Fatalf("cannot write rates file: %v", err)
+	}
+
+	_, err := ratefile.Load(path)
+	if !errors.Is(err, ratefile.ErrUnknownFormat) {
+		t.Errorf("expected error %v, got %v", ratefile.ErrUnknownFormat, err)
+	}
+}
+
+func TestReadCSV_InvalidRecord(t *testing.T) {
+	tt := map[string]string{
+		"invalid currency": "EUR,US,2,2025-04-08\n",
+		"invalid rate":     "EUR,USD,two,2025-04-08\n",
+		"invalid date":     "EUR,USD,2,08/04/2025\n",
+		"missing field":    "EUR,USD,2\n",
+	}
+
+	for name, content := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := ratefile.ReadCSV(strings.NewReader(content), ',')
+			if !errors.Is(err, ratefile.ErrInvalidRecord) {
+				t.Errorf("expected error %v, got %v", ratefile.ErrInvalidRecord, err)
+			}
+		})
+	}
+}
+
+func TestProvider_FetchExchangeRate(t *testing.T) {
+	provider, err := ratefile.ReadCSV(strings.NewReader(csvRates), ',')
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	tt := map[string]struct {
+		source string
+		target string
+		want   string
+		err    error
+	}{
+		"latest direct quote": {source: "EUR", target: "USD", want: "2.5"},
+		"inverse quote":       {source: "USD", target: "EUR", want: "0.4"},
+		"common base":         {source: "USD", target: "RON", want: "2"},
+		"same currency":       {source: "CHF", target: "CHF", want: "1"},
+		"unknown pair":        {source: "USD", target: "CHF", err: ratefile.ErrChangeRateNotFound},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := provider.FetchExchangeRate(mustParseCurrency(t, tc.source), mustParseCurrency(t, tc.target))
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if tc.err == nil && got.String() != tc.want {
+				t.Errorf("expected rate %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestProvider_FetchRateInfo(t *testing.T) {
+	const content = `base,quote,rate,date
+EUR,USD,2.5,2025-04-08
+EUR,RON,5,2025-04-01
+`
+	provider, err := ratefile.ReadCSV(strings.NewReader(content), ',')
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	got, err := provider.FetchRateInfo(mustParseCurrency(t, "USD"), mustParseCurrency(t, "RON"))
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	if got.Rate.String() != "2" || got.Provider != "ratefile" {
+		t.Errorf("expected rate 2 from ratefile, got %s from %q", got.Rate, got.Provider)
+	}
+
+	if got.Path.String() != "USD→EUR→RON" {
+		t.Errorf("expected path USD→EUR→RON, got %s", got.Path)
+	}
+
+	// the rate is only as recent as the oldest quote it was computed from.
+	if want := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC); !got.PublishedAt.Equal(want) {
+		t.Errorf("expected rate published on %v, got %v", want, got.PublishedAt)
+	}
+}
+
+func TestProvider_SupportedCurrencies(t *testing.T) {
+	provider, err := ratefile.ReadCSV(strings.NewReader(csvRates), ',')
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	var got []string
+	for _, currency := range provider.SupportedCurrencies() {
+		got = append(got, currency.ISOCode())
+	}
+
+	if want := "EUR,RON,USD"; strings.Join(got, ",") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+func mustParseCurrency(t *testing.T, code string) money.Currency {
+	t.Helper()
+
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		t.Fatalf("cannot parse currency %s code", code)
+	}
+
+	return currency
+}
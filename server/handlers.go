@@ -0,0 +1,283 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"moneyconverter/apiclient"
+	"moneyconverter/ecbank"
+	"moneyconverter/money"
+	"net/http"
+	"time"
+)
+
+// openAPI is the OpenAPI 3 document describing the API.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+// errMissingParameter is returned when a required query parameter is empty.
+var errMissingParameter = errors.New("missing parameter")
+
+// conversionResponse is the answer of /v1/convert.
+type conversionResponse struct {
+	Amount money.Amount       `json:"amount"`
+	Result money.Amount       `json:"result"`
+	Rate   money.ExchangeRate `json:"rate"`
+	// Date is the day the rate was published.
+	Date  string `json:"date"`
+	Stale bool   `json:"stale"`
+}
+
+// ratesResponse is the answer of /v1/rates.
+type ratesResponse struct {
+	Base  money.Currency                        `json:"base"`
+	Date  string                                `json:"date"`
+	Stale bool                                  `json:"stale"`
+	Rates map[money.Currency]money.ExchangeRate `json:"rates"`
+}
+
+// currenciesResponse is the answer of /v1/currencies.
+type currenciesResponse struct {
+	Currencies []money.Currency `json:"currencies"`
+}
+
+// healthResponse is the answer of /healthz.
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+// errorResponse is the envelope of every error.
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+// errorBody describes an error with a machine-readable code.
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// handleConvert converts an amount with the rates of the day, or of the given date.
+func (s *Server) handleConvert(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, err := parseCurrency(query.Get("from"), "from")
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	to, err := parseCurrency(query.Get("to"), "to")
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	amount, err := parseAmount(query.Get("amount"), from)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	table, err := s.table(r, query.Get("date"))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	result, err := money.Convert(amount, to, table)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	rate, err := table.FetchExchangeRate(from, to)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	if s.metrics != nil {
+		s.metrics.converted(from.ISOCode(), to.ISOCode())
+	}
+
+	s.writeJSON(w, http.StatusOK, conversionResponse{
+		Amount: amount,
+		Result: result,
+		Rate:   rate,
+		Date:   table.Date.Format(time.DateOnly),
+		Stale:  table.Stale,
+	})
+}
+
+// handleRates lists the rates of the day, or of the given date, against a base currency, the euro by default.
+func (s *Server) handleRates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	base := "EUR"
+	if query.Has("base") {
+		base = query.Get("base")
+	}
+
+	baseCurrency, err := parseCurrency(base, "base")
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	table, err := s.table(r, query.Get("date"))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	table, err = table.Rebase(baseCurrency)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, ratesResponse{
+		Base:  table.Base,
+		Date:  table.Date.Format(time.DateOnly),
+		Stale: table.Stale,
+		Rates: table.Rates,
+	})
+}
+
+// handleCurrencies lists the currencies of the rates of the day.
+func (s *Server) handleCurrencies(w http.ResponseWriter, r *http.Request) {
+	table, err := s.rates(r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, currenciesResponse{Currencies: table.SupportedCurrencies()})
+}
+
+// handleHealth tells the server is up, whether or not the bank can be reached.
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, healthResponse{Status: "ok"})
+}
+
+// handleOpenAPI answers the OpenAPI document describing the API.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(openAPI); err != nil {
+		s.logger.Error("unable to write response", "error", err)
+	}
+}
+
+// table returns the rates of the day, or those published on the date if any.
+func (s *Server) table(r *http.Request, date string) (ecbank.RateTable, error) {
+	if date == "" {
+		return s.rates(r.Context())
+	}
+
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return ecbank.RateTable{}, &paramError{name: "date", err: fmt.Errorf("expected YYYY-MM-DD, got %q", date)}
+	}
+
+	return s.ratesOn(r.Context(), day)
+}
+
+// parseCurrency parses the currency code of a query parameter.
+func parseCurrency(code, name string) (money.Currency, error) {
+	if code == "" {
+		return money.Currency{}, &paramError{name: name, err: errMissingParameter}
+	}
+
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		return money.Currency{}, &paramError{name: name, err: fmt.Errorf("%w %q", err, code)}
+	}
+
+	return currency, nil
+}
+
+// parseAmount parses the amount query parameter, in the given currency.
+func parseAmount(value string, currency money.Currency) (money.Amount, error) {
+	if value == "" {
+		return money.Amount{}, &paramError{name: "amount", err: errMissingParameter}
+	}
+
+	quantity, err := money.ParseDecimal(value)
+	if err != nil {
+		return money.Amount{}, &paramError{name: "amount", err: err}
+	}
+
+	amount, err := money.NewAmount(quantity, currency)
+	if err != nil {
+		return money.Amount{}, &paramError{name: "amount", err: err}
+	}
+
+	return amount, nil
+}
+
+// paramError reports an invalid query parameter.
+type paramError struct {
+	name string
+	err  error
+}
+
+// Error implements the error interface.
+func (e *paramError) Error() string {
+	return fmt.Sprintf("invalid parameter %s: %s", e.name, e.err)
+}
+
+// Unwrap returns the underlying error.
+func (e *paramError) Unwrap() error {
+	return e.err
+}
+
+// statusOf returns the HTTP status and the machine-readable code of an error.
+func statusOf(err error) (int, string) {
+	var param *paramError
+	switch {
+	case errors.Is(err, money.ErrTooPrecise):
+		return http.StatusUnprocessableEntity, "too_precise"
+	case errors.Is(err, money.ErrTooLarge):
+		return http.StatusUnprocessableEntity, "too_large"
+	case errors.Is(err, money.ErrInvalidCurrencyCode):
+		return http.StatusBadRequest, "invalid_currency"
+	case errors.Is(err, money.ErrInvalidDecimal):
+		return http.StatusBadRequest, "invalid_amount"
+	case errors.As(err, &param):
+		return http.StatusBadRequest, "invalid_parameter"
+	case errors.Is(err, ecbank.ErrChangeRateNotFound), errors.Is(err, apiclient.ErrRateNotFound):
+		return http.StatusNotFound, "rate_not_found"
+	case errors.Is(err, ecbank.ErrNoHistory), errors.Is(err, errNoPastRates):
+		return http.StatusNotFound, "no_rates_for_date"
+	case errors.Is(err, ecbank.ErrCallingServer), errors.Is(err, ecbank.ErrTimeout),
+		errors.Is(err, ecbank.ErrClientSide), errors.Is(err, ecbank.ErrServerSide),
+		errors.Is(err, ecbank.ErrUnknownStatusCode), errors.Is(err, ecbank.ErrUnexpectedFormat),
+		errors.Is(err, ecbank.ErrNoCachedRates), errors.Is(err, apiclient.ErrUnavailable),
+		errors.Is(err, apiclient.ErrUnexpectedResponse):
+		return http.StatusBadGateway, "provider_unavailable"
+	default:
+		return http.StatusInternalServerError, "internal"
+	}
+}
+
+// writeError answers the error in the error envelope.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code := statusOf(err)
+	if status >= http.StatusInternalServerError {
+		s.logger.Error("unable to answer request", "path", r.URL.Path, "error", err)
+	}
+
+	s.writeJSON(w, status, errorResponse{Error: errorBody{Code: code, Message: err.Error()}})
+}
+
+// writeJSON answers the value as JSON with the status.
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Error("unable to write response", "error", err)
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"moneyconverter/metrics"
+	"time"
+)
+
+// Metrics measures the conversions answered by a server, and the calls of an ecbank.Client to the bank and its cache,
+// which it observes as an ecbank.Observer.
+type Metrics struct {
+	registry      *metrics.Registry
+	conversions   *metrics.CounterVec
+	fetches       *metrics.CounterVec
+	fetchDuration *metrics.Histogram
+	cacheHits     *metrics.Counter
+	cacheMisses   *metrics.Counter
+	// publishedAt returns the day the rates served were published, or the zero time if none were loaded yet.
+	publishedAt func() time.Time
+}
+
+// NewMetrics returns the metrics to pass to a server with WithMetrics, and to its client with ecbank.WithObserver.
+func NewMetrics() *Metrics {
+	registry := metrics.NewRegistry()
+	m := &Metrics{
+		registry: registry,
+		conversions: registry.CounterVec("moneyconverter_conversions_total",
+			"Conversions answered, by source and target currency.", "from", "to"),
+		fetches: registry.CounterVec("moneyconverter_upstream_fetches_total",
+			"Calls to the bank, by class of status: 2xx, 4xx, 5xx, unknown, timeout or error.", "status_class"),
+		fetchDuration: registry.Histogram("moneyconverter_upstream_fetch_duration_seconds",
+			"Latency of the calls to the bank.", metrics.DefaultDurationBuckets),
+		cacheHits:   registry.Counter("moneyconverter_cache_hits_total", "Feeds read from the cache."),
+		cacheMisses: registry.Counter("moneyconverter_cache_misses_total", "Feeds missing from the cache."),
+	}
+
+	registry.GaugeFunc("moneyconverter_rates_publication_age_seconds",
+		"Time elapsed since the day the rates served were published, 0 before any were loaded.", m.publicationAge)
+
+	return m
+}
+
+// CacheHit implements ecbank.Observer.
+func (m *Metrics) CacheHit() {
+	m.cacheHits.Inc()
+}
+
+// CacheMiss implements ecbank.Observer.
+func (m *Metrics) CacheMiss() {
+	m.cacheMisses.Inc()
+}
+
+// Fetched implements ecbank.Observer.
+func (m *Metrics) Fetched(statusClass string, latency time.Duration) {
+	m.fetches.Inc(statusClass)
+	m.fetchDuration.ObserveDuration(latency)
+}
+
+// converted counts a conversion between two currencies.
+func (m *Metrics) converted(from, to string) {
+	m.conversions.Inc(from, to)
+}
+
+// publicationAge returns how many seconds have elapsed since the publication of the rates served.
+func (m *Metrics) publicationAge() float64 {
+	if m.publishedAt == nil {
+		return 0
+	}
+
+	published := m.publishedAt()
+	if published.IsZero() {
+		return 0
+	}
+	return time.Since(published).Seconds()
+}
+
+// WithMetrics makes the server count its conversions and expose the metrics on GET /metrics.
+func WithMetrics(m *Metrics) Option {
+	return func(s *Server) {
+		s.metrics = m
+	}
+}
+
+// publishedAt returns the day the rates in memory were published, or the zero time if none were loaded yet.
+func (s *Server) publishedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latest.Date
+}
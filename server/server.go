@@ -0,0 +1,305 @@
+// Package server exposes the money converter as an HTTP JSON API.
+//
+// Endpoints:
+//
+//	GET /v1/convert?amount=12.50&from=USD&to=EUR[&date=2025-04-08]
+//	GET /v1/rates[?base=USD][&date=2025-04-08]
+//	GET /v1/currencies
+//	GET /healthz
+//	GET /openapi.json
+//	GET /metrics, when built WithMetrics, in the text format of Prometheus
+//
+// Errors are answered as {"error":{"code":"...","message":"..."}}, with the status 400 for invalid parameters,
+// 404 for unknown rates, 422 for amounts too precise or too large, and 502 when the bank cannot be reached.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"moneyconverter/ecbank"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultRefreshInterval is how long the rates of the day are served before being fetched again.
+const defaultRefreshInterval = time.Hour
+
+// defaultRetryInterval is how long to wait after a failed refresh before fetching the rates of the day again.
+const defaultRetryInterval = time.Minute
+
+// RateSource provides the rates of the day, such as an ecbank.Client,
+// or an apiclient.Client for a server relaying another one.
+type RateSource interface {
+	Rates(ctx context.Context) (ecbank.RateTable, error)
+}
+
+// refresher is implemented by the rate sources able to skip their cache, such as an ecbank.Client.
+type refresher interface {
+	Refresh(ctx context.Context) (ecbank.RateTable, error)
+}
+
+// historySource is implemented by the rate sources recording the publications they fetch, such as an ecbank.Client.
+type historySource interface {
+	History() ecbank.History
+}
+
+// pastRateSource is implemented by the rate sources knowing the rates published on past days,
+// such as an apiclient.Client.
+type pastRateSource interface {
+	RatesOn(ctx context.Context, day time.Time) (ecbank.RateTable, error)
+}
+
+// errNoPastRates is returned when asking for the rates of a date to a source that only knows those of the day.
+var errNoPastRates = errors.New("the rates of past days are not available")
+
+// Server answers the HTTP API, keeping the rates of the day in memory.
+type Server struct {
+	source          RateSource
+	refreshInterval time.Duration
+	retryInterval   time.Duration
+	logger          *slog.Logger
+	metrics         *Metrics
+	mux             *http.ServeMux
+
+	// mu guards the rates kept in memory.
+	mu sync.Mutex
+	// latest holds the rates of the day, fetched at loadedAt.
+	latest   ecbank.RateTable
+	loadedAt time.Time
+	// fetching is closed when the fetch in flight, if any, is over.
+	fetching chan struct{}
+	// retryAt is when to fetch again after a failure, failedErr the error of that failure.
+	retryAt   time.Time
+	failedErr error
+
+	// historyMu guards the publications read from the history of the source.
+	historyMu sync.Mutex
+	// history holds the publications of the history sorted by date, historyLoaded whether it was read
+	// since the rates of the day were last fetched.
+	history       []ecbank.RateTable
+	historyLoaded bool
+}
+
+// Option customises a Server built by New.
+type Option func(*Server)
+
+// WithRefreshInterval sets how long the rates of the day are served from memory before being fetched again.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.refreshInterval = interval
+	}
+}
+
+// WithRetryInterval sets how long to wait after a failed refresh before fetching the rates of the day again,
+// those in memory being served meanwhile.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.retryInterval = interval
+	}
+}
+
+// WithLogger makes the server log the requests it fails to answer and the rates it cannot refresh.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// New returns a Server answering with the rates of the source.
+// The rates of past days are served if the source knows them, as an ecbank.Client does from its history.
+func New(source RateSource, opts ...Option) *Server {
+	s := &Server{
+		source:          source,
+		refreshInterval: defaultRefreshInterval,
+		retryInterval:   defaultRetryInterval,
+		logger:          slog.New(slog.DiscardHandler),
+		mux:             http.NewServeMux(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.logger == nil {
+		s.logger = slog.New(slog.DiscardHandler)
+	}
+
+	s.mux.HandleFunc("GET /v1/convert", s.handleConvert)
+	s.mux.HandleFunc("GET /v1/rates", s.handleRates)
+	s.mux.HandleFunc("GET /v1/currencies", s.handleCurrencies)
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	if s.metrics != nil {
+		s.metrics.publishedAt = s.publishedAt
+		s.mux.Handle("GET /metrics", s.metrics.registry)
+	}
+
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// rates returns the rates of the day, fetching them when those in memory are older than the refresh interval.
+// A single fetch is in flight at a time: meanwhile, the rates in memory are served, and the requests arriving
+// before any rates were loaded wait for it. Should the bank fail, the rates in memory keep being served,
+// marked as stale, without fetching them again before the retry interval.
+func (s *Server) rates(ctx context.Context) (ecbank.RateTable, error) {
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		loaded := !s.loadedAt.IsZero()
+
+		switch {
+		case loaded && now.Sub(s.loadedAt) < s.refreshInterval:
+			table := s.latest
+			s.mu.Unlock()
+			return table, nil
+
+		case now.Before(s.retryAt):
+			table, err := s.latest, s.failedErr
+			s.mu.Unlock()
+			if loaded {
+				return table, nil
+			}
+			return ecbank.RateTable{}, err
+
+		case s.fetching != nil && loaded:
+			table := s.latest
+			s.mu.Unlock()
+			return table, nil
+
+		case s.fetching != nil:
+			fetching := s.fetching
+			s.mu.Unlock()
+
+			select {
+			case <-fetching:
+				continue
+			case <-ctx.Done():
+				return ecbank.RateTable{}, ctx.Err()
+			}
+		}
+
+		fetching := make(chan struct{})
+		s.fetching = fetching
+		s.mu.Unlock()
+
+		return s.refresh(ctx, fetching)
+	}
+}
+
+// refresh fetches the rates of the day, and closes fetching once they are in memory or the fetch failed.
+func (s *Server) refresh(ctx context.Context, fetching chan struct{}) (ecbank.RateTable, error) {
+	// the fetch serves every request waiting for it, it goes on should the one starting it be cancelled.
+	table, err := s.fetch(context.WithoutCancel(ctx))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer close(fetching)
+	s.fetching = nil
+
+	if err != nil {
+		s.retryAt, s.failedErr = time.Now().Add(s.retryInterval), err
+		if s.loadedAt.IsZero() {
+			return ecbank.RateTable{}, err
+		}
+
+		s.logger.Warn("unable to refresh rates, serving those in memory", "error", err, "published", s.latest.Date, "retry", s.retryAt)
+		s.latest.Stale = true
+		return s.latest, nil
+	}
+
+	s.latest, s.loadedAt = table, time.Now()
+	s.retryAt, s.failedErr = time.Time{}, nil
+
+	s.historyMu.Lock()
+	s.historyLoaded = false
+	s.historyMu.Unlock()
+
+	return table, nil
+}
+
+// fetch returns the latest publication of the source, skipping its cache when an expected publication is missing,
+// such as when the rates of the day were cached before the bank published them.
+func (s *Server) fetch(ctx context.Context) (ecbank.RateTable, error) {
+	table, err := s.source.Rates(ctx)
+	if err != nil {
+		return ecbank.RateTable{}, err
+	}
+
+	expected := ecbank.ExpectedPublication(time.Now())
+	source, canRefresh := s.source.(refresher)
+	if !table.Date.Before(expected) || !canRefresh {
+		return table, nil
+	}
+
+	s.logger.Debug("expected publication missing from the rates, refreshing them", "expected", expected, "published", table.Date)
+	refreshed, err := source.Refresh(ctx)
+	if err != nil {
+		s.logger.Warn("unable to refresh the rates, serving those cached", "error", err, "published", table.Date)
+		return table, nil
+	}
+	return refreshed, nil
+}
+
+// ratesOn returns the rates published on a day, or the latest ones before it.
+// The rates of the day are used when they are the latest, as the history may not record them yet.
+func (s *Server) ratesOn(ctx context.Context, day time.Time) (ecbank.RateTable, error) {
+	latest, latestErr := s.rates(ctx)
+	table, err := s.publishedOn(ctx, day)
+
+	if latestErr == nil && !latest.Date.After(day) && (err != nil || table.Date.Before(latest.Date)) {
+		return latest, nil
+	}
+	if err != nil {
+		return ecbank.RateTable{}, err
+	}
+	return table, nil
+}
+
+// publishedOn returns the publication of a day, or the latest one before it, as known to the source.
+func (s *Server) publishedOn(ctx context.Context, day time.Time) (ecbank.RateTable, error) {
+	switch source := s.source.(type) {
+	case historySource:
+		tables, err := s.pastPublications(source)
+		if err != nil {
+			return ecbank.RateTable{}, err
+		}
+
+		i := sort.Search(len(tables), func(i int) bool {
+			return tables[i].Date.After(day)
+		})
+		if i == 0 {
+			return ecbank.RateTable{}, fmt.Errorf("%w: %s", ecbank.ErrNoHistory, day.Format(time.DateOnly))
+		}
+		return tables[i-1], nil
+
+	case pastRateSource:
+		return source.RatesOn(ctx, day)
+
+	default:
+		return ecbank.RateTable{}, errNoPastRates
+	}
+}
+
+// pastPublications returns the publications recorded in the history of the source, sorted by date.
+// The history is read once, then again after each fetch of the rates of the day, which may add to it.
+func (s *Server) pastPublications(source historySource) ([]ecbank.RateTable, error) {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	if !s.historyLoaded {
+		tables, err := source.History().Load()
+		if err != nil {
+			return nil, err
+		}
+		s.history, s.historyLoaded = tables, true
+	}
+	return s.history, nil
+}
@@ -0,0 +1,508 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"moneyconverter/ecbank"
+	"moneyconverter/money"
+	"moneyconverter/server"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const feed = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time='2025-04-08'>
+			<Cube currency='USD' rate='2.0000'/>
+			<Cube currency='CHF' rate='0.9349'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+const pastFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time='2025-04-01'>
+			<Cube currency='USD' rate='1.5000'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+// newServer returns a test server answering with the rates of the feed, cached for the day without calling the bank,
+// and with those of the past feed in its history.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "mc_data_"+time.Now().Format("20060102")+".txt")
+	if err := os.WriteFile(filename, []byte(feed), 0o644); err != nil {
+		t.Fatalf("unable to write cache file: %s", err)
+	}
+
+	if _, err := ecbank.OpenHistory(dir).Import(strings.NewReader(pastFeed)); err != nil {
+		t.Fatalf("unable to import past rates: %s", err)
+	}
+
+	client := ecbank.NewClient(time.Second, ecbank.WithOffline(), ecbank.WithCacheDir(dir))
+	ts := httptest.NewServer(server.New(client))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+// get requests the path and decodes the JSON answer.
+func get(t *testing.T, ts *httptest.Server, path string) (int, map[string]any) {
+	t.Helper()
+
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("unable to get %s: %s", path, err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected JSON, got %q", contentType)
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("unable to decode the answer of %s: %s", path, err)
+	}
+
+	return resp.StatusCode, body
+}
+
+func TestServer(t *testing.T) {
+	ts := newServer(t)
+
+	tt := map[string]struct {
+		path   string
+		status int
+		// expected holds substrings of the JSON answer.
+		expected []string
+	}{
+		"convert": {
+			path:   "/v1/convert?amount=12.50&from=EUR&to=USD",
+			status: http.StatusOK,
+			expected: []string{
+				`"amount":{"currency":"EUR","quantity":"12.50"}`,
+				`"result":{"currency":"USD","quantity":"25.00"}`,
+				`"rate":"2"`,
+				`"date":"2025-04-08"`,
+			},
+		},
+		"convert on a date": {
+			path:     "/v1/convert?amount=2&from=USD&to=EUR&date=2025-04-09",
+			status:   http.StatusOK,
+			expected: []string{`"result":{"currency":"EUR","quantity":"1.00"}`, `"date":"2025-04-08"`},
+		},
+		"convert on a past date": {
+			path:     "/v1/convert?amount=3&from=USD&to=EUR&date=2025-04-02",
+			status:   http.StatusOK,
+			expected: []string{`"result":{"currency":"EUR","quantity":"2.00"}`, `"date":"2025-04-01"`},
+		},
+		"rates": {
+			path:     "/v1/rates?base=USD",
+			status:   http.StatusOK,
+			expected: []string{`"base":"USD"`, `"EUR":"0.5"`},
+		},
+		"currencies": {
+			path:     "/v1/currencies",
+			status:   http.StatusOK,
+			expected: []string{`"currencies":["CHF","EUR","USD"]`},
+		},
+		"health": {
+			path:     "/healthz",
+			status:   http.StatusOK,
+			expected: []string{`"status":"ok"`},
+		},
+		"invalid currency": {
+			path:     "/v1/convert?amount=1&from=EURO&to=USD",
+			status:   http.StatusBadRequest,
+			expected: []string{`"code":"invalid_currency"`},
+		},
+		"missing amount": {
+			path:     "/v1/convert?from=EUR&to=USD",
+			status:   http.StatusBadRequest,
+			expected: []string{`"code":"invalid_parameter"`, "missing parameter"},
+		},
+		"invalid amount": {
+			path:     "/v1/convert?amount=abc&from=EUR&to=USD",
+			status:   http.StatusBadRequest,
+			expected: []string{`"code":"invalid_amount"`},
+		},
+		"invalid date": {
+			path:     "/v1/rates?date=yesterday",
+			status:   http.StatusBadRequest,
+			expected: []string{`"code":"invalid_parameter"`},
+		},
+		"too precise amount": {
+			path:     "/v1/convert?amount=1.001&from=EUR&to=USD",
+			status:   http.StatusUnprocessableEntity,
+			expected: []string{`"code":"too_precise"`},
+		},
+		"amount times a cross rate beyond 64 bits": {
+			path:     "/v1/convert?amount=1000000000&from=CHF&to=USD",
+			status:   http.StatusOK,
+			expected: []string{`"rate":"2.1392662317"`, `"result":{"currency":"USD","quantity":"2139266231.70"}`},
+		},
+		"too large result": {
+			path:     "/v1/convert?amount=9000000000&from=CHF&to=USD",
+			status:   http.StatusUnprocessableEntity,
+			expected: []string{`"code":"too_large"`},
+		},
+		"unknown rate": {
+			path:     "/v1/convert?amount=1&from=EUR&to=JPY",
+			status:   http.StatusNotFound,
+			expected: []string{`"code":"rate_not_found"`},
+		},
+		"date before the history": {
+			path:     "/v1/rates?date=2020-01-01",
+			status:   http.StatusNotFound,
+			expected: []string{`"code":"no_rates_for_date"`},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			status, body := get(t, ts, tc.path)
+			if status != tc.status {
+				t.Errorf("expected status %d, got %d: %v", tc.status, status, body)
+			}
+
+			encoded, err := json.Marshal(body)
+			if err != nil {
+				t.Fatalf("unable to encode the answer: %s", err)
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(string(encoded), expected) {
+					t.Errorf("expected %s in %s", expected, encoded)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_HistoryReadOnce(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ecbank.OpenHistory(dir).Import(strings.NewReader(pastFeed)); err != nil {
+		t.Fatalf("unable to import past rates: %s", err)
+	}
+
+	client := ecbank.NewClient(time.Second, ecbank.WithOffline(), ecbank.WithCacheDir(dir))
+	ts := httptest.NewServer(server.New(client))
+	defer ts.Close()
+
+	if status, body := get(t, ts, "/v1/rates?date=2025-04-02"); status != http.StatusOK || body["date"] != "2025-04-01" {
+		t.Fatalf("expected the rates of 2025-04-01, got status %d and %v", status, body)
+	}
+
+	// the history is kept in memory: corrupting its file doesn't affect the other dates.
+	if err := os.WriteFile(filepath.Join(dir, "mc_history.log"), []byte("corrupted\n"), 0o644); err != nil {
+		t.Fatalf("unable to overwrite the history: %s", err)
+	}
+
+	for _, date := range []string{"2025-04-01", "2025-04-03", "2025-04-05"} {
+		if status, body := get(t, ts, "/v1/rates?date="+date); status != http.StatusOK || body["date"] != "2025-04-01" {
+			t.Errorf("expected the rates of 2025-04-01 on %s, got status %d and %v", date, status, body)
+		}
+	}
+
+	if status, _ := get(t, ts, "/v1/rates?date=2020-01-01"); status != http.StatusNotFound {
+		t.Errorf("expected status %d before the history, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestServer_UpstreamFailure(t *testing.T) {
+	client := ecbank.NewClient(time.Second, ecbank.WithOffline(), ecbank.WithCacheDir(t.TempDir()))
+	ts := httptest.NewServer(server.New(client))
+	defer ts.Close()
+
+	status, body := get(t, ts, "/v1/convert?amount=1&from=EUR&to=USD")
+	if status != http.StatusBadGateway {
+		t.Errorf("expected status %d, got %d", http.StatusBadGateway, status)
+	}
+
+	envelope, _ := body["error"].(map[string]any)
+	if envelope["code"] != "provider_unavailable" {
+		t.Errorf("expected code provider_unavailable, got %v", body)
+	}
+
+	if status, _ := get(t, ts, "/healthz"); status != http.StatusOK {
+		t.Errorf("expected the server to stay healthy, got status %d", status)
+	}
+}
+
+// flakySource answers the rates of its table for the first answered fetches, then fails,
+// each fetch waiting for release to be closed.
+type flakySource struct {
+	table    ecbank.RateTable
+	answered int32
+	calls    atomic.Int32
+	started  chan struct{}
+	release  chan struct{}
+}
+
+// Rates implements server.RateSource.
+func (f *flakySource) Rates(_ context.Context) (ecbank.RateTable, error) {
+	call := f.calls.Add(1)
+	f.started <- struct{}{}
+	<-f.release
+	if call <= f.answered {
+		return f.table, nil
+	}
+	return ecbank.RateTable{}, ecbank.ErrServerSide
+}
+
+func TestServer_RefreshFailure(t *testing.T) {
+	usd, err := money.ParseCurrency("USD")
+	if err != nil {
+		t.Fatalf("unable to parse currency: %s", err)
+	}
+	eur, err := money.ParseCurrency("EUR")
+	if err != nil {
+		t.Fatalf("unable to parse currency: %s", err)
+	}
+	rate, err := money.ParseDecimal("2")
+	if err != nil {
+		t.Fatalf("unable to parse rate: %s", err)
+	}
+
+	source := &flakySource{
+		table:    ecbank.RateTable{Base: eur, Date: time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC), Rates: map[money.Currency]money.ExchangeRate{eur: money.ExchangeRate(rate), usd: money.ExchangeRate(rate)}},
+		answered: 1,
+		started:  make(chan struct{}, 10),
+		release:  make(chan struct{}),
+	}
+	ts := httptest.NewServer(server.New(source, server.WithRefreshInterval(0), server.WithRetryInterval(time.Hour)))
+	defer ts.Close()
+
+	close(source.release)
+	if status, _ := get(t, ts, "/v1/rates"); status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+	}
+
+	// the refresh fails: the rates in memory are served, and not fetched again before the retry interval.
+	for range 3 {
+		status, body := get(t, ts, "/v1/rates")
+		if status != http.StatusOK || body["stale"] != true {
+			t.Errorf("expected stale rates, got status %d and %v", status, body)
+		}
+	}
+
+	if calls := source.calls.Load(); calls != 2 {
+		t.Errorf("expected 2 fetches, got %d", calls)
+	}
+}
+
+// cachedSource answers the rates of its cached table, and those of its published table when refreshed.
+type cachedSource struct {
+	cached    ecbank.RateTable
+	published ecbank.RateTable
+	refreshes atomic.Int32
+}
+
+// Rates implements server.RateSource.
+func (c *cachedSource) Rates(_ context.Context) (ecbank.RateTable, error) {
+	return c.cached, nil
+}
+
+// Refresh skips the cache, as an ecbank.Client does.
+func (c *cachedSource) Refresh(_ context.Context) (ecbank.RateTable, error) {
+	c.refreshes.Add(1)
+	return c.published, nil
+}
+
+func TestServer_CachedBeforePublication(t *testing.T) {
+	eur, err := money.ParseCurrency("EUR")
+	if err != nil {
+		t.Fatalf("unable to parse currency: %s", err)
+	}
+	rate, err := money.ParseDecimal("1")
+	if err != nil {
+		t.Fatalf("unable to parse rate: %s", err)
+	}
+	rates := map[money.Currency]money.ExchangeRate{eur: money.ExchangeRate(rate)}
+
+	expected := ecbank.ExpectedPublication(time.Now())
+	tt := map[string]struct {
+		cached    time.Time
+		want      time.Time
+		refreshes int32
+	}{
+		"older than the expected publication": {
+			cached: expected.AddDate(0, 0, -1), want: expected, refreshes: 1,
+		},
+		"expected publication": {
+			cached: expected, want: expected, refreshes: 0,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			source := &cachedSource{
+				cached:    ecbank.RateTable{Base: eur, Date: tc.cached, Rates: rates},
+				published: ecbank.RateTable{Base: eur, Date: expected, Rates: rates},
+			}
+			ts := httptest.NewServer(server.New(source))
+			defer ts.Close()
+
+			status, body := get(t, ts, "/v1/rates")
+			if status != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+			}
+			if want := tc.want.Format(time.DateOnly); body["date"] != want {
+				t.Errorf("expected the rates of %s, got %v", want, body["date"])
+			}
+			if refreshes := source.refreshes.Load(); refreshes != tc.refreshes {
+				t.Errorf("expected %d refreshes, got %d", tc.refreshes, refreshes)
+			}
+		})
+	}
+}
+
+func TestServer_SingleFetch(t *testing.T) {
+	source := &flakySource{started: make(chan struct{}, 10), release: make(chan struct{})}
+	ts := httptest.NewServer(server.New(source))
+	defer ts.Close()
+
+	// the requests arriving while the rates are fetched wait for that fetch, which fails for all of them.
+	statuses := make(chan int, 3)
+	for range cap(statuses) {
+		go func() {
+			resp, err := http.Get(ts.URL + "/v1/rates")
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+
+	<-source.started
+	time.Sleep(50 * time.Millisecond)
+	close(source.release)
+
+	for range cap(statuses) {
+		if status := <-statuses; status != http.StatusBadGateway {
+			t.Errorf("expected status %d, got %d", http.StatusBadGateway, status)
+		}
+	}
+
+	if calls := source.calls.Load(); calls != 1 {
+		t.Errorf("expected a single fetch, got %d", calls)
+	}
+}
+
+func TestServer_MethodNotAllowed(t *testing.T) {
+	ts := newServer(t)
+
+	resp, err := http.Post(ts.URL+"/v1/convert", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("unable to post: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
+
+func TestServer_OpenAPI(t *testing.T) {
+	ts := newServer(t)
+
+	status, document := get(t, ts, "/openapi.json")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+	}
+
+	if document["openapi"] != "3.0.3" {
+		t.Errorf("expected an OpenAPI 3 document, got version %v", document["openapi"])
+	}
+
+	paths, _ := document["paths"].(map[string]any)
+	for _, path := range []string{"/v1/convert", "/v1/rates", "/v1/currencies", "/healthz", "/openapi.json", "/metrics"} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("expected %s to be documented", path)
+		}
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "mc_data_"+time.Now().Format("20060102")+".txt")
+	if err := os.WriteFile(filename, []byte(feed), 0o644); err != nil {
+		t.Fatalf("unable to write cache file: %s", err)
+	}
+
+	metrics := server.NewMetrics()
+	client := ecbank.NewClient(time.Second, ecbank.WithOffline(), ecbank.WithCacheDir(dir), ecbank.WithObserver(metrics))
+	ts := httptest.NewServer(server.New(client, server.WithMetrics(metrics)))
+	defer ts.Close()
+
+	for range 2 {
+		if status, body := get(t, ts, "/v1/convert?amount=1&from=EUR&to=USD"); status != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %v", http.StatusOK, status, body)
+		}
+	}
+	if status, _ := get(t, ts, "/v1/convert?amount=1&from=EUR&to=XXX"); status != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, status)
+	}
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("unable to get the metrics: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable to read the metrics: %s", err)
+	}
+
+	// the cached rates being older than the expected publication, the offline client reads them again to refresh them.
+	for _, want := range []string{
+		"moneyconverter_conversions_total{from=\"EUR\",to=\"USD\"} 2\n",
+		"moneyconverter_cache_hits_total 2\n",
+		"moneyconverter_cache_misses_total 0\n",
+		"moneyconverter_upstream_fetch_duration_seconds_count 0\n",
+		"# TYPE moneyconverter_rates_publication_age_seconds gauge\n",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected the metrics to contain %q, got\n%s", want, body)
+		}
+	}
+	if strings.Contains(string(body), "XXX") {
+		t.Errorf("expected failed conversions not to be counted, got\n%s", body)
+	}
+	if strings.Contains(string(body), "moneyconverter_rates_publication_age_seconds 0\n") {
+		t.Errorf("expected the age of the rates to be measured, got\n%s", body)
+	}
+}
+
+func TestServer_MetricsDisabled(t *testing.T) {
+	ts := newServer(t)
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("unable to get the metrics: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
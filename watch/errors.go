@@ -0,0 +1,16 @@
+package watch
+
+// watchError defines a sentinel error.
+type watchError string
+
+// watchError implements the error interface.
+func (e watchError) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrInvalidRule is returned when a rule cannot be parsed.
+	ErrInvalidRule = watchError("invalid rule")
+	// ErrNotification is returned when an event cannot be delivered, such as when a webhook answers with an error.
+	ErrNotification = watchError("unable to deliver the event")
+)
@@ -0,0 +1,138 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponseExcerpt is how much of a failed answer of a webhook, or of the output of a failed command, is reported.
+const maxResponseExcerpt = 512
+
+// Notifier delivers the events of a Watcher.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// jsonLines writes each event as a line of JSON.
+type jsonLines struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// JSONLines returns a Notifier writing each event to w as a line of JSON.
+func JSONLines(w io.Writer) Notifier {
+	return &jsonLines{w: w}
+}
+
+// Notify implements Notifier.
+func (n *jsonLines) Notify(_ context.Context, event Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	body, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := n.w.Write(body); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotification, err)
+	}
+	return nil
+}
+
+// command runs a program for each event.
+type command struct {
+	name string
+	args []string
+}
+
+// Command returns a Notifier running a program for each event, which is given as JSON on its standard input.
+func Command(name string, args ...string) Notifier {
+	return command{name: name, args: args}
+}
+
+// Notify implements Notifier.
+func (n command) Notify(ctx context.Context, event Event) error {
+	body, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, n.name, n.args...)
+	cmd.Stdin = bytes.NewReader(body)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s: %w: %s", ErrNotification, n.name, err, excerpt(output))
+	}
+	return nil
+}
+
+// webhook posts each event to a URL.
+type webhook struct {
+	url    string
+	client *http.Client
+}
+
+// defaultWebhookTimeout is how long a webhook may take to answer when no client is given.
+const defaultWebhookTimeout = 10 * time.Second
+
+// Webhook returns a Notifier posting each event as JSON to the URL, with the client, or a default one if nil.
+// Answers other than 2xx are reported as errors.
+func Webhook(url string, client *http.Client) Notifier {
+	if client == nil {
+		client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	return webhook{url: url, client: client}
+}
+
+// Notify implements Notifier.
+func (n webhook) Notify(ctx context.Context, event Event) error {
+	body, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotification, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotification, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		answer, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseExcerpt))
+		return fmt.Errorf("%w: %s answered %d: %s", ErrNotification, n.url, resp.StatusCode, excerpt(answer))
+	}
+	return nil
+}
+
+// encode writes an event as a line of JSON, leaving the operators of its rule unescaped.
+func encode(event Event) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(event); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotification, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// excerpt returns the beginning of an output, on a single line.
+func excerpt(output []byte) string {
+	if len(output) > maxResponseExcerpt {
+		output = output[:maxResponseExcerpt]
+	}
+	return strings.Join(strings.Fields(string(output)), " ")
+}
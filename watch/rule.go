@@ -0,0 +1,181 @@
+package watch
+
+import (
+	"fmt"
+	"math/big"
+	"moneyconverter/ecbank"
+	"moneyconverter/money"
+	"strings"
+)
+
+// Kind tells what a rule watches.
+type Kind string
+
+const (
+	// Threshold rules compare a rate to a value, such as USD/EUR > 0.95.
+	Threshold Kind = "threshold"
+	// Move rules compare the change of a rate since the previous publication to a percentage, such as GBP moves > 1%.
+	Move Kind = "move"
+)
+
+// operator compares a value to the limit of a rule.
+type operator string
+
+const (
+	greater        operator = ">"
+	greaterOrEqual operator = ">="
+	less           operator = "<"
+	lessOrEqual    operator = "<="
+)
+
+// holds reports whether the comparison of a value with the limit, as returned by big.Rat.Cmp, satisfies the operator.
+func (o operator) holds(cmp int) bool {
+	switch o {
+	case greater:
+		return cmp > 0
+	case greaterOrEqual:
+		return cmp >= 0
+	case less:
+		return cmp < 0
+	default:
+		return cmp <= 0
+	}
+}
+
+// Rule is a condition on the rates, parsed by ParseRule.
+type Rule struct {
+	// Kind tells whether the rule compares a rate, or its change, to the limit.
+	Kind Kind
+	// Source and Target are the currencies of the rate watched: the amount of Target one unit of Source is worth.
+	Source, Target money.Currency
+
+	text     string
+	operator operator
+	// limit is the value of a threshold, or the fraction a rate has to move by, 0.01 for 1%.
+	limit *big.Rat
+}
+
+// ParseRule reads a rule such as:
+//
+//	USD/EUR > 0.95          the rate of USD in EUR is above 0.95
+//	EUR/USD <= 1.05         the rate of EUR in USD is at most 1.05
+//	GBP moves > 1%          the rate of GBP against the euro changed by more than 1% since the previous publication
+//	GBP/USD moves >= 0.5% day-over-day
+//
+// A single currency is quoted against the euro, as published by the bank.
+// Thresholds accept the operators >, >=, < and <=, moves only > and >=.
+func ParseRule(text string) (Rule, error) {
+	fields := strings.Fields(text)
+	rule := Rule{text: strings.Join(fields, " "), Kind: Threshold}
+	if len(fields) < 3 {
+		return Rule{}, fmt.Errorf("%w %q: expected a pair, an operator and a value", ErrInvalidRule, text)
+	}
+
+	var err error
+	rule.Source, rule.Target, err = parsePair(fields[0])
+	if err != nil {
+		return Rule{}, fmt.Errorf("%w %q: %w", ErrInvalidRule, text, err)
+	}
+	fields = fields[1:]
+
+	if strings.EqualFold(fields[0], "moves") {
+		rule.Kind = Move
+		fields = fields[1:]
+		if len(fields) == 3 && strings.EqualFold(fields[2], "day-over-day") {
+			fields = fields[:2]
+		}
+	}
+	if len(fields) != 2 {
+		return Rule{}, fmt.Errorf("%w %q: expected an operator and a value", ErrInvalidRule, text)
+	}
+
+	rule.operator = operator(fields[0])
+	switch rule.operator {
+	case greater, greaterOrEqual:
+	case less, lessOrEqual:
+		if rule.Kind == Move {
+			return Rule{}, fmt.Errorf("%w %q: moves are compared with > or >=", ErrInvalidRule, text)
+		}
+	default:
+		return Rule{}, fmt.Errorf("%w %q: unknown operator %q", ErrInvalidRule, text, fields[0])
+	}
+
+	value := fields[1]
+	if rule.Kind == Move {
+		var ok bool
+		if value, ok = strings.CutSuffix(value, "%"); !ok {
+			return Rule{}, fmt.Errorf("%w %q: expected a percentage such as 1%%", ErrInvalidRule, text)
+		}
+	}
+
+	limit, err := money.ParseDecimal(value)
+	if err != nil {
+		return Rule{}, fmt.Errorf("%w %q: %w", ErrInvalidRule, text, err)
+	}
+	rule.limit = limit.Rat()
+	if rule.Kind == Move {
+		rule.limit.Quo(rule.limit, big.NewRat(100, 1))
+	}
+
+	return rule, nil
+}
+
+// parsePair reads a pair of currencies such as USD/EUR, or a single currency quoted against the euro.
+func parsePair(pair string) (money.Currency, money.Currency, error) {
+	sourceCode, targetCode, isPair := strings.Cut(pair, "/")
+	if !isPair {
+		sourceCode, targetCode = "EUR", pair
+	}
+
+	source, err := money.ParseCurrency(sourceCode)
+	if err != nil {
+		return money.Currency{}, money.Currency{}, err
+	}
+
+	target, err := money.ParseCurrency(targetCode)
+	if err != nil {
+		return money.Currency{}, money.Currency{}, err
+	}
+
+	return source, target, nil
+}
+
+// String returns the rule as written, with single spaces.
+func (r Rule) String() string {
+	return r.text
+}
+
+// pair returns the currencies of the rule, such as USD/EUR.
+func (r Rule) pair() string {
+	return r.Source.ISOCode() + "/" + r.Target.ISOCode()
+}
+
+// rate returns the rate watched by the rule in a table.
+func (r Rule) rate(table ecbank.RateTable) (money.ExchangeRate, error) {
+	rate, err := table.FetchExchangeRate(r.Source, r.Target)
+	if err != nil {
+		return money.ExchangeRate{}, fmt.Errorf("rule %q: %w", r.text, err)
+	}
+	return rate, nil
+}
+
+// crossed reports whether a rate satisfies a threshold rule.
+func (r Rule) crossed(rate money.ExchangeRate) bool {
+	return r.operator.holds(money.Decimal(rate).Rat().Cmp(r.limit))
+}
+
+// change returns the relative change between two rates, 0.01 for a rise of 1%.
+func change(previous, current money.ExchangeRate) *big.Rat {
+	before := money.Decimal(previous).Rat()
+	if before.Sign() == 0 {
+		return new(big.Rat)
+	}
+
+	diff := new(big.Rat).Sub(money.Decimal(current).Rat(), before)
+	return diff.Quo(diff, before)
+}
+
+// moved reports whether a change satisfies a move rule, whichever its direction.
+func (r Rule) moved(change *big.Rat) bool {
+	return r.operator.holds(new(big.Rat).Abs(change).Cmp(r.limit))
+}
@@ -0,0 +1,20 @@
+package watch
+
+import (
+	"moneyconverter/ecbank"
+	"time"
+)
+
+// nextCheck returns when to check the rates again: at the next publication if the latest one is known,
+// every retry interval until it is otherwise.
+func nextCheck(now, latest time.Time, retry time.Duration) time.Time {
+	next := ecbank.NextPublication(now)
+	if !latest.Before(ecbank.ExpectedPublication(now)) {
+		return next
+	}
+
+	if retried := now.Add(retry); retried.Before(next) {
+		return retried
+	}
+	return next
+}
@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNextCheck(t *testing.T) {
+	cet, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("unable to load the time zone of the bank: %s", err)
+	}
+	at := func(day string, hour, minute int) time.Time {
+		t.Helper()
+
+		date, err := time.ParseInLocation(time.DateOnly, day, cet)
+		if err != nil {
+			t.Fatalf("invalid day %q: %s", day, err)
+		}
+		return date.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	day := func(day string) time.Time {
+		t.Helper()
+
+		date, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			t.Fatalf("invalid day %q: %s", day, err)
+		}
+		return date
+	}
+
+	tt := map[string]struct {
+		now    time.Time
+		latest time.Time
+		next   time.Time
+	}{
+		"morning, latest known": {
+			now: at("2025-04-08", 9, 0), latest: day("2025-04-07"), next: at("2025-04-08", 16, 5),
+		},
+		"evening, published": {
+			now: at("2025-04-08", 17, 0), latest: day("2025-04-08"), next: at("2025-04-09", 16, 5),
+		},
+		"evening, publication late": {
+			now: at("2025-04-08", 17, 0), latest: day("2025-04-07"), next: at("2025-04-08", 17, 15),
+		},
+		"before the publication, the previous one missing": {
+			now: at("2025-04-09", 15, 55), latest: day("2025-04-07"), next: at("2025-04-09", 16, 5),
+		},
+		"friday evening": {
+			now: at("2025-04-11", 18, 0), latest: day("2025-04-11"), next: at("2025-04-14", 16, 5),
+		},
+		"sunday": {
+			now: at("2025-04-13", 12, 0), latest: day("2025-04-11"), next: at("2025-04-14", 16, 5),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if got := nextCheck(tc.now, tc.latest, 15*time.Minute); !got.Equal(tc.next) {
+				t.Errorf("nextCheck got %s, want %s", got, tc.next)
+			}
+		})
+	}
+}
+
+func TestWithRetryInterval(t *testing.T) {
+	for interval, want := range map[time.Duration]time.Duration{
+		time.Minute: time.Minute,
+		0:           defaultRetryInterval,
+		-time.Hour:  defaultRetryInterval,
+	} {
+		if got := New(nil, nil, nil, WithRetryInterval(interval)).retry; got != want {
+			t.Errorf("expected a retry interval of %s for %s, got %s", want, interval, got)
+		}
+	}
+}
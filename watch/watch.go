@@ -0,0 +1,270 @@
+// Package watch follows the reference rates of the European Central Bank and notifies when they meet rules,
+// such as USD/EUR > 0.95 or GBP moves > 1% day-over-day.
+//
+// A Watcher checks the rates when the bank is expected to publish them, around 16:00 CET on working days,
+// and polls until the publication shows up when it is late.
+// A threshold rule notifies when it becomes true: once when the watch starts if it already holds,
+// then each time the rate crosses the threshold again after having gone back.
+// A move rule notifies once per publication in which the rate changed by more than its percentage since the previous one.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"moneyconverter/ecbank"
+	"moneyconverter/money"
+	"time"
+)
+
+// defaultRetryInterval is how often the rates are checked while the expected publication is late.
+const defaultRetryInterval = 15 * time.Minute
+
+// RateSource provides the rates of the day, such as an ecbank.Client or an apiclient.Client.
+type RateSource interface {
+	Rates(ctx context.Context) (ecbank.RateTable, error)
+}
+
+// refresher is implemented by the rate sources able to skip their cache, such as an ecbank.Client.
+type refresher interface {
+	Refresh(ctx context.Context) (ecbank.RateTable, error)
+}
+
+// pastRateSource is implemented by the rate sources knowing the rates published on past days.
+type pastRateSource interface {
+	RatesOn(ctx context.Context, day time.Time) (ecbank.RateTable, error)
+}
+
+// Event tells that a rule was met.
+type Event struct {
+	// Rule is the rule met, as written.
+	Rule string `json:"rule"`
+	// Kind tells whether the rate crossed a threshold or moved.
+	Kind Kind `json:"kind"`
+	// Pair is the rate watched, such as USD/EUR.
+	Pair string `json:"pair"`
+	// Rate is the value of the rate in the publication.
+	Rate money.ExchangeRate `json:"rate"`
+	// Previous is the value of the rate in the previous publication, for moves.
+	Previous *money.ExchangeRate `json:"previous,omitempty"`
+	// Change is how much the rate moved since the previous publication, such as -1.25%, for moves.
+	Change string `json:"change,omitempty"`
+	// Date is the day the rates were published.
+	Date string `json:"date"`
+	// Time is when the rule was found met.
+	Time time.Time `json:"time"`
+}
+
+// Watcher checks the rates of a source against rules and notifies the events.
+type Watcher struct {
+	source    RateSource
+	rules     []Rule
+	notifiers []Notifier
+	retry     time.Duration
+	logger    *slog.Logger
+
+	// latest holds the last publication fetched, previous the one before, to measure moves.
+	latest, previous ecbank.RateTable
+	// movesChecked is the day of the last publication the move rules were checked against.
+	movesChecked time.Time
+	// crossed tells, for each threshold rule, whether it held at the last check.
+	crossed map[int]bool
+}
+
+// Option customises a Watcher built by New.
+type Option func(*Watcher)
+
+// WithRetryInterval sets how often the rates are checked while the expected publication is late.
+// An interval that isn't positive is ignored, as the watcher would otherwise check the rates without pause.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(w *Watcher) {
+		if interval > 0 {
+			w.retry = interval
+		}
+	}
+}
+
+// WithLogger makes the watcher log its checks, and the errors it recovers from.
+func WithLogger(logger *slog.Logger) Option {
+	return func(w *Watcher) {
+		w.logger = logger
+	}
+}
+
+// New returns a Watcher checking the rules against the rates of the source, and sending events to every notifier.
+func New(source RateSource, rules []Rule, notifiers []Notifier, opts ...Option) *Watcher {
+	w := &Watcher{
+		source:    source,
+		rules:     rules,
+		notifiers: notifiers,
+		retry:     defaultRetryInterval,
+		crossed:   make(map[int]bool, len(rules)),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.logger == nil {
+		w.logger = slog.New(slog.DiscardHandler)
+	}
+
+	return w
+}
+
+// Run checks the rates at each publication until the context is done.
+// Failures are logged and retried, so that the watch goes on.
+func (w *Watcher) Run(ctx context.Context) error {
+	for {
+		if _, err := w.Check(ctx); err != nil {
+			w.logger.Warn("checking the rates failed", "error", err)
+		}
+
+		now := time.Now()
+		next := nextCheck(now, w.latest.Date, w.retry)
+		w.logger.Info("next check", "at", next, "published", w.latest.Date.Format(time.DateOnly))
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Check fetches the rates, evaluates the rules and notifies the events, which it returns.
+// The rules it cannot evaluate, and the events it cannot deliver, are reported as errors
+// while the others are still processed.
+func (w *Watcher) Check(ctx context.Context) ([]Event, error) {
+	table, err := w.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !table.Date.Equal(w.latest.Date) {
+		w.previous = w.latest
+		if w.previous.Rates == nil {
+			w.previous = w.pastRates(ctx, table.Date)
+		}
+		w.latest = table
+	}
+	checkMoves := !w.latest.Date.Equal(w.movesChecked)
+	w.movesChecked = w.latest.Date
+
+	now := time.Now()
+	var events []Event
+	var errs []error
+	for i, rule := range w.rules {
+		if rule.Kind == Move && !checkMoves {
+			continue
+		}
+
+		event, met, err := w.evaluate(i, rule)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !met {
+			continue
+		}
+
+		event.Time = now
+		events = append(events, event)
+		for _, notifier := range w.notifiers {
+			if err := notifier.Notify(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return events, errors.Join(errs...)
+}
+
+// fetch returns the latest publication of the source, skipping its cache when an expected publication is missing.
+func (w *Watcher) fetch(ctx context.Context) (ecbank.RateTable, error) {
+	table, err := w.source.Rates(ctx)
+	if err != nil {
+		return ecbank.RateTable{}, err
+	}
+
+	expected := ecbank.ExpectedPublication(time.Now())
+	source, canRefresh := w.source.(refresher)
+	if !table.Date.Before(expected) || !canRefresh {
+		return table, nil
+	}
+
+	w.logger.Debug("expected publication missing from the rates, refreshing them", "expected", expected, "published", table.Date)
+	refreshed, err := source.Refresh(ctx)
+	if err != nil {
+		w.logger.Warn("unable to refresh the rates", "error", err)
+		return table, nil
+	}
+	return refreshed, nil
+}
+
+// pastRates returns the publication before the day, or an empty table if the source doesn't know it.
+func (w *Watcher) pastRates(ctx context.Context, day time.Time) ecbank.RateTable {
+	source, ok := w.source.(pastRateSource)
+	if !ok {
+		return ecbank.RateTable{}
+	}
+
+	table, err := source.RatesOn(ctx, day.AddDate(0, 0, -1))
+	if err != nil || !table.Date.Before(day) {
+		w.logger.Debug("no previous publication to measure moves against", "day", day, "error", err)
+		return ecbank.RateTable{}
+	}
+	return table
+}
+
+// evaluate checks a rule against the latest publication, and returns the event to notify, if any.
+func (w *Watcher) evaluate(i int, rule Rule) (Event, bool, error) {
+	rate, err := rule.rate(w.latest)
+	if err != nil {
+		return Event{}, false, err
+	}
+
+	event := Event{
+		Rule: rule.String(),
+		Kind: rule.Kind,
+		Pair: rule.pair(),
+		Rate: rate,
+		Date: w.latest.Date.Format(time.DateOnly),
+	}
+
+	switch rule.Kind {
+	case Move:
+		if w.previous.Rates == nil {
+			return Event{}, false, nil
+		}
+
+		previous, err := rule.rate(w.previous)
+		if err != nil {
+			return Event{}, false, err
+		}
+
+		moved := change(previous, rate)
+		event.Previous = &previous
+		event.Change = formatChange(moved)
+		return event, rule.moved(moved), nil
+
+	default:
+		crossed := rule.crossed(rate)
+		wasCrossed := w.crossed[i]
+		w.crossed[i] = crossed
+		return event, crossed && !wasCrossed, nil
+	}
+}
+
+// formatChange writes a relative change as a signed percentage with two decimals, such as +1.25%.
+func formatChange(change *big.Rat) string {
+	percent := new(big.Rat).Mul(change, big.NewRat(100, 1))
+	sign := "+"
+	if percent.Sign() < 0 {
+		sign = ""
+	}
+	return fmt.Sprintf("%s%s%%", sign, percent.FloatString(2))
+}
@@ -0,0 +1,277 @@
+package watch_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"moneyconverter/ecbank"
+	"moneyconverter/money"
+	"moneyconverter/watch"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// table returns the rates published on a day, against the euro, given as pairs of currency code and rate.
+func table(t *testing.T, day string, rates ...string) ecbank.RateTable {
+	t.Helper()
+
+	date, err := time.Parse(time.DateOnly, day)
+	if err != nil {
+		t.Fatalf("invalid day %q: %s", day, err)
+	}
+
+	result := ecbank.RateTable{
+		Base:  mustParseCurrency(t, "EUR"),
+		Date:  date,
+		Rates: map[money.Currency]money.ExchangeRate{mustParseCurrency(t, "EUR"): mustParseRate(t, "1")},
+	}
+	for i := 0; i+1 < len(rates); i += 2 {
+		result.Rates[mustParseCurrency(t, rates[i])] = mustParseRate(t, rates[i+1])
+	}
+
+	return result
+}
+
+func mustParseCurrency(t *testing.T, code string) money.Currency {
+	t.Helper()
+
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		t.Fatalf("invalid currency %q: %s", code, err)
+	}
+	return currency
+}
+
+func mustParseRate(t *testing.T, value string) money.ExchangeRate {
+	t.Helper()
+
+	rate, err := money.ParseDecimal(value)
+	if err != nil {
+		t.Fatalf("invalid rate %q: %s", value, err)
+	}
+	return money.ExchangeRate(rate)
+}
+
+func mustParseRules(t *testing.T, texts ...string) []watch.Rule {
+	t.Helper()
+
+	rules := make([]watch.Rule, 0, len(texts))
+	for _, text := range texts {
+		rule, err := watch.ParseRule(text)
+		if err != nil {
+			t.Fatalf("unable to parse rule %q: %s", text, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// sequence is a rate source answering with its tables in turn, then with the last one.
+type sequence struct {
+	tables []ecbank.RateTable
+	past   map[string]ecbank.RateTable
+}
+
+func (s *sequence) Rates(context.Context) (ecbank.RateTable, error) {
+	if len(s.tables) == 0 {
+		return ecbank.RateTable{}, errors.New("no rates")
+	}
+
+	table := s.tables[0]
+	if len(s.tables) > 1 {
+		s.tables = s.tables[1:]
+	}
+	return table, nil
+}
+
+func (s *sequence) RatesOn(_ context.Context, day time.Time) (ecbank.RateTable, error) {
+	table, ok := s.past[day.Format(time.DateOnly)]
+	if !ok {
+		return ecbank.RateTable{}, ecbank.ErrNoHistory
+	}
+	return table, nil
+}
+
+func TestParseRule(t *testing.T) {
+	tt := map[string]struct {
+		text   string
+		kind   watch.Kind
+		source string
+		target string
+		err    error
+	}{
+		"threshold":            {text: "USD/EUR > 0.95", kind: watch.Threshold, source: "USD", target: "EUR"},
+		"lower threshold":      {text: " EUR/USD   <=  1.05 ", kind: watch.Threshold, source: "EUR", target: "USD"},
+		"move against euro":    {text: "GBP moves > 1%", kind: watch.Move, source: "EUR", target: "GBP"},
+		"move day-over-day":    {text: "GBP/USD moves >= 0.5% day-over-day", kind: watch.Move, source: "GBP", target: "USD"},
+		"missing value":        {text: "USD/EUR >", err: watch.ErrInvalidRule},
+		"unknown operator":     {text: "USD/EUR ~ 0.95", err: watch.ErrInvalidRule},
+		"invalid currency":     {text: "USD/EURO > 0.95", err: watch.ErrInvalidRule},
+		"invalid value":        {text: "USD/EUR > abc", err: watch.ErrInvalidRule},
+		"move without percent": {text: "GBP moves > 1", err: watch.ErrInvalidRule},
+		"move below":           {text: "GBP moves < 1%", err: watch.ErrInvalidRule},
+		"unknown period":       {text: "GBP moves > 1% week-over-week", err: watch.ErrInvalidRule},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			rule, err := watch.ParseRule(tc.text)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+
+			if rule.Kind != tc.kind || rule.Source.ISOCode() != tc.source || rule.Target.ISOCode() != tc.target {
+				t.Errorf("got %s %s/%s, want %s %s/%s", rule.Kind, rule.Source, rule.Target, tc.kind, tc.source, tc.target)
+			}
+			if rule.String() != strings.Join(strings.Fields(tc.text), " ") {
+				t.Errorf("expected the rule to be written as given, got %q", rule.String())
+			}
+		})
+	}
+}
+
+func TestWatcher_Check(t *testing.T) {
+	source := &sequence{
+		tables: []ecbank.RateTable{
+			table(t, "2025-04-08", "USD", "1.0000", "GBP", "0.8500"),
+			table(t, "2025-04-08", "USD", "1.0000", "GBP", "0.8500"),
+			table(t, "2025-04-09", "USD", "1.1000", "GBP", "0.8510"),
+			table(t, "2025-04-10", "USD", "0.9000", "GBP", "0.8000"),
+			table(t, "2025-04-11", "USD", "0.8000", "GBP", "0.8000"),
+			table(t, "2025-04-14", "USD", "1.2000", "GBP", "0.8000"),
+			table(t, "2025-04-15", "USD", "1.0000", "GBP", "0.8000"),
+		},
+		past: map[string]ecbank.RateTable{
+			"2025-04-07": table(t, "2025-04-07", "USD", "1.0000", "GBP", "0.8000"),
+		},
+	}
+
+	var out bytes.Buffer
+	watcher := watch.New(source, mustParseRules(t, "USD/EUR > 0.95", "GBP moves > 1%"), []watch.Notifier{watch.JSONLines(&out)})
+
+	wantEvents := [][]string{
+		// the threshold already holds, GBP moved since the publication in the history.
+		{"USD/EUR > 0.95|1||2025-04-08", "GBP moves > 1%|0.85|+6.25%|2025-04-08"},
+		// nothing new is published.
+		nil,
+		// USD/EUR went below the threshold, GBP moved by less than 1%.
+		nil,
+		{"USD/EUR > 0.95|1.1111111111||2025-04-10", "GBP moves > 1%|0.8|-5.99%|2025-04-10"},
+		// the threshold still holds.
+		nil,
+		nil,
+		// USD/EUR crossed the threshold again.
+		{"USD/EUR > 0.95|1||2025-04-15"},
+	}
+
+	lines := 0
+	for i, want := range wantEvents {
+		events, err := watcher.Check(context.Background())
+		if err != nil {
+			t.Fatalf("check %d: unexpected error: %s", i, err)
+		}
+
+		var got []string
+		for _, event := range events {
+			got = append(got, strings.Join([]string{event.Rule, event.Rate.String(), event.Change, event.Date}, "|"))
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("check %d: got events\n%s\nwant\n%s", i, strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+		lines += len(want)
+	}
+
+	written := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(written) != lines {
+		t.Fatalf("expected %d JSON lines, got %d:\n%s", lines, len(written), out.String())
+	}
+
+	var event map[string]any
+	if err := json.Unmarshal([]byte(written[1]), &event); err != nil {
+		t.Fatalf("invalid JSON line %q: %s", written[1], err)
+	}
+	for key, want := range map[string]any{"rule": "GBP moves > 1%", "kind": "move", "pair": "EUR/GBP", "rate": "0.85", "previous": "0.8", "change": "+6.25%", "date": "2025-04-08"} {
+		if event[key] != want {
+			t.Errorf("expected %s to be %v, got %v", key, want, event[key])
+		}
+	}
+}
+
+func TestWatcher_Check_Errors(t *testing.T) {
+	source := &sequence{tables: []ecbank.RateTable{table(t, "2025-04-08", "USD", "1.0000")}}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "receiver down", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	var out bytes.Buffer
+	watcher := watch.New(source, mustParseRules(t, "JPY/EUR > 0.01", "USD/EUR > 0.95"),
+		[]watch.Notifier{watch.Webhook(receiver.URL, nil), watch.JSONLines(&out)})
+
+	events, err := watcher.Check(context.Background())
+	if !errors.Is(err, ecbank.ErrChangeRateNotFound) || !errors.Is(err, watch.ErrNotification) {
+		t.Errorf("expected the unknown rate and the failed delivery to be reported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "receiver down") {
+		t.Errorf("expected the answer of the webhook to be reported, got %v", err)
+	}
+
+	if len(events) != 1 || out.Len() == 0 {
+		t.Errorf("expected the other rules to be notified, got %v and %q", events, out.String())
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	received := make(chan watch.Event, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "expected a JSON post", http.StatusBadRequest)
+			return
+		}
+
+		var event watch.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received <- event
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	event := watch.Event{Rule: "USD/EUR > 0.95", Kind: watch.Threshold, Pair: "USD/EUR", Rate: mustParseRate(t, "0.96"), Date: "2025-04-08"}
+	if err := watch.Webhook(receiver.URL, receiver.Client()).Notify(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got := <-received
+	if got.Rule != event.Rule || got.Rate != event.Rate || got.Date != event.Date {
+		t.Errorf("received %+v, want %+v", got, event)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell to run commands with")
+	}
+
+	event := watch.Event{Rule: "USD/EUR > 0.95", Kind: watch.Threshold, Pair: "USD/EUR", Rate: mustParseRate(t, "0.96"), Date: "2025-04-08"}
+
+	err := watch.Command("sh", "-c", `grep -q '"rule":"USD/EUR > 0.95"'`).Notify(context.Background(), event)
+	if err != nil {
+		t.Errorf("expected the command to read the event, got %s", err)
+	}
+
+	err = watch.Command("sh", "-c", "echo failed; exit 3").Notify(context.Background(), event)
+	if !errors.Is(err, watch.ErrNotification) || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("expected the failure of the command to be reported with its output, got %v", err)
+	}
+}